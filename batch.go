@@ -0,0 +1,40 @@
+package go_heaps
+
+// PushMany inserts items into h. Heaps implementing PushMany(...Item), like
+// the pairing heap, insert them in a single batch, and other heaps insert
+// them one by one.
+func PushMany(h Interface, items ...Item) {
+	if b, ok := h.(interface {
+		PushMany(items ...Item)
+	}); ok {
+		b.PushMany(items...)
+		return
+	}
+	for _, item := range items {
+		h.Insert(item)
+	}
+}
+
+// PopMany removes up to n items from h and returns them in ascending order.
+// It returns fewer items if h runs out, and none if n is not positive. Heaps
+// implementing PopMany(int) []Item, like the pairing heap, remove them in a
+// single batch, and other heaps call DeleteMin until done.
+func PopMany(h Interface, n int) []Item {
+	if n <= 0 {
+		return nil
+	}
+	if b, ok := h.(interface {
+		PopMany(n int) []Item
+	}); ok {
+		return b.PopMany(n)
+	}
+	var items []Item
+	for len(items) < n {
+		item := h.DeleteMin()
+		if item == nil {
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
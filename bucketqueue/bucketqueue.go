@@ -0,0 +1,158 @@
+// Package bucketqueue implements a monotone bucket queue, the priority
+// queue of Dial's shortest path algorithm.
+//
+// Priorities are small non-negative integers and the queue is monotone:
+// nothing may be pushed below the priority last popped. Entries are kept in
+// a circular array with one bucket per priority, covering the range from the
+// lowest priority held up to the number of buckets ahead of it. Push is O(1) and
+// Pop is O(1) amortized over a monotone run, since the minimum only moves
+// forward. Pushing past the covered range grows the array.
+//
+// For shortest paths with integer edge weights up to C, New(C+1) covers
+// every priority that can be pushed, and decreasing a key is done by pushing
+// the vertex again and skipping the stale entry when it is popped.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Bucket_queue
+package bucketqueue
+
+import (
+	"fmt"
+)
+
+// Entry is a payload queued at a priority.
+type Entry struct {
+	Priority int
+	Value    interface{}
+}
+
+// Queue is a monotone bucket queue.
+type Queue struct {
+	buckets [][]Entry // circular, bucket p % len(buckets) holds priority p
+	floor   int       // priority last popped
+	min     int       // no entry has a lower priority
+	max     int       // no entry has a higher priority
+	size    int
+}
+
+// New returns an empty Queue with buckets for span consecutive priorities.
+func New(span int) *Queue {
+	if span < 1 {
+		panic(fmt.Sprintf("bucketqueue: span %d is not positive", span))
+	}
+	return &Queue{buckets: make([][]Entry, span)}
+}
+
+// Len returns the number of entries in the queue.
+func (q *Queue) Len() int {
+	return q.size
+}
+
+// IsEmpty returns true if the queue has no entries.
+func (q *Queue) IsEmpty() bool {
+	return q.size == 0
+}
+
+// Span returns the number of consecutive priorities the queue covers
+// without growing.
+func (q *Queue) Span() int {
+	return len(q.buckets)
+}
+
+// Floor returns the lowest priority that may be pushed while the queue is
+// not empty: the priority last popped, or 0 if nothing was popped since the
+// queue was last empty.
+func (q *Queue) Floor() int {
+	return q.floor
+}
+
+// Push queues value at priority. Priority must not be below Floor, unless
+// the queue is empty. The queue grows when the priorities it holds no longer
+// fit in Span.
+// The complexity is O(1), or O(n + span) when the queue grows.
+func (q *Queue) Push(priority int, value interface{}) {
+	if priority < 0 {
+		panic(fmt.Sprintf("bucketqueue: negative priority %d", priority))
+	}
+	if q.size == 0 {
+		q.floor, q.min, q.max = 0, priority, priority
+	} else if priority < q.floor {
+		panic(fmt.Sprintf("bucketqueue: priority %d below the last popped %d", priority, q.floor))
+	}
+	if priority < q.min {
+		q.min = priority
+	}
+	if priority > q.max {
+		q.max = priority
+	}
+	if q.max-q.min >= len(q.buckets) {
+		q.grow(q.max - q.min + 1)
+	}
+	i := priority % len(q.buckets)
+	q.buckets[i] = append(q.buckets[i], Entry{Priority: priority, Value: value})
+	q.size++
+}
+
+// grow resizes the bucket array to cover at least span priorities.
+func (q *Queue) grow(span int) {
+	if span < 2*len(q.buckets) {
+		span = 2 * len(q.buckets)
+	}
+	buckets := make([][]Entry, span)
+	for _, bucket := range q.buckets {
+		if len(bucket) > 0 {
+			buckets[bucket[0].Priority%span] = bucket
+		}
+	}
+	q.buckets = buckets
+}
+
+// Peek returns the entry Pop would return without removing it.
+// The complexity is O(1) amortized.
+func (q *Queue) Peek() (Entry, bool) {
+	bucket := q.first()
+	if bucket == nil {
+		return Entry{}, false
+	}
+	return bucket[len(bucket)-1], true
+}
+
+// Pop removes and returns an entry with the lowest priority. Entries of
+// equal priority are popped last in first out.
+// The complexity is O(1) amortized.
+func (q *Queue) Pop() (Entry, bool) {
+	bucket := q.first()
+	if bucket == nil {
+		return Entry{}, false
+	}
+	i := q.min % len(q.buckets)
+	e := bucket[len(bucket)-1]
+	bucket[len(bucket)-1] = Entry{}
+	q.buckets[i] = bucket[:len(bucket)-1]
+	q.floor = e.Priority
+	q.size--
+	return e, true
+}
+
+// first advances min to the lowest priority held and returns its bucket,
+// or nil if the queue is empty.
+func (q *Queue) first() []Entry {
+	if q.size == 0 {
+		return nil
+	}
+	for {
+		if bucket := q.buckets[q.min%len(q.buckets)]; len(bucket) > 0 {
+			return bucket
+		}
+		q.min++
+	}
+}
+
+// Clear removes all entries from the queue.
+func (q *Queue) Clear() {
+	for i := range q.buckets {
+		q.buckets[i] = nil
+	}
+	q.size = 0
+}
@@ -0,0 +1,133 @@
+package bucketqueue
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBucketQueueOrder(t *testing.T) {
+	q := New(8)
+	for _, p := range []int{3, 1, 7, 1, 4} {
+		q.Push(p, p)
+	}
+	if q.Len() != 5 {
+		t.Fatalf("expected 5 entries, got %d", q.Len())
+	}
+	if e, _ := q.Peek(); e.Priority != 1 {
+		t.Fatalf("unexpected peek %v", e)
+	}
+	for _, want := range []int{1, 1, 3, 4, 7} {
+		e, ok := q.Pop()
+		if !ok || e.Priority != want || e.Value != want {
+			t.Fatalf("expected priority %d, got %v", want, e)
+		}
+	}
+	if _, ok := q.Pop(); ok || !q.IsEmpty() {
+		t.Fail()
+	}
+}
+
+func TestBucketQueueGrow(t *testing.T) {
+	q := New(2)
+	q.Push(5, "a")
+	q.Push(6, "b")
+	q.Push(20, "c")
+	q.Push(100, "d")
+	if q.Span() < 96 {
+		t.Fatalf("expected the queue to cover 96 priorities, got %d", q.Span())
+	}
+	for _, want := range []string{"a", "b", "c", "d"} {
+		if e, _ := q.Pop(); e.Value != want {
+			t.Fatalf("expected %s, got %v", want, e)
+		}
+	}
+}
+
+func TestBucketQueueMonotone(t *testing.T) {
+	q := New(4)
+	q.Push(10, nil)
+	q.Push(12, nil)
+	q.Pop()
+	q.Push(10, nil)
+	if q.Floor() != 10 {
+		t.Fatalf("expected the floor to be 10, got %d", q.Floor())
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic below the floor")
+			}
+		}()
+		q.Push(9, nil)
+	}()
+
+	// an empty queue accepts any priority
+	q.Clear()
+	q.Push(2, nil)
+	q.Push(1, nil)
+	if q.Floor() != 0 {
+		t.Fatalf("expected the floor to be reset, got %d", q.Floor())
+	}
+	if e, _ := q.Pop(); e.Priority != 1 || q.Span() != 4 {
+		t.Fatalf("unexpected entry %v", e)
+	}
+}
+
+// TestDial runs Dial's shortest path algorithm against Bellman-Ford on
+// random graphs.
+func TestDial(t *testing.T) {
+	const n, maxWeight = 200, 9
+	r := rand.New(rand.NewSource(1))
+	type edge struct{ to, w int }
+	graph := make([][]edge, n)
+	for i := 0; i < 4*n; i++ {
+		from, to := r.Intn(n), r.Intn(n)
+		graph[from] = append(graph[from], edge{to, r.Intn(maxWeight + 1)})
+	}
+
+	dist := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+	q := New(maxWeight + 1)
+	q.Push(0, 0)
+	for !q.IsEmpty() {
+		e, _ := q.Pop()
+		v := e.Value.(int)
+		if dist[v] != -1 {
+			continue // stale entry
+		}
+		dist[v] = e.Priority
+		for _, ed := range graph[v] {
+			if dist[ed.to] == -1 {
+				q.Push(e.Priority+ed.w, ed.to)
+			}
+		}
+	}
+	if q.Span() != maxWeight+1 {
+		t.Fatalf("the queue grew to %d", q.Span())
+	}
+
+	want := make([]int, n)
+	for i := range want {
+		want[i] = -1
+	}
+	want[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for from, edges := range graph {
+			for _, ed := range edges {
+				if want[from] != -1 && (want[ed.to] == -1 || want[from]+ed.w < want[ed.to]) {
+					want[ed.to] = want[from] + ed.w
+					changed = true
+				}
+			}
+		}
+	}
+	for v := range want {
+		if dist[v] != want[v] {
+			t.Fatalf("vertex %d: expected distance %d, got %d", v, want[v], dist[v])
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package cacheheap keeps the keys of a cache of fixed capacity in a heap
+// ordered by a pluggable eviction policy: LRU by the time of the last
+// access, LFU by the number of accesses, or GDSF, which weighs the accesses
+// by the cost of fetching the key again and its size.
+//
+// The keys are held in a frequency.Queue, an indexed priority queue, whose
+// priority of a key is set again on every access in O(log n). Keys of equal
+// priority are evicted least recently used first.
+//
+// The Cache tracks keys only: the cached values are kept by the caller,
+// which drops the value of every key returned by Add or Evict.
+//
+// Structure is not thread safe.
+package cacheheap
+
+import (
+	"github.com/theodesp/go-heaps/frequency"
+)
+
+// Entry is a key of the cache as seen by a Policy.
+type Entry struct {
+	Key      interface{}
+	Cost     float64 // the cost given to Add
+	Hits     int     // the accesses since the key was added, the Add included
+	Accessed uint64  // the logical time of the last access
+}
+
+// Policy decides which key is evicted. The key of the lowest priority is
+// evicted first.
+type Policy interface {
+	// Priority returns the priority of e after it was added or accessed.
+	Priority(e *Entry) float64
+	// Evicted is called after e was evicted at the given priority.
+	Evicted(e *Entry, priority float64)
+}
+
+// LRU evicts the least recently used key.
+var LRU Policy = lru{}
+
+type lru struct{}
+
+func (lru) Priority(e *Entry) float64 { return float64(e.Accessed) }
+
+func (lru) Evicted(*Entry, float64) {}
+
+// LFU evicts the least frequently used key, and the least recently used of
+// those on a tie.
+var LFU Policy = lfu{}
+
+type lfu struct{}
+
+func (lfu) Priority(e *Entry) float64 { return float64(e.Hits) }
+
+func (lfu) Evicted(*Entry, float64) {}
+
+// GDSF returns the Greedy-Dual-Size-Frequency policy, which evicts the key
+// of the lowest L + Hits*Cost/size, where L is the priority of the last key
+// evicted. L ages the keys that are no longer used, so they are evicted
+// eventually however often they were used before. size returns the size of
+// a key, such as the length of its value; a nil size counts every key as 1.
+// The policy keeps L and must not be shared between caches.
+func GDSF(size func(key interface{}) float64) Policy {
+	return &gdsf{size: size}
+}
+
+type gdsf struct {
+	size     func(key interface{}) float64
+	inflated float64 // L
+}
+
+func (g *gdsf) Priority(e *Entry) float64 {
+	size := 1.0
+	if g.size != nil {
+		size = g.size(e.Key)
+	}
+	return g.inflated + float64(e.Hits)*e.Cost/size
+}
+
+func (g *gdsf) Evicted(_ *Entry, priority float64) {
+	g.inflated = priority
+}
+
+// Cache tracks the keys of a cache and evicts them by a Policy.
+type Cache struct {
+	queue   *frequency.Queue
+	policy  Policy
+	entries map[interface{}]*Entry
+	clock   uint64
+}
+
+// New returns an empty Cache of capacity keys evicted by policy.
+func New(capacity int, policy Policy) *Cache {
+	return &Cache{
+		// the policy sets the priorities, the accesses add nothing
+		queue:   frequency.New(capacity, frequency.WithWeight(0)),
+		policy:  policy,
+		entries: make(map[interface{}]*Entry, capacity),
+	}
+}
+
+// Len returns the number of keys in the cache.
+func (c *Cache) Len() int {
+	return len(c.entries)
+}
+
+// Cap returns the number of keys the cache can hold.
+func (c *Cache) Cap() int {
+	return c.queue.Cap()
+}
+
+// Contains reports whether key is in the cache, without counting an access.
+func (c *Cache) Contains(key interface{}) bool {
+	_, ok := c.entries[key]
+	return ok
+}
+
+// Add adds key with the cost of fetching it again, evicting a key first if
+// the cache is full, and returns the evicted key. Adding a key already in
+// the cache sets its cost and counts as an access. A cache of no capacity
+// returns key itself.
+// The complexity is O(log n).
+func (c *Cache) Add(key interface{}, cost float64) (evicted interface{}, ok bool) {
+	if e, found := c.entries[key]; found {
+		e.Cost = cost
+		c.access(e)
+		return nil, false
+	}
+	if c.Cap() == 0 {
+		return key, true
+	}
+	if len(c.entries) == c.Cap() {
+		evicted, ok = c.Evict()
+	}
+	e := &Entry{Key: key, Cost: cost}
+	c.entries[key] = e
+	c.access(e)
+	return evicted, ok
+}
+
+// Touch counts an access to key and reports whether it is in the cache.
+// The complexity is O(log n).
+func (c *Cache) Touch(key interface{}) bool {
+	e, ok := c.entries[key]
+	if ok {
+		c.access(e)
+	}
+	return ok
+}
+
+// Evict removes the key to evict by the policy and returns it, or false if
+// the cache is empty.
+// The complexity is O(log n).
+func (c *Cache) Evict() (key interface{}, ok bool) {
+	key, priority, ok := c.queue.Peek()
+	if !ok {
+		return nil, false
+	}
+	c.queue.Pop()
+	e := c.entries[key]
+	delete(c.entries, key)
+	c.policy.Evicted(e, priority)
+	return key, true
+}
+
+// Remove removes key without evicting it, as when its value is
+// invalidated, and reports whether it was in the cache.
+// The complexity is O(log n).
+func (c *Cache) Remove(key interface{}) bool {
+	if _, ok := c.entries[key]; !ok {
+		return false
+	}
+	c.queue.Remove(key)
+	delete(c.entries, key)
+	return true
+}
+
+// access records an access to e and sets its priority again.
+func (c *Cache) access(e *Entry) {
+	c.clock++
+	e.Hits++
+	e.Accessed = c.clock
+	if err := c.queue.Push(e.Key, c.policy.Priority(e)); err != nil {
+		panic(err) // a NaN priority from the policy
+	}
+}
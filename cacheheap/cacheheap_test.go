@@ -0,0 +1,97 @@
+package cacheheap
+
+import (
+	"testing"
+)
+
+// run adds or touches every key of trace in turn, adding the keys not in
+// the cache at cost 1, and returns the keys evicted.
+func run(c *Cache, trace ...string) []interface{} {
+	var evicted []interface{}
+	for _, key := range trace {
+		if c.Touch(key) {
+			continue
+		}
+		if key, ok := c.Add(key, 1); ok {
+			evicted = append(evicted, key)
+		}
+	}
+	return evicted
+}
+
+func equal(a []interface{}, b ...string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLRU(t *testing.T) {
+	c := New(2, LRU)
+	if evicted := run(c, "a", "b", "a", "c", "b", "a"); !equal(evicted, "b", "a", "c") {
+		t.Fatalf("unexpected evictions %v", evicted)
+	}
+	if c.Len() != 2 || !c.Contains("b") || !c.Contains("a") {
+		t.Fatal("expected a and b to be cached")
+	}
+}
+
+func TestLFU(t *testing.T) {
+	c := New(2, LFU)
+	if evicted := run(c, "a", "a", "a", "b", "c", "c", "d"); !equal(evicted, "b", "c") {
+		t.Fatalf("unexpected evictions %v", evicted)
+	}
+	for _, want := range []string{"d", "a"} {
+		if key, ok := c.Evict(); !ok || key != want {
+			t.Fatalf("expected %s, got %v", want, key)
+		}
+	}
+	if _, ok := c.Evict(); ok {
+		t.Fatal("expected an empty cache")
+	}
+}
+
+func TestGDSF(t *testing.T) {
+	sizes := map[interface{}]float64{"big": 10, "small": 1, "dear": 10}
+	c := New(2, GDSF(func(key interface{}) float64 { return sizes[key] }))
+	c.Add("big", 1)   // 0.1
+	c.Add("small", 1) // 1
+	if key, _ := c.Add("dear", 50); key != "big" {
+		t.Fatalf("expected big to be evicted, got %v", key)
+	}
+	// L is now 0.1: small is at 1 and dear at 5
+	c.Touch("small") // 2.1
+	c.Touch("small") // 3.1
+	if key, _ := c.Evict(); key != "small" {
+		t.Fatalf("expected small to be evicted, got %v", key)
+	}
+	// L is now 3.1, which ages the old priority of dear
+	c.Add("big", 1) // 3.2
+	if key, _ := c.Evict(); key != "big" {
+		t.Fatalf("expected big to be evicted, got %v", key)
+	}
+	c.Add("small", 1) // 4.2
+	c.Add("small", 1) // a second access at 5.4
+	if key, _ := c.Evict(); key != "dear" {
+		t.Fatalf("expected dear to be evicted, got %v", key)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c := New(1, LRU)
+	c.Add("a", 1)
+	if !c.Remove("a") || c.Remove("a") || c.Touch("a") || c.Len() != 0 {
+		t.Fatal("expected a to be removed once")
+	}
+	if key, ok := c.Add("b", 1); ok {
+		t.Fatalf("expected no eviction, got %v", key)
+	}
+	if key, ok := New(0, LFU).Add("a", 1); !ok || key != "a" {
+		t.Fatalf("expected a cache of no capacity to return a, got %v", key)
+	}
+}
@@ -0,0 +1,121 @@
+// Package chanmerge merges live feeds of items, each delivered in ascending
+// order on a channel, into a single ascending stream. It is the streaming
+// counterpart of a k-way merge such as runs.Merge.
+//
+// A Merger holds the head item of every feed in a pairing heap. The global
+// minimum is only known once every open feed has delivered its next item,
+// so Pop receives from the feeds missing a head, all at once, before taking
+// the smallest head. A feed drops out when its channel is closed. Every feed
+// is read one item ahead at most; the channels themselves provide any
+// further buffering.
+//
+// Structure is not thread safe.
+package chanmerge
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// head is the next item of a feed in the merge heap.
+type head struct {
+	item heap.Item
+	feed int
+}
+
+// Compare orders heads by item, then by the order of the feeds.
+func (h *head) Compare(than heap.Item) int {
+	o := than.(*head)
+	if d := h.item.Compare(o.item); d != 0 {
+		return d
+	}
+	return h.feed - o.feed
+}
+
+// Merger merges the items of several feeds in ascending order.
+type Merger struct {
+	feeds   []<-chan heap.Item
+	heads   *pairing.PairHeap
+	waiting []int // the open feeds without a head
+	cases   []reflect.SelectCase
+}
+
+// New returns a Merger of feeds. The items of every feed must be sent in
+// ascending order. Equal items of different feeds are popped in the order
+// of feeds. A nil channel counts as a closed feed.
+func New(feeds ...<-chan heap.Item) *Merger {
+	m := &Merger{feeds: feeds, heads: pairing.New()}
+	for i, feed := range feeds {
+		if feed != nil {
+			m.waiting = append(m.waiting, i)
+		}
+	}
+	return m
+}
+
+// Pop removes and returns the smallest item not yet popped across all
+// feeds, blocking until every open feed has delivered its next item. It
+// returns nil, false once every feed is closed and drained.
+// The complexity is O(log k) amortized for k feeds, plus the wait.
+func (m *Merger) Pop() (heap.Item, bool) {
+	item, err := m.PopContext(context.Background())
+	return item, err == nil
+}
+
+// PopContext is like Pop but stops waiting when ctx is cancelled or its
+// deadline passes, returning ctx.Err(). The items received so far are kept
+// for the next call. It returns io.EOF once every feed is closed and
+// drained.
+func (m *Merger) PopContext(ctx context.Context) (heap.Item, error) {
+	if err := m.fill(ctx); err != nil {
+		return nil, err
+	}
+	min := m.heads.DeleteMin()
+	if min == nil {
+		return nil, io.EOF
+	}
+	h := min.(*head)
+	m.waiting = append(m.waiting, h.feed)
+	return h.item, nil
+}
+
+// Feeds returns the number of feeds that are open or hold an item not yet
+// popped.
+func (m *Merger) Feeds() int {
+	return len(m.waiting) + m.heads.Len()
+}
+
+// fill receives the next item of every waiting feed, in whatever order
+// they arrive, and drops the feeds that are closed. Nil items are skipped.
+func (m *Merger) fill(ctx context.Context) error {
+	done := ctx.Done()
+	for len(m.waiting) > 0 {
+		m.cases = m.cases[:0]
+		for _, i := range m.waiting {
+			m.cases = append(m.cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.feeds[i])})
+		}
+		if done != nil {
+			m.cases = append(m.cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+		}
+		chosen, v, ok := reflect.Select(m.cases)
+		if chosen == len(m.waiting) {
+			return ctx.Err()
+		}
+		i := m.waiting[chosen]
+		if ok {
+			item, _ := v.Interface().(heap.Item)
+			if item == nil {
+				continue
+			}
+			m.heads.Insert(&head{item: item, feed: i})
+		}
+		last := len(m.waiting) - 1
+		m.waiting[chosen] = m.waiting[last]
+		m.waiting = m.waiting[:last]
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package chanmerge
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// feed sends values on a new channel from a goroutine, then closes it.
+func feed(values ...int) <-chan heap.Item {
+	ch := make(chan heap.Item)
+	go func() {
+		for _, v := range values {
+			ch <- heap.Integer(v)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func TestPop(t *testing.T) {
+	var feeds []<-chan heap.Item
+	var all []int
+	for i := 0; i < 8; i++ {
+		values := make([]int, rand.Intn(200))
+		for j := range values {
+			values[j] = rand.Intn(1000)
+		}
+		sort.Ints(values)
+		all = append(all, values...)
+		feeds = append(feeds, feed(values...))
+	}
+	feeds = append(feeds, nil, feed())
+	sort.Ints(all)
+
+	m := New(feeds...)
+	for _, v := range all {
+		item, ok := m.Pop()
+		if !ok || item != heap.Integer(v) {
+			t.Fatalf("expected %d, got %v", v, item)
+		}
+	}
+	if item, ok := m.Pop(); ok || item != nil {
+		t.Fatalf("expected the feeds to be drained, got %v", item)
+	}
+	if m.Feeds() != 0 {
+		t.Fatalf("expected no feeds left, got %d", m.Feeds())
+	}
+}
+
+func TestPopTies(t *testing.T) {
+	a, b := make(chan heap.Item, 2), make(chan heap.Item, 2)
+	a <- heap.KV(heap.Integer(1), "a")
+	b <- heap.KV(heap.Integer(1), "b")
+	close(a)
+	close(b)
+	m := New(b, a)
+	for _, want := range []string{"b", "a"} {
+		item, _ := m.Pop()
+		if item.(heap.Pair).Value != want {
+			t.Fatalf("expected %s, got %v", want, item)
+		}
+	}
+}
+
+func TestPopContext(t *testing.T) {
+	slow := make(chan heap.Item, 1)
+	m := New(feed(1, 3), slow)
+	if m.Feeds() != 2 {
+		t.Fatalf("expected 2 feeds, got %d", m.Feeds())
+	}
+
+	// the minimum is unknown until slow delivers
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.PopContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to pass, got %v", err)
+	}
+
+	slow <- heap.Integer(2)
+	close(slow)
+	for _, want := range []int{1, 2, 3} {
+		item, err := m.PopContext(context.Background())
+		if err != nil || item != heap.Integer(want) {
+			t.Fatalf("expected %d, got %v, %v", want, item, err)
+		}
+	}
+	if _, err := m.PopContext(context.Background()); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
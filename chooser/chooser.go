@@ -0,0 +1,96 @@
+// Package chooser recommends a heap implementation from this collection for
+// a described workload and constructs it.
+//
+// The rules follow the complexity table in the README: only the pairing and
+// rank pairing heaps implement the Extended interface (Delete, Adjust and
+// Meld), the pairing heap has constant time Insert and Meld, and the leftist
+// heap offers O(log n) worst case bounds without amortization spikes.
+package chooser
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+)
+
+// Kind identifies a heap implementation.
+type Kind int
+
+const (
+	// Pairing is the pairing heap, a good general purpose default.
+	Pairing Kind = iota
+	// RankPairing is the rank pairing heap, suited to decrease-key heavy work.
+	RankPairing
+	// Leftist is the leftist heap, suited to steady insert/delete-min mixes.
+	Leftist
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Pairing:
+		return "pairing"
+	case RankPairing:
+		return "rank pairing"
+	case Leftist:
+		return "leftist"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Workload describes how a heap is going to be used. Operation counts are
+// relative: only their ratios matter.
+type Workload struct {
+	Inserts      int
+	DeleteMins   int
+	DecreaseKeys int // Adjust calls lowering a priority
+	Deletes      int // removals of arbitrary items
+	Melds        int
+	Size         int // expected number of items held at once
+}
+
+// Recommend returns the implementation best matching w.
+func Recommend(w Workload) Kind {
+	total := w.Inserts + w.DeleteMins + w.DecreaseKeys + w.Deletes + w.Melds
+	if total == 0 {
+		return Pairing
+	}
+
+	// Decrease-key dominated workloads favour the rank pairing heap, whose
+	// decrease operation restructures only the path to the root.
+	if w.DecreaseKeys*4 >= total {
+		return RankPairing
+	}
+	// Any other use of the Extended operations requires the pairing heap.
+	if w.DecreaseKeys > 0 || w.Deletes > 0 || w.Melds > 0 {
+		return Pairing
+	}
+	// Insert bursts benefit from the constant time insert of the pairing
+	// heap, large queues from its cheap inserts in general.
+	if w.Inserts >= 2*w.DeleteMins || w.Size > 1<<16 {
+		return Pairing
+	}
+	return Leftist
+}
+
+// New returns an empty heap of the implementation recommended for w.
+func New(w Workload) heap.Interface {
+	return NewKind(Recommend(w))
+}
+
+// NewKind returns an empty heap of kind k.
+func NewKind(k Kind) heap.Interface {
+	switch k {
+	case Pairing:
+		return pairing.New()
+	case RankPairing:
+		return rpheap.New()
+	case Leftist:
+		return leftist.New()
+	default:
+		panic(fmt.Sprintf("unknown heap kind %v", k))
+	}
+}
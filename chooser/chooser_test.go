@@ -0,0 +1,47 @@
+package chooser
+
+import (
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload Workload
+		want     Kind
+	}{
+		{"empty", Workload{}, Pairing},
+		{"dijkstra", Workload{Inserts: 10, DeleteMins: 10, DecreaseKeys: 30}, RankPairing},
+		{"cancellations", Workload{Inserts: 10, DeleteMins: 8, Deletes: 2}, Pairing},
+		{"sharded", Workload{Inserts: 10, DeleteMins: 10, Melds: 1}, Pairing},
+		{"bursts", Workload{Inserts: 100, DeleteMins: 10}, Pairing},
+		{"steady", Workload{Inserts: 10, DeleteMins: 10, Size: 1000}, Leftist},
+		{"large", Workload{Inserts: 10, DeleteMins: 10, Size: 1 << 20}, Pairing},
+	}
+
+	for _, test := range tests {
+		if got := Recommend(test.workload); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, kind := range []Kind{Pairing, RankPairing, Leftist} {
+		h := NewKind(kind)
+		for _, v := range []int{5, 1, 4, 2, 3} {
+			h.Insert(heap.Integer(v))
+		}
+		for want := 1; want <= 5; want++ {
+			if got := h.DeleteMin(); got != heap.Integer(want) {
+				t.Errorf("%v: expected %d, got %v", kind, want, got)
+			}
+		}
+	}
+
+	if _, ok := New(Workload{DecreaseKeys: 1}).(heap.Extended); !ok {
+		t.Error("decrease-key workloads need an Extended heap")
+	}
+}
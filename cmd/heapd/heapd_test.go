@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func call(t *testing.T, srv *httptest.Server, method, path, body string, v interface{}) int {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if v != nil && res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res.StatusCode
+}
+
+func TestHTTP(t *testing.T) {
+	for name := range backends {
+		reg, err := newRegistry(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := httptest.NewServer(handler(reg))
+
+		for _, body := range []string{
+			`{"priority": 3, "value": "c"}`,
+			`{"priority": 1, "value": "a"}`,
+			`{"priority": 2, "value": "b1"}`,
+			`{"priority": 2, "value": "b2"}`,
+		} {
+			if status := call(t, srv, "POST", "/push", body, nil); status != http.StatusOK {
+				t.Fatalf("%s: push returned %d", name, status)
+			}
+		}
+		var size map[string]int
+		if call(t, srv, "GET", "/size", "", &size); size["size"] != 4 {
+			t.Fatalf("%s: expected size 4, got %v", name, size)
+		}
+		var e Entry
+		if call(t, srv, "GET", "/peek", "", &e); e.Value != "a" {
+			t.Fatalf("%s: expected to peek a, got %v", name, e)
+		}
+		for _, want := range []string{"a", "b1", "b2", "c"} {
+			e = Entry{}
+			if call(t, srv, "POST", "/pop", "", &e); e.Value != want {
+				t.Fatalf("%s: expected %s, got %v", name, want, e)
+			}
+		}
+		if status := call(t, srv, "POST", "/pop", "", nil); status != http.StatusNotFound {
+			t.Fatalf("%s: expected 404 from an empty heap, got %d", name, status)
+		}
+		var stats Stats
+		call(t, srv, "GET", "/stats", "", &stats)
+		if stats.Backend != name || stats.Pushes != 4 || stats.Pops != 4 || stats.Size != 0 {
+			t.Fatalf("%s: unexpected stats %+v", name, stats)
+		}
+
+		// keys name separate heaps
+		call(t, srv, "POST", "/push?key=other", `{"priority": 1, "value": "x"}`, nil)
+		if call(t, srv, "GET", "/size?key=other", "", &size); size["size"] != 1 {
+			t.Fatalf("%s: expected size 1, got %v", name, size)
+		}
+		if call(t, srv, "GET", "/size", "", &size); size["size"] != 0 {
+			t.Fatalf("%s: expected size 0, got %v", name, size)
+		}
+		srv.Close()
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	reg, _ := newRegistry("pairing")
+	srv := httptest.NewServer(handler(reg))
+	defer srv.Close()
+
+	var size map[string]int
+	body := ` [{"priority": 3, "value": "c"}, {"priority": 1, "value": "a"}, {"priority": 2, "value": "b"}]`
+	if call(t, srv, "POST", "/push", body, &size); size["size"] != 3 {
+		t.Fatalf("expected size 3, got %v", size)
+	}
+	var entries []Entry
+	call(t, srv, "POST", "/pop?count=2", "", &entries)
+	if len(entries) != 2 || entries[0].Value != "a" || entries[1].Value != "b" {
+		t.Fatalf("expected a and b, got %v", entries)
+	}
+	call(t, srv, "POST", "/pop?count=5", "", &entries)
+	if len(entries) != 1 || entries[0].Value != "c" {
+		t.Fatalf("expected c, got %v", entries)
+	}
+	if status := call(t, srv, "POST", "/pop?count=5&key=missing", "", &entries); status != http.StatusOK || len(entries) != 0 {
+		t.Fatalf("expected no entries from a missing heap, got %d %v", status, entries)
+	}
+	for _, c := range []struct{ path, body string }{
+		{"/push", `[{"priority": 1}, {"priority": "x"}]`},
+		{"/pop?count=-1", ""},
+		{"/pop?count=x", ""},
+	} {
+		if status := call(t, srv, "POST", c.path, c.body, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", c.path, status)
+		}
+	}
+	if call(t, srv, "GET", "/size", "", &size); size["size"] != 0 {
+		t.Fatalf("expected an invalid batch to push nothing, got %v", size)
+	}
+}
+
+func TestHTTPErrors(t *testing.T) {
+	reg, _ := newRegistry("pairing")
+	srv := httptest.NewServer(handler(reg))
+	defer srv.Close()
+	for _, c := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{"GET", "/push", "", http.StatusMethodNotAllowed},
+		{"POST", "/push", "{", http.StatusBadRequest},
+		{"GET", "/pop", "", http.StatusMethodNotAllowed},
+		{"GET", "/peek", "", http.StatusNotFound},
+		{"POST", "/pop?key=missing", "", http.StatusNotFound},
+	} {
+		if status := call(t, srv, c.method, c.path, c.body, nil); status != c.status {
+			t.Errorf("%s %s: expected %d, got %d", c.method, c.path, c.status, status)
+		}
+	}
+	if _, err := newRegistry("btree"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+
+	// infinities cannot be pushed, nor encoded
+	s := reg.get("default", true)
+	if err := s.push(Entry{Priority: math.Inf(-1)}); err == nil || s.len() != 0 {
+		t.Fatal("expected an infinite priority to be rejected")
+	}
+	w := httptest.NewRecorder()
+	reply(w, Entry{Priority: math.Inf(1)})
+	if w.Code != http.StatusInternalServerError || w.Body.Len() == 0 {
+		t.Fatalf("expected an internal server error, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestStats(t *testing.T) {
+	s, _ := newService("pairing")
+	clock := time.Unix(1000, 0)
+	s.now = func() time.Time { return clock }
+
+	stats := s.stats()
+	if stats.MinPriority != nil || stats.Ages.OldestSeconds != 0 || len(stats.Ops) != 0 {
+		t.Fatalf("unexpected stats of an empty heap %+v", stats)
+	}
+	s.push(Entry{Priority: 3, Value: "old"})
+	clock = clock.Add(30 * time.Second)
+	s.push(Entry{Priority: 2, Value: "recent"})
+	s.push(Entry{Priority: 5, Value: "recent"})
+	clock = clock.Add(2 * time.Second)
+	s.push(Entry{Priority: 4, Value: "new"})
+	s.pop()
+
+	stats = s.stats()
+	if stats.MinPriority == nil || *stats.MinPriority != 3 {
+		t.Fatalf("expected min priority 3, got %v", stats.MinPriority)
+	}
+	if stats.Ages.OldestSeconds != 32 {
+		t.Fatalf("expected the oldest entry to be 32s old, got %d", stats.Ages.OldestSeconds)
+	}
+	if want := []int{1, 1, 1, 0, 0, 0}; !equalInts(stats.Ages.Buckets, want) {
+		t.Fatalf("expected age buckets %v, got %v", want, stats.Ages.Buckets)
+	}
+	if stats.Ops["Insert"].Count != 4 || stats.Ops["DeleteMin"].Count != 1 {
+		t.Fatalf("unexpected operation counters %+v", stats.Ops)
+	}
+	if stats.Ops["DeleteMin"].Comparisons == 0 {
+		t.Fatal("expected DeleteMin to count its comparisons")
+	}
+	// taking stats is not an operation
+	if _, ok := s.stats().Ops["FindMin"]; ok {
+		t.Fatal("expected stats not to count as a FindMin")
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStatsUnderLoad(t *testing.T) {
+	s, _ := newService("fibonacci")
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				s.push(Entry{Priority: float64((i * 7919) % 1000)})
+				if i%3 == 0 {
+					s.pop()
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for scraping := true; scraping; {
+		select {
+		case <-done:
+			scraping = false
+		default:
+		}
+		stats := s.stats()
+		if uint64(stats.Size) != stats.Pushes-stats.Pops {
+			t.Fatalf("inconsistent snapshot %+v", stats)
+		}
+		if stats.Ops["Insert"].Count != stats.Pushes || stats.Ops["DeleteMin"].Count != stats.Pops {
+			t.Fatalf("operation counters disagree with %+v", stats)
+		}
+		queued := 0
+		for _, n := range stats.Ages.Buckets {
+			queued += n
+		}
+		if queued != stats.Size {
+			t.Fatalf("age distribution counts %d entries, expected %d", queued, stats.Size)
+		}
+	}
+}
+
+// BenchmarkService pushes and pops entries from concurrent clients, one by
+// one or in batches of 64.
+func BenchmarkService(b *testing.B) {
+	const batch = 64
+	entries := make([]Entry, batch)
+	for i := range entries {
+		entries[i] = Entry{Priority: float64((i * 7919) % batch)}
+	}
+	b.Run("OneByOne", func(b *testing.B) {
+		s, _ := newService("pairing")
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				for _, e := range entries {
+					s.push(e)
+				}
+				for range entries {
+					s.pop()
+				}
+			}
+		})
+	})
+	b.Run("Many", func(b *testing.B) {
+		s, _ := newService("pairing")
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.pushMany(entries)
+				s.popMany(batch)
+			}
+		})
+	})
+}
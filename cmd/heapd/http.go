@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// handler serves the HTTP API of the heaps of reg. Every request names its
+// heap with the key query parameter, "default" if omitted:
+//
+//	POST /push    {"priority": 1.5, "value": "job"}, replies {"size": n}
+//	POST /push    [{"priority": 1.5, "value": "job"}, ...], pushes a batch
+//	POST /pop     replies with the entry of lowest priority, 404 if empty
+//	POST /pop?count=n
+//	              replies with up to n entries in ascending order
+//	GET  /peek    replies with the entry of lowest priority, 404 if empty
+//	GET  /size    replies {"size": n}
+//	GET  /stats   replies with the Stats of the heap, a consistent snapshot
+func handler(reg *registry) http.Handler {
+	// heap returns the heap named by r, or nil if it does not exist and
+	// create is false
+	heap := func(r *http.Request, create bool) *service {
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			key = "default"
+		}
+		return reg.get(key, create)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/push", method("POST", func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var entries []Entry
+		var err error
+		if bytes.HasPrefix(body, []byte("[")) {
+			err = json.Unmarshal(body, &entries)
+		} else {
+			entries = make([]Entry, 1)
+			err = json.Unmarshal(body, &entries[0])
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s := heap(r, true)
+		if err := s.pushMany(entries); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply(w, map[string]int{"size": s.len()})
+	}))
+	mux.HandleFunc("/pop", method("POST", func(w http.ResponseWriter, r *http.Request) {
+		count := r.URL.Query().Get("count")
+		if count == "" {
+			replyEntry(w, heap(r, false), (*service).pop)
+			return
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			http.Error(w, "count must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		entries := []Entry{}
+		if s := heap(r, false); s != nil {
+			entries = s.popMany(n)
+		}
+		reply(w, entries)
+	}))
+	mux.HandleFunc("/peek", method("GET", func(w http.ResponseWriter, r *http.Request) {
+		replyEntry(w, heap(r, false), (*service).peek)
+	}))
+	mux.HandleFunc("/size", method("GET", func(w http.ResponseWriter, r *http.Request) {
+		size := 0
+		if s := heap(r, false); s != nil {
+			size = s.len()
+		}
+		reply(w, map[string]int{"size": size})
+	}))
+	mux.HandleFunc("/stats", method("GET", func(w http.ResponseWriter, r *http.Request) {
+		stats := emptyStats(reg.backend)
+		if s := heap(r, false); s != nil {
+			stats = s.stats()
+		}
+		reply(w, stats)
+	}))
+	return mux
+}
+
+// method restricts h to requests with the given method.
+func method(m string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != m {
+			w.Header().Set("Allow", m)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func replyEntry(w http.ResponseWriter, s *service, get func(*service) (Entry, bool)) {
+	var e Entry
+	ok := false
+	if s != nil {
+		e, ok = get(s)
+	}
+	if !ok {
+		http.Error(w, "heap is empty", http.StatusNotFound)
+		return
+	}
+	reply(w, e)
+}
+
+// reply writes v as JSON, or an internal server error if it cannot be
+// encoded rather than an empty body.
+func reply(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,65 @@
+// Command heapd serves priority queues over HTTP and the Redis protocol, as
+// a starting point for a priority queue service backed by the heaps of this
+// library.
+//
+// Usage:
+//
+//	heapd [-http host:port] [-resp host:port] [-backend pairing]
+//
+// heapd holds any number of heaps, named by keys and created on first
+// push. Entries hold a float priority, lower first, and a string value.
+// Entries of equal priority are popped in the order they were pushed.
+//
+// The HTTP API exchanges JSON and names the heap with the key query
+// parameter, "default" if omitted:
+//
+//	POST /push    {"priority": 1.5, "value": "job"}, replies {"size": n}
+//	POST /push    [{"priority": 1.5, "value": "job"}, ...], pushes a batch
+//	POST /pop     replies with the entry of lowest priority, 404 if empty
+//	POST /pop?count=n
+//	              replies with up to n entries in ascending order
+//	GET  /peek    replies with the entry of lowest priority, 404 if empty
+//	GET  /size    replies {"size": n}
+//	GET  /stats   replies with a snapshot of the size, lowest priority, age
+//	              distribution and operation counters of the heap
+//
+// With -resp, heapd also speaks RESP, the Redis protocol, so that existing
+// Redis clients can use it in tests. It implements the sorted set commands
+// ZADD, ZPOPMIN and ZCARD, plus DEL, PING, QUIT and COMMAND. Each key is a
+// heap rather than a set: members are not unique and ZADD always adds.
+//
+// The backing heap is chosen with -backend among pairing, leftist, skew,
+// fibonacci, binomial, rank_pairing and treap. The queues live in memory
+// and are lost when heapd exits.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("http", "localhost:8080", "address to serve the HTTP API on")
+	resp := flag.String("resp", "", "address to serve the Redis protocol on, disabled if empty")
+	backend := flag.String("backend", "pairing", "heap implementation: "+backendNames())
+	flag.Parse()
+
+	reg, err := newRegistry(*backend)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *resp != "" {
+		l, err := net.Listen("tcp", *resp)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("heapd: serving the Redis protocol on %s", l.Addr())
+		go func() {
+			log.Fatal(serveRESP(l, reg))
+		}()
+	}
+	log.Printf("heapd: serving %s heaps on http://%s/", *backend, *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler(reg)))
+}
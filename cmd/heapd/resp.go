@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxArgs    = 1 << 20
+	maxBulkLen = 1 << 24
+)
+
+// serveRESP serves the heaps of reg to Redis clients connecting to l, with
+// a subset of the sorted set commands. Each key is a heap, so members are
+// not unique and ZADD always adds:
+//
+//	ZADD key score member [score member ...]   number of entries added
+//	ZPOPMIN key [count]                        member, score, ...
+//	ZCARD key                                  number of entries
+//	DEL key [key ...]                          number of heaps deleted
+//	PING [message], QUIT, COMMAND
+//
+// Commands are read in the RESP array format sent by clients or inline, as
+// typed in a telnet session.
+func serveRESP(l net.Listener, reg *registry) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			serveConn(conn, reg)
+		}()
+	}
+}
+
+func serveConn(rw io.ReadWriter, reg *registry) {
+	r := bufio.NewReader(rw)
+	w := bufio.NewWriter(rw)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if err != io.EOF {
+				writeError(w, err.Error())
+				w.Flush()
+			}
+			return
+		}
+		quit := len(args) > 0 && execute(w, reg, args)
+		// flush once the pipelined commands read so far are answered
+		if r.Buffered() == 0 || quit {
+			if w.Flush() != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// execute runs the command args and writes its reply. It returns true if
+// the connection should be closed.
+func execute(w *bufio.Writer, reg *registry, args []string) bool {
+	cmd, args := args[0], args[1:]
+	name := strings.ToUpper(cmd)
+	switch name {
+	case "PING":
+		switch len(args) {
+		case 0:
+			fmt.Fprint(w, "+PONG\r\n")
+		case 1:
+			writeBulk(w, args[0])
+		default:
+			writeArity(w, name)
+		}
+	case "QUIT":
+		fmt.Fprint(w, "+OK\r\n")
+		return true
+	case "COMMAND":
+		fmt.Fprint(w, "*0\r\n")
+	case "ZADD":
+		if len(args) < 3 || len(args)%2 != 1 {
+			writeArity(w, name)
+			return false
+		}
+		entries := make([]Entry, 0, len(args)/2)
+		for i := 1; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			// JSON cannot encode infinities, so the HTTP API could not
+			// return them
+			if err != nil || math.IsNaN(score) || math.IsInf(score, 0) {
+				writeError(w, "ERR value is not a valid float")
+				return false
+			}
+			entries = append(entries, Entry{Priority: score, Value: args[i+1]})
+		}
+		if err := reg.get(args[0], true).pushMany(entries); err != nil {
+			writeError(w, "ERR "+err.Error())
+			return false
+		}
+		fmt.Fprintf(w, ":%d\r\n", len(entries))
+	case "ZPOPMIN":
+		if len(args) < 1 || len(args) > 2 {
+			writeArity(w, name)
+			return false
+		}
+		count := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				writeError(w, "ERR value is out of range, must be positive")
+				return false
+			}
+			count = n
+		}
+		var popped []Entry
+		if s := reg.get(args[0], false); s != nil {
+			// atomic like in Redis: no other client pops in between
+			popped = s.popMany(count)
+		}
+		fmt.Fprintf(w, "*%d\r\n", 2*len(popped))
+		for _, e := range popped {
+			writeBulk(w, e.Value)
+			writeBulk(w, formatScore(e.Priority))
+		}
+	case "ZCARD":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		size := 0
+		if s := reg.get(args[0], false); s != nil {
+			size = s.len()
+		}
+		fmt.Fprintf(w, ":%d\r\n", size)
+	case "DEL":
+		if len(args) < 1 {
+			writeArity(w, name)
+			return false
+		}
+		deleted := 0
+		for _, key := range args {
+			if reg.remove(key) {
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", cmd))
+	}
+	return false
+}
+
+// readCommand reads the arguments of the next command, or none for an
+// empty inline line.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > maxArgs {
+		return nil, protocolError("invalid multibulk length")
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, protocolError(fmt.Sprintf("expected '$', got %q", line))
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, protocolError("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, protocolError("bulk string not terminated")
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func protocolError(msg string) error {
+	return fmt.Errorf("ERR Protocol error: %s", msg)
+}
+
+// readLine reads a line terminated by CRLF, or LF for inline commands.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// formatScore formats a score like Redis does. Scores are finite.
+func formatScore(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+func writeArity(w *bufio.Writer, name string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestRESP(t *testing.T) {
+	reg, _ := newRegistry("pairing")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go serveRESP(l, reg)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for _, c := range []struct {
+		send, want string
+	}{
+		{"PING\r\n", "+PONG\r\n"},
+		{"*2\r\n$4\r\necho\r\n$2\r\nhi\r\n", "-ERR unknown command 'echo'\r\n"},
+		{"*6\r\n$4\r\nZADD\r\n$1\r\nq\r\n$1\r\n3\r\n$1\r\nc\r\n$3\r\n1.5\r\n$1\r\na\r\n", ":2\r\n"},
+		{"zadd q -1e300 b\r\n", ":1\r\n"},
+		{"ZADD q nan x\r\n", "-ERR value is not a valid float\r\n"},
+		{"ZADD q +inf x\r\n", "-ERR value is not a valid float\r\n"},
+		{"ZADD q 1 y -inf x\r\n", "-ERR value is not a valid float\r\n"},
+		{"ZADD q 1\r\n", "-ERR wrong number of arguments for 'zadd' command\r\n"},
+		{"ZCARD q\r\n", ":3\r\n"},
+		{"ZPOPMIN q 0\r\n", "*0\r\n"},
+		{"ZPOPMIN q 2\r\n", "*4\r\n$1\r\nb\r\n$7\r\n-1e+300\r\n$1\r\na\r\n$3\r\n1.5\r\n"},
+		{"ZPOPMIN missing\r\n", "*0\r\n"},
+		// pipelined commands are all answered
+		{"\r\nZCARD q\r\nPING hello\r\n", ":1\r\n$5\r\nhello\r\n"},
+		{"DEL q missing\r\n", ":1\r\n"},
+		{"ZCARD q\r\n", ":0\r\n"},
+		{"QUIT\r\n", "+OK\r\n"},
+	} {
+		if _, err := conn.Write([]byte(c.send)); err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(c.want))
+		for n := 0; n < len(got); {
+			m, err := r.Read(got[n:])
+			if err != nil {
+				t.Fatalf("%q: %v after %q", c.send, err, got[:n])
+			}
+			n += m
+		}
+		if string(got) != c.want {
+			t.Fatalf("%q: expected %q, got %q", c.send, c.want, got)
+		}
+	}
+	if _, err := r.ReadByte(); err == nil {
+		t.Fatal("expected QUIT to close the connection")
+	}
+}
+
+func TestRESPProtocolError(t *testing.T) {
+	reg, _ := newRegistry("pairing")
+	for _, send := range []string{"*x\r\n", "*1\r\n+PING\r\n", "*1\r\n$4\r\nPINGxx"} {
+		var out bytes.Buffer
+		serveConn(struct {
+			io.Reader
+			io.Writer
+		}{strings.NewReader(send), &out}, reg)
+		if !strings.HasPrefix(out.String(), "-ERR Protocol error") {
+			t.Errorf("%q: expected a protocol error, got %q", send, out.String())
+		}
+	}
+}
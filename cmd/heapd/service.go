@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/instrument"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/treap"
+)
+
+// backends are the heap implementations a service can be backed by.
+var backends = map[string]func() heap.Interface{
+	"pairing":      func() heap.Interface { return pairing.New() },
+	"leftist":      func() heap.Interface { return leftist.New() },
+	"skew":         func() heap.Interface { return skew.New() },
+	"fibonacci":    func() heap.Interface { return fibonacci.New() },
+	"binomial":     func() heap.Interface { return &binomial.BinomialHeap{} },
+	"rank_pairing": func() heap.Interface { return rpheap.New() },
+	"treap":        func() heap.Interface { return treap.New() },
+}
+
+func backendNames() string {
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Entry is a value queued at a priority.
+type Entry struct {
+	Priority float64 `json:"priority"`
+	Value    string  `json:"value"`
+}
+
+// item is the heap item of an entry. Entries of equal priority are popped
+// first in first out whatever the backend.
+type item struct {
+	Entry
+	seq    uint64
+	pushed int64 // unix second of the push, for the age distribution
+}
+
+func (i item) Compare(than heap.Item) int {
+	o := than.(item)
+	switch {
+	case i.Priority < o.Priority:
+		return -1
+	case i.Priority > o.Priority:
+		return 1
+	case i.seq < o.seq:
+		return -1
+	case i.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// service is a heap shared by the clients of every front-end. Its heap is
+// instrumented to count the comparisons and time spent by each operation.
+type service struct {
+	mu      sync.Mutex
+	backend string
+	heap    *instrument.Heap
+	seq     uint64
+	size    int
+	pushes  uint64
+	pops    uint64
+	ops     map[string]OpStats
+	// pushed counts the queued entries by the unix second they were pushed
+	pushed map[int64]int
+	now    func() time.Time
+}
+
+// foldEvery is the number of instrumentation records buffered before they
+// are folded into the operation counters.
+const foldEvery = 1024
+
+func newService(backend string) (*service, error) {
+	newHeap, ok := backends[backend]
+	if !ok {
+		return nil, fmt.Errorf("heapd: unknown backend %q, expected one of %s", backend, backendNames())
+	}
+	return &service{
+		backend: backend,
+		// hide the Potential method of the backend, which instrument
+		// would call after every operation and which is not constant
+		// time for every heap
+		heap:   instrument.New(struct{ heap.Interface }{newHeap()}),
+		ops:    make(map[string]OpStats),
+		pushed: make(map[int64]int),
+		now:    time.Now,
+	}, nil
+}
+
+// registry holds the heaps of a server by key, creating them on first use.
+type registry struct {
+	mu       sync.Mutex
+	backend  string
+	services map[string]*service
+}
+
+func newRegistry(backend string) (*registry, error) {
+	if _, ok := backends[backend]; !ok {
+		return nil, fmt.Errorf("heapd: unknown backend %q, expected one of %s", backend, backendNames())
+	}
+	return &registry{backend: backend, services: make(map[string]*service)}, nil
+}
+
+// get returns the heap of key, creating it if create is true, or nil.
+func (r *registry) get(key string, create bool) *service {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.services[key]
+	if !ok && create {
+		s, _ = newService(r.backend)
+		r.services[key] = s
+	}
+	return s
+}
+
+// remove drops the heap of key and reports whether there was one.
+func (r *registry) remove(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.services[key]
+	delete(r.services, key)
+	return ok
+}
+
+func (s *service) push(e Entry) error {
+	return s.pushMany([]Entry{e})
+}
+
+// pushMany pushes entries under a single acquisition of the lock. It pushes
+// none of them if one is invalid.
+func (s *service) pushMany(entries []Entry) error {
+	for _, e := range entries {
+		if math.IsNaN(e.Priority) || math.IsInf(e.Priority, 0) {
+			return fmt.Errorf("heapd: priority %v is not finite", e.Priority)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sec := s.now().Unix()
+	items := make([]heap.Item, len(entries))
+	for i, e := range entries {
+		s.seq++
+		items[i] = item{Entry: e, seq: s.seq, pushed: sec}
+	}
+	heap.PushMany(s.heap, items...)
+	s.pushed[sec] += len(entries)
+	s.size += len(entries)
+	s.pushes += uint64(len(entries))
+	s.fold(false)
+	return nil
+}
+
+func (s *service) pop() (Entry, bool) {
+	popped := s.popMany(1)
+	if len(popped) == 0 {
+		return Entry{}, false
+	}
+	return popped[0], true
+}
+
+// popMany pops up to n entries under a single acquisition of the lock.
+func (s *service) popMany(n int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.size {
+		n = s.size
+	}
+	items := heap.PopMany(s.heap, n)
+	entries := make([]Entry, len(items))
+	for i, it := range items {
+		it := it.(item)
+		if s.pushed[it.pushed]--; s.pushed[it.pushed] == 0 {
+			delete(s.pushed, it.pushed)
+		}
+		entries[i] = it.Entry
+	}
+	s.size -= len(items)
+	s.pops += uint64(len(items))
+	s.fold(false)
+	return entries
+}
+
+func (s *service) peek() (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return Entry{}, false
+	}
+	e := s.heap.FindMin().(item).Entry
+	s.fold(false)
+	return e, true
+}
+
+func (s *service) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// fold adds the buffered instrumentation records to the operation counters,
+// once there are foldEvery of them or if all is true.
+func (s *service) fold(all bool) {
+	records := s.heap.Records()
+	if len(records) < foldEvery && !all {
+		return
+	}
+	for _, r := range records {
+		op := s.ops[r.Op]
+		op.Count++
+		op.Comparisons += uint64(r.Comparisons)
+		op.Nanoseconds += uint64(r.Duration)
+		s.ops[r.Op] = op
+	}
+	s.heap.Reset()
+}
+
+// Stats describes the state of the service. It is a consistent snapshot:
+// no operation runs while it is taken.
+type Stats struct {
+	Backend string `json:"backend"`
+	Size    int    `json:"size"`
+	Pushes  uint64 `json:"pushes"`
+	Pops    uint64 `json:"pops"`
+	// MinPriority is the lowest priority queued, nil if the heap is empty.
+	MinPriority *float64 `json:"min_priority"`
+	// Ages is the distribution of the time the queued entries have waited
+	// so far, at the resolution of a second.
+	Ages Ages `json:"ages"`
+	// Ops holds the counters of the operations on the backing heap, by
+	// name, such as Insert and DeleteMin.
+	Ops map[string]OpStats `json:"ops"`
+}
+
+// Ages counts the queued entries by age. Buckets holds the number of
+// entries younger than each bound of AgeBounds, and Oldest is the age of the
+// oldest entry.
+type Ages struct {
+	OldestSeconds int64 `json:"oldest_seconds"`
+	Buckets       []int `json:"buckets"`
+}
+
+// AgeBounds are the upper bounds, in seconds, of the buckets of Ages. The
+// last bucket counts the entries that waited longer than all of them.
+var AgeBounds = []int64{1, 10, 60, 600, 3600}
+
+// OpStats holds the counters of an operation on the backing heap.
+type OpStats struct {
+	Count       uint64 `json:"count"`
+	Comparisons uint64 `json:"comparisons"`
+	Nanoseconds uint64 `json:"nanoseconds"`
+}
+
+// emptyStats returns the Stats of an empty heap that was never used.
+func emptyStats(backend string) Stats {
+	return Stats{
+		Backend: backend,
+		Ages:    Ages{Buckets: make([]int, len(AgeBounds)+1)},
+		Ops:     make(map[string]OpStats),
+	}
+}
+
+func (s *service) stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fold(true)
+	stats := emptyStats(s.backend)
+	stats.Size, stats.Pushes, stats.Pops = s.size, s.pushes, s.pops
+	for name, op := range s.ops {
+		stats.Ops[name] = op
+	}
+	if s.size > 0 {
+		min := s.heap.FindMin().(item).Priority
+		stats.MinPriority = &min
+		// the lookup is not an operation of a client
+		s.heap.Reset()
+	}
+	now := s.now().Unix()
+	for sec, n := range s.pushed {
+		age := now - sec
+		if age > stats.Ages.OldestSeconds {
+			stats.Ages.OldestSeconds = age
+		}
+		b := sort.Search(len(AgeBounds), func(i int) bool { return age < AgeBounds[i] })
+		stats.Ages.Buckets[b] += n
+	}
+	return stats
+}
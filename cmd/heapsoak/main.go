@@ -0,0 +1,161 @@
+// Command heapsoak soak tests the heap implementations of this library for
+// release qualification.
+//
+// Usage:
+//
+//	heapsoak [-heap all] [-duration 1h] [-clients n] [-seed s] [-ops 10000]
+//	         [-rounds 0] [-validate 1000] [-report 1m] [-traces dir] [-max 10]
+//
+// heapsoak runs rounds of random operations until -duration has elapsed or
+// -rounds rounds have run. Every round builds a new heap, takes turns among
+// the heaps named by -heap, a comma separated list or "all", and applies a
+// random mix of -ops Insert, DeleteMin, Delete and Adjust operations, the
+// last two on heaps implementing Extended only. Results are checked against
+// a model of the heap's contents, and every -validate operations so are the
+// minimum, the size and, for heaps with a Validate method, their internal
+// invariants. Each round ends by draining the heap in order.
+//
+// The heaps are not thread safe, so the -clients concurrent clients each
+// run their own rounds on their own heaps, which soaks the implementations
+// under a loaded scheduler and garbage collector.
+//
+// Round i uses the seed -seed + i, which determines its operations and
+// its mix. When a round finds a violation, heapsoak reports the heap, the
+// seed and the failing operation, writes the trace of the round as recorded
+// by trace.Recorder to -traces, and prints the command reproducing it. It
+// stops after -max violations and exits with status 1 if there was any.
+// Progress is reported every -report.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// soak runs rounds from concurrent clients and collects their results.
+type soak struct {
+	names    []string
+	seed     int64
+	ops      int
+	validate int
+	rounds   int64 // 0 for no limit
+	deadline time.Time
+	max      int
+
+	next int64 // the index of the next round, accessed atomically
+	done int64 // the number of rounds run, accessed atomically
+
+	mu         sync.Mutex
+	violations []*Violation
+}
+
+// run runs rounds on clients goroutines until the deadline, the round limit
+// or the violation limit is reached, calling found for every violation.
+func (s *soak) run(clients int, found func(*Violation)) {
+	var wg sync.WaitGroup
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !s.stopped() {
+				i := atomic.AddInt64(&s.next, 1) - 1
+				if s.rounds > 0 && i >= s.rounds {
+					return
+				}
+				name := s.names[int(i%int64(len(s.names)))]
+				v := round(name, heaps[name], s.seed+i, s.ops, s.validate)
+				atomic.AddInt64(&s.done, 1)
+				if v != nil {
+					s.mu.Lock()
+					if len(s.violations) < s.max {
+						s.violations = append(s.violations, v)
+						found(v)
+					}
+					s.mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *soak) stopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.violations) >= s.max || time.Now().After(s.deadline)
+}
+
+func main() {
+	names := flag.String("heap", "all", "comma separated heaps to soak, or all: "+strings.Join(heapNames(), ", "))
+	duration := flag.Duration("duration", time.Hour, "how long to soak")
+	clients := flag.Int("clients", runtime.NumCPU(), "number of concurrent clients")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the first round")
+	ops := flag.Int("ops", 10000, "number of operations per round")
+	rounds := flag.Int64("rounds", 0, "number of rounds to run, 0 for no limit")
+	validate := flag.Int("validate", 1000, "validate the heap every `n` operations, 0 for only at the end of rounds")
+	report := flag.Duration("report", time.Minute, "interval between progress reports")
+	traces := flag.String("traces", ".", "`dir`ectory to write the traces of violations to")
+	max := flag.Int("max", 10, "stop after this many violations")
+	flag.Parse()
+
+	s := &soak{
+		seed:     *seed,
+		ops:      *ops,
+		validate: *validate,
+		rounds:   *rounds,
+		deadline: time.Now().Add(*duration),
+		max:      *max,
+	}
+	if *names == "all" {
+		s.names = heapNames()
+	} else {
+		for _, name := range strings.Split(*names, ",") {
+			if _, ok := heaps[name]; !ok {
+				log.Fatalf("heapsoak: unknown heap %q, expected one of %s", name, strings.Join(heapNames(), ", "))
+			}
+			s.names = append(s.names, name)
+		}
+	}
+	if *ops <= 0 || *clients <= 0 || *max <= 0 {
+		log.Fatal("heapsoak: -ops, -clients and -max must be positive")
+	}
+
+	log.Printf("heapsoak: soaking %s for %v from seed %d with %d clients",
+		strings.Join(s.names, ", "), *duration, s.seed, *clients)
+	start := time.Now()
+	ticker := time.NewTicker(*report)
+	defer ticker.Stop()
+	go func() {
+		for range ticker.C {
+			done := atomic.LoadInt64(&s.done)
+			log.Printf("heapsoak: %d rounds, %.0f ops/s", done,
+				float64(done)*float64(s.ops)/time.Since(start).Seconds())
+		}
+	}()
+
+	s.run(*clients, func(v *Violation) {
+		path := filepath.Join(*traces, fmt.Sprintf("heapsoak-%s-%d.trace", v.Heap, v.Seed))
+		if err := ioutil.WriteFile(path, v.Trace, 0644); err != nil {
+			log.Printf("heapsoak: writing the trace: %v", err)
+			path = "(not written)"
+		}
+		log.Printf("heapsoak: VIOLATION in %s, seed %d, operation %d of %d: %s\n"+
+			"\ttrace: %s\n\treproduce: heapsoak -heap %s -seed %d -ops %d -rounds 1 -clients 1 -validate 1",
+			v.Heap, v.Seed, v.Step, v.Ops, v.Err, path, v.Heap, v.Seed, v.Ops)
+	})
+
+	log.Printf("heapsoak: %d rounds of %d operations in %v, %d violations",
+		atomic.LoadInt64(&s.done), s.ops, time.Since(start).Round(time.Second), len(s.violations))
+	if len(s.violations) > 0 {
+		os.Exit(1)
+	}
+}
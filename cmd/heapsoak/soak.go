@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/counting"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/heaptest"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/quake"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/trace"
+	"github.com/theodesp/go-heaps/treap"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+// heaps are the implementations heapsoak can soak. A new implementation is
+// qualified by adding it here.
+var heaps = map[string]func() heap.Interface{
+	"pairing":           func() heap.Interface { return pairing.New() },
+	"pairing-multipass": func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.MultiPass)) },
+	"pairing-lazy":      func() heap.Interface { return pairing.New(pairing.WithLazyInsert()) },
+	"pairing-auxiliary": func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.Auxiliary)) },
+	"leftist":           func() heap.Interface { return leftist.New() },
+	"skew":              func() heap.Interface { return skew.New() },
+	"fibonacci":         func() heap.Interface { return fibonacci.New() },
+	"binomial":          func() heap.Interface { return &binomial.BinomialHeap{} },
+	"rank_pairing":      func() heap.Interface { return rpheap.New() },
+	"treap":             func() heap.Interface { return treap.New() },
+	"quake":             func() heap.Interface { return quake.New() },
+	"violation":         func() heap.Interface { return violation.New() },
+	"counting":          func() heap.Interface { return counting.New() },
+}
+
+func heapNames() []string {
+	var names []string
+	for name := range heaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Violation is an invariant violation found by a round.
+type Violation struct {
+	Heap string
+	Seed int64
+	Ops  int
+	// Step is the index of the operation that exposed the violation, or
+	// len(ops) when found by the final drain.
+	Step int
+	Err  string
+	// Trace is the trace of the operations applied up to the violation.
+	Trace []byte
+}
+
+// round runs n random operations generated from seed on a new heap, checking
+// every result against a model of its contents, and the minimum, the size
+// and the heap's own Validate method every validate operations. The heap is
+// drained at the end to check that everything comes out in order. It
+// returns nil if no violation was found.
+func round(name string, newHeap func() heap.Interface, seed int64, n, validate int) (v *Violation) {
+	h := newHeap()
+	var buf bytes.Buffer
+	rec := trace.NewRecorder(h, &buf)
+	step := 0
+	fail := func(format string, args ...interface{}) *Violation {
+		return &Violation{Heap: name, Seed: seed, Ops: n, Step: step,
+			Err: fmt.Sprintf(format, args...), Trace: buf.Bytes()}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			v = fail("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	ops := heaptest.RandomOps(seed, n, mix(seed, h)...)
+	var contents []int // the model, sorted
+	for ; step < len(ops); step++ {
+		op := ops[step]
+		got := op.Apply(rec)
+		if op.Want != nil && !trace.Same(got, op.Want) {
+			return fail("%v returned %v, expected %v", op, got, op.Want)
+		}
+		switch op.Kind {
+		case heaptest.Insert:
+			contents = insert(contents, op.Item)
+		case heaptest.DeleteMin:
+			contents = contents[1:]
+		case heaptest.Delete:
+			contents = remove(contents, op.Item)
+		case heaptest.Adjust:
+			contents = insert(remove(contents, op.Item), op.New)
+		}
+		if validate > 0 && (step+1)%validate == 0 {
+			if err := check(rec, h, contents); err != "" {
+				return fail("after %v: %s", op, err)
+			}
+		}
+	}
+
+	if err := check(rec, h, contents); err != "" {
+		return fail("at the end: %s", err)
+	}
+	for _, want := range contents {
+		if got := rec.DeleteMin(); !trace.Same(got, heap.Integer(want)) {
+			return fail("draining: DeleteMin returned %v, expected %d", got, want)
+		}
+	}
+	if got := rec.DeleteMin(); got != nil {
+		return fail("draining: DeleteMin returned %v from an empty heap", got)
+	}
+	return nil
+}
+
+// mix returns the kinds of operations of the round of seed: Insert, and
+// DeleteMin, Delete and Adjust with random weights, the last two only if h
+// implements heap.Extended. Rounds thus range from growing heaps to heaps
+// churning around a small size.
+func mix(seed int64, h heap.Interface) []heaptest.Kind {
+	r := rand.New(rand.NewSource(^seed))
+	weights := map[heaptest.Kind]int{
+		heaptest.Insert:    1 + r.Intn(4),
+		heaptest.DeleteMin: r.Intn(4),
+	}
+	if _, ok := h.(heap.Extended); ok {
+		weights[heaptest.Delete] = r.Intn(3)
+		weights[heaptest.Adjust] = r.Intn(3)
+	}
+	var kinds []heaptest.Kind
+	for _, kind := range []heaptest.Kind{heaptest.Insert, heaptest.DeleteMin, heaptest.Delete, heaptest.Adjust} {
+		for i := 0; i < weights[kind]; i++ {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// check compares the minimum and, if h has a Len method, the size of h with
+// the model, and calls the Validate method of h if it has one. It returns a
+// description of the first violation, or "".
+func check(rec *trace.Recorder, h heap.Interface, contents []int) string {
+	var want heap.Item
+	if len(contents) > 0 {
+		want = heap.Integer(contents[0])
+	}
+	if got := rec.FindMin(); !trace.Same(got, want) {
+		return fmt.Sprintf("FindMin returned %v, expected %v", got, want)
+	}
+	if l, ok := h.(interface{ Len() int }); ok && l.Len() != len(contents) {
+		return fmt.Sprintf("Len returned %d, expected %d", l.Len(), len(contents))
+	}
+	if v, ok := h.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+func insert(contents []int, item heap.Item) []int {
+	v := int(item.(heap.Integer))
+	i := sort.SearchInts(contents, v)
+	contents = append(contents, 0)
+	copy(contents[i+1:], contents[i:])
+	contents[i] = v
+	return contents
+}
+
+func remove(contents []int, item heap.Item) []int {
+	i := sort.SearchInts(contents, int(item.(heap.Integer)))
+	return append(contents[:i], contents[i+1:]...)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+func TestRound(t *testing.T) {
+	for _, name := range heapNames() {
+		for seed := int64(0); seed < 5; seed++ {
+			if v := round(name, heaps[name], seed, 500, 50); v != nil {
+				t.Fatalf("%s: seed %d: %s", name, seed, v.Err)
+			}
+		}
+	}
+}
+
+// broken loses the item given to its tenth Insert.
+type broken struct {
+	*pairing.PairHeap
+	inserts int
+}
+
+func (b *broken) Insert(item heap.Item) heap.Item {
+	if b.inserts++; b.inserts == 10 {
+		return item
+	}
+	return b.PairHeap.Insert(item)
+}
+
+func TestViolation(t *testing.T) {
+	newHeap := func() heap.Interface { return &broken{PairHeap: pairing.New()} }
+	v := round("broken", newHeap, 1, 200, 1)
+	if v == nil {
+		t.Fatal("expected a violation")
+	}
+	if v.Seed != 1 || v.Ops != 200 || v.Step < 9 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+	// the seed reproduces the violation, and the trace the calls leading
+	// to it
+	if again := round("broken", newHeap, 1, 200, 1); again.Step != v.Step || again.Err != v.Err {
+		t.Fatalf("expected the same violation, got %+v", again)
+	}
+	events, err := trace.Read(bytes.NewReader(v.Trace), func(s string) (heap.Item, error) {
+		i, err := strconv.Atoi(s)
+		return heap.Integer(i), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trace.Replay(newHeap(), events); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(v.Err, "Len returned 9, expected 10") {
+		t.Fatalf("expected the lost item to show in Len, got %s", v.Err)
+	}
+
+	panicking := func() heap.Interface { return &panicky{pairing.New()} }
+	if v := round("panicky", panicking, 1, 200, 10); v == nil || !strings.HasPrefix(v.Err, "panic: ") {
+		t.Fatalf("expected a panic to be reported, got %+v", v)
+	}
+}
+
+type panicky struct{ *pairing.PairHeap }
+
+func (p *panicky) DeleteMin() heap.Item {
+	panic("boom")
+}
+
+func TestSoak(t *testing.T) {
+	heaps["broken"] = func() heap.Interface { return &broken{PairHeap: pairing.New()} }
+	defer delete(heaps, "broken")
+
+	s := &soak{names: []string{"pairing", "leftist"}, seed: 7, ops: 100, validate: 10,
+		rounds: 20, deadline: time.Now().Add(time.Minute), max: 3}
+	s.run(4, func(v *Violation) { t.Errorf("unexpected violation %s", v.Err) })
+	if s.done != 20 {
+		t.Fatalf("expected 20 rounds, ran %d", s.done)
+	}
+
+	s = &soak{names: []string{"pairing", "broken"}, seed: 7, ops: 100, validate: 10,
+		deadline: time.Now().Add(time.Minute), max: 3}
+	var found []*Violation
+	s.run(4, func(v *Violation) { found = append(found, v) })
+	if len(found) != 3 {
+		t.Fatalf("expected to stop after 3 violations, found %d", len(found))
+	}
+	for _, v := range found {
+		// odd rounds soak the broken heap
+		if v.Heap != "broken" || (v.Seed-7)%2 != 1 {
+			t.Fatalf("unexpected violation in %s with seed %d", v.Heap, v.Seed)
+		}
+	}
+}
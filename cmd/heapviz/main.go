@@ -0,0 +1,93 @@
+// Command heapviz serves a web page showing the structure of a pairing heap
+// and stepping through a recorded trace of operations.
+//
+// Usage:
+//
+//	heapviz [-addr host:port] [-state dump] [-multipass] [-lazy-insert] [-dot] [trace]
+//
+// The heap starts from the dump written by PairHeap.DumpState given with
+// -state, or empty, and the operations of the trace written by
+// trace.Recorder are replayed on it one at a time. Items that parse as
+// integers are replayed as go_heaps.Integer, the others as go_heaps.String.
+// With -dot, heapviz writes the final tree as Graphviz DOT to standard
+// output instead of serving the page.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heapviz"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to serve the page on")
+	state := flag.String("state", "", "load the initial heap from a DumpState `file`")
+	multipass := flag.Bool("multipass", false, "use the multi-pass pairing strategy")
+	lazy := flag.Bool("lazy-insert", false, "defer linking inserted items until the next read, drawn as a dashed forest until then")
+	dot := flag.Bool("dot", false, "write the final tree as DOT to standard output and exit")
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var opts []pairing.Option
+	if *multipass {
+		opts = append(opts, pairing.WithStrategy(pairing.MultiPass))
+	}
+	if *lazy {
+		opts = append(opts, pairing.WithLazyInsert())
+	}
+	p := pairing.New(opts...)
+	if *state != "" {
+		f, err := os.Open(*state)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = p.LoadState(f, parse)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var events []trace.Event
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		events, err = trace.Read(f, parse)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	steps := heapviz.Steps(p, events)
+	if *dot {
+		last := steps[len(steps)-1]
+		if err := heapviz.WriteDOT(os.Stdout, last.Tree, last.Forest...); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fmt.Printf("heapviz: %d steps on http://%s/\n", len(steps)-1, *addr)
+	log.Fatal(http.ListenAndServe(*addr, heapviz.Handler(steps)))
+}
+
+func parse(s string) (heap.Item, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		return heap.Integer(i), nil
+	}
+	return heap.String(s), nil
+}
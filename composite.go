@@ -0,0 +1,30 @@
+package go_heaps
+
+// CompositeItem is an Item made of several keys compared lexicographically:
+// the first keys that differ decide the order, and when one item is a prefix
+// of the other the shorter item is smaller. It suits priorities such as
+// (priority class, deadline, sequence number).
+type CompositeItem []Item
+
+// Composite returns a CompositeItem comparing keys in the given order.
+func Composite(keys ...Item) CompositeItem {
+	return CompositeItem(keys)
+}
+
+// Compare implements the Item interface.
+func (c CompositeItem) Compare(b Item) int {
+	other := b.(CompositeItem)
+	for i := 0; i < len(c) && i < len(other); i++ {
+		if diff := c[i].Compare(other[i]); diff != 0 {
+			return diff
+		}
+	}
+	switch {
+	case len(c) < len(other):
+		return -1
+	case len(c) > len(other):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,275 @@
+// Package conformance checks that a heap implementation honours the
+// contract of the go_heaps interfaces.
+//
+// Run it from a test of the implementation:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, func() go_heaps.Interface { return mypkg.New() })
+//	}
+//
+// The heap must accept go_heaps.Integer items. Heaps implementing Extended
+// are also checked for Delete, Adjust and Meld, and heaps with a
+// Do(go_heaps.ItemIterator) method for iteration. Heaps with a Len() int or
+// Size() int method must report the number of items they hold after every
+// operation.
+package conformance
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heaptest"
+)
+
+// Doer is implemented by heaps that can iterate over their items.
+type Doer interface {
+	Do(it heap.ItemIterator)
+}
+
+// Run runs every conformance check as a subtest of t, on heaps returned by
+// newHeap. newHeap must return a new empty heap on every call.
+func Run(t *testing.T, newHeap func() heap.Interface) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, newHeap) })
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, newHeap) })
+	t.Run("Duplicates", func(t *testing.T) { testDuplicates(t, newHeap) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newHeap) })
+	t.Run("RandomOps", func(t *testing.T) { testRandomOps(t, newHeap) })
+	if _, ok := newHeap().(heap.Extended); ok {
+		t.Run("Delete", func(t *testing.T) { testDelete(t, newHeap) })
+		t.Run("Adjust", func(t *testing.T) { testAdjust(t, newHeap) })
+		t.Run("Meld", func(t *testing.T) { testMeld(t, newHeap) })
+	}
+	if _, ok := newHeap().(Doer); ok {
+		t.Run("Do", func(t *testing.T) { testDo(t, newHeap) })
+	}
+}
+
+func testEmpty(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap()
+	if item := h.FindMin(); item != nil {
+		t.Fatalf("FindMin on an empty heap returned %v", item)
+	}
+	if item := h.DeleteMin(); item != nil {
+		t.Fatalf("DeleteMin on an empty heap returned %v", item)
+	}
+	h.Clear()
+	expectSize(t, h, 0, "Clear")
+
+	h.Insert(heap.Integer(1))
+	expectSize(t, h, 1, "Insert")
+	h.DeleteMin()
+	expectSize(t, h, 0, "DeleteMin")
+	if item := h.FindMin(); item != nil {
+		t.Fatalf("FindMin on an emptied heap returned %v", item)
+	}
+	if item := h.DeleteMin(); item != nil {
+		t.Fatalf("DeleteMin on an emptied heap returned %v", item)
+	}
+}
+
+func testOrdering(t *testing.T, newHeap func() heap.Interface) {
+	for _, n := range []int{1, 2, 3, 10, 100, 1000} {
+		h := newHeap()
+		for i, v := range rand.Perm(n) {
+			if item := h.Insert(heap.Integer(v)); item != heap.Integer(v) {
+				t.Fatalf("Insert(%d) returned %v", v, item)
+			}
+			expectSize(t, h, i+1, "Insert")
+		}
+		expectDrain(t, h, ints(0, n))
+	}
+}
+
+func testDuplicates(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap()
+	var want []int
+	for i := 0; i < 300; i++ {
+		v := rand.Intn(10)
+		h.Insert(heap.Integer(v))
+		want = append(want, v)
+	}
+	sort.Ints(want)
+	expectDrain(t, h, want)
+}
+
+func testClear(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap()
+	for _, v := range rand.Perm(50) {
+		h.Insert(heap.Integer(v))
+	}
+	h.Clear()
+	expectSize(t, h, 0, "Clear")
+	if item := h.FindMin(); item != nil {
+		t.Fatalf("FindMin after Clear returned %v", item)
+	}
+	h.Insert(heap.Integer(7))
+	h.Insert(heap.Integer(3))
+	expectDrain(t, h, []int{3, 7})
+}
+
+func testRandomOps(t *testing.T, newHeap func() heap.Interface) {
+	for seed := int64(0); seed < 5; seed++ {
+		h := newHeap()
+		size := 0
+		for i, op := range heaptest.RandomOps(seed, 2000, heaptest.Insert, heaptest.DeleteMin) {
+			got := op.Apply(h)
+			if op.Want != nil && got != op.Want {
+				t.Fatalf("seed %d: operation %d %v returned %v, want %v", seed, i, op, got, op.Want)
+			}
+			switch {
+			case op.Kind == heaptest.Insert:
+				size++
+			case got != nil:
+				size--
+			}
+			expectSize(t, h, size, op.String())
+		}
+	}
+}
+
+func testDelete(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap().(heap.Extended)
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	var want []int
+	for v := 0; v < 100; v++ {
+		if v%3 != 0 {
+			want = append(want, v)
+			continue
+		}
+		if item := h.Delete(heap.Integer(v)); item != heap.Integer(v) {
+			t.Fatalf("Delete(%d) returned %v", v, item)
+		}
+		expectSize(t, h, 100-v/3-1, "Delete")
+	}
+	expectDrain(t, h, want)
+
+	if item := h.Delete(heap.Integer(1)); item != nil {
+		t.Fatalf("Delete on an empty heap returned %v", item)
+	}
+	expectSize(t, h, 0, "Delete")
+}
+
+func testAdjust(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap().(heap.Extended)
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	// move every multiple of 3 to a distinct value, up or down
+	var want []int
+	for v := 0; v < 100; v++ {
+		if v%3 != 0 {
+			want = append(want, v)
+			continue
+		}
+		nv := -v - 1
+		if v%2 == 0 {
+			nv = v + 1000
+		}
+		if h.Adjust(heap.Integer(v), heap.Integer(nv)) == nil {
+			t.Fatalf("Adjust(%d, %d) returned nil", v, nv)
+		}
+		expectSize(t, h, 100, "Adjust")
+		want = append(want, nv)
+	}
+	sort.Ints(want)
+	expectDrain(t, h, want)
+}
+
+func testMeld(t *testing.T, newHeap func() heap.Interface) {
+	a, b := newHeap().(heap.Extended), newHeap()
+	for v := 0; v < 100; v++ {
+		if v%2 == 0 {
+			a.Insert(heap.Integer(v))
+		} else {
+			b.Insert(heap.Integer(v))
+		}
+	}
+	melded := a.Meld(b)
+	expectSize(t, melded, 100, "Meld")
+	expectDrain(t, melded, ints(0, 100))
+	expectDrain(t, a.Meld(newHeap()), nil)
+}
+
+func testDo(t *testing.T, newHeap func() heap.Interface) {
+	h := newHeap()
+	h.(Doer).Do(func(item heap.Item) bool {
+		t.Fatalf("Do on an empty heap visited %v", item)
+		return false
+	})
+
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	seen := map[heap.Item]int{}
+	h.(Doer).Do(func(item heap.Item) bool {
+		seen[item]++
+		return true
+	})
+	if len(seen) != 100 {
+		t.Fatalf("Do visited %d distinct items, want 100", len(seen))
+	}
+	expectSize(t, h, 100, "Do")
+	for item, count := range seen {
+		if count != 1 {
+			t.Fatalf("Do visited %v %d times", item, count)
+		}
+	}
+
+	visits := 0
+	h.(Doer).Do(func(item heap.Item) bool {
+		visits++
+		return visits < 10
+	})
+	if visits != 10 {
+		t.Fatalf("Do went on for %d visits after the iterator returned false", visits)
+	}
+	expectDrain(t, h, ints(0, 100))
+}
+
+// expectDrain removes every item from h and checks they come out as want.
+func expectDrain(t *testing.T, h heap.Interface, want []int) {
+	t.Helper()
+	expectSize(t, h, len(want), "the operations before the drain")
+	for i, v := range want {
+		if min := h.FindMin(); min != heap.Integer(v) {
+			t.Fatalf("FindMin returned %v at position %d, want %d", min, i, v)
+		}
+		if item := h.DeleteMin(); item != heap.Integer(v) {
+			t.Fatalf("DeleteMin returned %v at position %d, want %d", item, i, v)
+		}
+		expectSize(t, h, len(want)-i-1, "DeleteMin")
+	}
+	if item := h.DeleteMin(); item != nil {
+		t.Fatalf("DeleteMin returned %v after %d items, want nil", item, len(want))
+	}
+}
+
+// expectSize checks that h holds want items after op, if it has a Len or
+// Size method.
+func expectSize(t *testing.T, h heap.Interface, want int, op string) {
+	t.Helper()
+	var size int
+	switch s := h.(type) {
+	case interface{ Len() int }:
+		size = s.Len()
+	case interface{ Size() int }:
+		size = s.Size()
+	default:
+		return
+	}
+	if size != want {
+		t.Fatalf("%T reported %d items after %s, want %d", h, size, op, want)
+	}
+}
+
+func ints(from, to int) []int {
+	out := make([]int, 0, to-from)
+	for v := from; v < to; v++ {
+		out = append(out, v)
+	}
+	return out
+}
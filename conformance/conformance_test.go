@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/counting"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/quake"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/treap"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+func TestPairing(t *testing.T) {
+	Run(t, func() heap.Interface { return pairing.New() })
+}
+
+func TestPairingMultiPass(t *testing.T) {
+	Run(t, func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.MultiPass)) })
+}
+
+func TestPairingLazyInsert(t *testing.T) {
+	Run(t, func() heap.Interface { return pairing.New(pairing.WithLazyInsert()) })
+}
+
+func TestPairingAuxiliary(t *testing.T) {
+	Run(t, func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.Auxiliary)) })
+}
+
+func TestLeftist(t *testing.T) {
+	Run(t, func() heap.Interface { return leftist.New() })
+}
+
+func TestSkew(t *testing.T) {
+	Run(t, func() heap.Interface { return skew.New() })
+}
+
+func TestFibonacci(t *testing.T) {
+	Run(t, func() heap.Interface { return fibonacci.New() })
+}
+
+func TestBinomial(t *testing.T) {
+	Run(t, func() heap.Interface { return &binomial.BinomialHeap{} })
+}
+
+func TestTreap(t *testing.T) {
+	Run(t, func() heap.Interface { return treap.New() })
+}
+
+func TestRankPairing(t *testing.T) {
+	Run(t, func() heap.Interface { return rpheap.New() })
+}
+
+func TestQuake(t *testing.T) {
+	Run(t, func() heap.Interface { return quake.New() })
+}
+
+func TestViolation(t *testing.T) {
+	Run(t, func() heap.Interface { return violation.New() })
+}
+
+func TestCounting(t *testing.T) {
+	Run(t, func() heap.Interface { return counting.New() })
+}
@@ -0,0 +1,116 @@
+package go_heaps
+
+import (
+	"container/heap"
+)
+
+// Items is a slice of Items ordered by Compare. It implements
+// container/heap.Interface, and therefore sort.Interface, so Items from this
+// package can be used with container/heap and sort directly.
+type Items []Item
+
+// Len implements sort.Interface.
+func (s Items) Len() int { return len(s) }
+
+// Less implements sort.Interface.
+func (s Items) Less(i, j int) bool { return s[i].Compare(s[j]) < 0 }
+
+// Swap implements sort.Interface.
+func (s Items) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Push implements container/heap.Interface.
+func (s *Items) Push(x interface{}) { *s = append(*s, x.(Item)) }
+
+// Pop implements container/heap.Interface.
+func (s *Items) Pop() interface{} {
+	old := *s
+	n := len(old) - 1
+	item := old[n]
+	old[n] = nil
+	*s = old[:n]
+	return item
+}
+
+// FromContainer adapts h, a container/heap.Interface holding Items, to the
+// Interface of this package. Items are added and removed with heap.Push and
+// heap.Pop, so h keeps its own invariants and may still be used directly.
+//
+// container/heap gives no access to the minimum without removing it, so
+// FindMin costs a Pop and a Push: O(log n).
+func FromContainer(h heap.Interface) Interface {
+	return &containerHeap{h: h}
+}
+
+type containerHeap struct {
+	h heap.Interface
+}
+
+func (c *containerHeap) Insert(item Item) Item {
+	heap.Push(c.h, item)
+	return item
+}
+
+func (c *containerHeap) DeleteMin() Item {
+	if c.h.Len() == 0 {
+		return nil
+	}
+	return heap.Pop(c.h).(Item)
+}
+
+func (c *containerHeap) FindMin() Item {
+	if c.h.Len() == 0 {
+		return nil
+	}
+	min := heap.Pop(c.h)
+	heap.Push(c.h, min)
+	return min.(Item)
+}
+
+func (c *containerHeap) Clear() {
+	for c.h.Len() > 0 {
+		c.h.Pop()
+	}
+}
+
+// ToContainerHeap returns the items of h as Items in binary heap order, the
+// layout of container/heap, to hand them over to code using container/heap
+// directly. h is left unchanged: heaps implementing Do(ItemIterator) are
+// walked in place, heaps returned by FromContainer over Items are copied,
+// and other heaps are drained and refilled. Clear h afterwards to move the
+// items rather than copy them.
+// The complexity is O(n), plus a drain and a refill of h when it does not
+// implement Do.
+func ToContainerHeap(h Interface) Items {
+	if c, ok := h.(*containerHeap); ok {
+		if s, ok := c.h.(*Items); ok {
+			return append(Items(nil), *s...)
+		}
+	}
+	var items Items
+	if d, ok := h.(interface {
+		Do(it ItemIterator)
+	}); ok {
+		d.Do(func(item Item) bool {
+			items = append(items, item)
+			return true
+		})
+		Heapify(items)
+		return items
+	}
+	for item := h.DeleteMin(); item != nil; item = h.DeleteMin() {
+		items = append(items, item)
+	}
+	PushMany(h, items...)
+	// ascending order is a binary heap order
+	return items
+}
+
+// FromContainerHeap inserts items, a slice used with container/heap, into h
+// and returns h, to take a queue back from code using container/heap. Heaps
+// implementing PushMany, like the pairing heap, insert them in a single
+// batch. items is left as is; FromContainer wraps it instead to share it.
+// The complexity is that of n Insert calls on h.
+func FromContainerHeap(items Items, h Interface) Interface {
+	PushMany(h, items...)
+	return h
+}
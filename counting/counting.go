@@ -0,0 +1,118 @@
+// Package counting implements a multiplicity heap: every distinct item is
+// stored once together with the number of times it was inserted, so
+// inserting highly repetitive keys takes space proportional to the number of
+// distinct keys only.
+//
+// Items are also used as map keys, so they must be comparable with == and
+// two items must compare equal exactly when they are ==, as is the case for
+// go_heaps.Integer and go_heaps.String.
+//
+// Structure is not thread safe.
+package counting
+
+import (
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// Heap is a min heap storing distinct items with their multiplicity.
+// The zero value for Heap is an empty heap ready to use.
+type Heap struct {
+	distinct *pairing.PairHeap
+	counts   map[heap.Item]int
+	size     int
+}
+
+// Heap implements the Interface interface
+var _ heap.Interface = (*Heap)(nil)
+
+// Init initializes or clears the Heap
+func (h *Heap) Init() *Heap {
+	h.distinct = pairing.New()
+	h.counts = make(map[heap.Item]int)
+	h.size = 0
+	return h
+}
+
+// New returns an initialized Heap.
+func New() *Heap { return new(Heap).Init() }
+
+// Insert adds one occurrence of item and returns it.
+// The complexity is O(1).
+func (h *Heap) Insert(item heap.Item) heap.Item {
+	h.Add(item, 1)
+	return item
+}
+
+// Add adds n occurrences of item.
+// The complexity is O(1).
+func (h *Heap) Add(item heap.Item, n int) {
+	if n <= 0 {
+		return
+	}
+	if h.distinct == nil {
+		h.Init()
+	}
+	if h.counts[item] == 0 {
+		h.distinct.Insert(item)
+	}
+	h.counts[item] += n
+	h.size += n
+}
+
+// DeleteMin removes one occurrence of the minimum item and returns it.
+// The distinct item is only removed from the heap with its last occurrence.
+// The complexity is O(1), or O(log n) amortized for the last occurrence.
+func (h *Heap) DeleteMin() heap.Item {
+	min := h.FindMin()
+	if min == nil {
+		return nil
+	}
+	h.size--
+	if h.counts[min]--; h.counts[min] == 0 {
+		delete(h.counts, min)
+		h.distinct.DeleteMin()
+	}
+	return min
+}
+
+// FindMin returns the minimum item.
+// The complexity is O(1).
+func (h *Heap) FindMin() heap.Item {
+	if h.distinct == nil {
+		return nil
+	}
+	return h.distinct.FindMin()
+}
+
+// Clear removes all items.
+func (h *Heap) Clear() {
+	h.Init()
+}
+
+// Count returns the number of occurrences of item.
+// The complexity is O(1).
+func (h *Heap) Count(item heap.Item) int {
+	return h.counts[item]
+}
+
+// Multiplicity returns the number of occurrences of the minimum item, that is
+// how many DeleteMin calls return it.
+// The complexity is O(1).
+func (h *Heap) Multiplicity() int {
+	min := h.FindMin()
+	if min == nil {
+		return 0
+	}
+	return h.counts[min]
+}
+
+// Size returns the total number of occurrences held.
+func (h *Heap) Size() int {
+	return h.size
+}
+
+// Distinct returns the number of distinct items held.
+func (h *Heap) Distinct() int {
+	return len(h.counts)
+}
@@ -0,0 +1,75 @@
+package counting
+
+import (
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestCountingHeap(t *testing.T) {
+	h := New()
+
+	for i := 0; i < 100000; i++ {
+		h.Insert(heap.Integer(i % 3))
+	}
+	h.Add(heap.Integer(-1), 2)
+	h.Add(heap.Integer(7), 0)
+
+	if h.Size() != 100002 || h.Distinct() != 4 {
+		t.Fatalf("unexpected size %d and distinct %d", h.Size(), h.Distinct())
+	}
+	if h.Count(heap.Integer(0)) != 33334 || h.Count(heap.Integer(7)) != 0 {
+		t.Fail()
+	}
+	if h.FindMin() != heap.Integer(-1) || h.Multiplicity() != 2 {
+		t.Fail()
+	}
+
+	for i := 0; i < 2; i++ {
+		if h.DeleteMin() != heap.Integer(-1) {
+			t.Fail()
+		}
+	}
+	if h.FindMin() != heap.Integer(0) || h.Multiplicity() != 33334 {
+		t.Fail()
+	}
+
+	for i := 0; i < 33334; i++ {
+		h.DeleteMin()
+	}
+	if h.FindMin() != heap.Integer(1) || h.Distinct() != 2 {
+		t.Fail()
+	}
+
+	h.Clear()
+	if h.DeleteMin() != nil || h.Multiplicity() != 0 || h.Size() != 0 {
+		t.Fail()
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var h Heap
+	if h.FindMin() != nil || h.DeleteMin() != nil || h.Multiplicity() != 0 {
+		t.Fatal("empty zero value returned an item")
+	}
+	if h.Size() != 0 || h.Distinct() != 0 || h.Count(heap.Integer(1)) != 0 {
+		t.Fatal("empty zero value reported items")
+	}
+
+	h.Insert(heap.Integer(2))
+	h.Add(heap.Integer(1), 2)
+	if h.Size() != 3 || h.Distinct() != 2 || h.Multiplicity() != 2 {
+		t.Fatalf("unexpected size %d and distinct %d", h.Size(), h.Distinct())
+	}
+	for _, want := range []heap.Item{heap.Integer(1), heap.Integer(1), heap.Integer(2), nil} {
+		if item := h.DeleteMin(); item != want {
+			t.Fatalf("DeleteMin returned %v, want %v", item, want)
+		}
+	}
+
+	var cleared Heap
+	cleared.Clear()
+	if cleared.FindMin() != nil || cleared.Size() != 0 {
+		t.Fatal("Clear on the zero value left items")
+	}
+}
@@ -0,0 +1,192 @@
+// Package deadline implements a priority queue specialized on time.Time keys.
+//
+// Entries are kept in an array-backed binary heap and compared with
+// time.Time.Before directly, avoiding the Item interface indirection of the
+// general purpose heaps. An optional timer channel fires whenever the earliest
+// deadline is reached.
+//
+// Structure is not thread safe.
+package deadline
+
+import (
+	"time"
+)
+
+// Entry is a payload scheduled at a deadline.
+type Entry struct {
+	Deadline time.Time
+	Value    interface{}
+}
+
+// Heap is a min heap of entries ordered by deadline.
+// The zero value for Heap is an empty heap ready to use.
+type Heap struct {
+	entries []Entry
+	timer   *time.Timer
+	shrink  ShrinkPolicy
+}
+
+// Option configures a Heap created by New.
+type Option func(*Heap)
+
+// WithShrinkPolicy sets when the heap releases the capacity left unused
+// after removals. The default is ShrinkNever.
+func WithShrinkPolicy(policy ShrinkPolicy) Option {
+	return func(h *Heap) {
+		h.shrink = policy
+	}
+}
+
+// Init initializes or clears the Heap
+func (h *Heap) Init() *Heap {
+	h.entries = nil
+	h.rearm()
+	return h
+}
+
+// New returns an initialized Heap configured with opts.
+func New(opts ...Option) *Heap {
+	h := new(Heap)
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h.Init()
+}
+
+// Len returns the number of entries in the heap.
+func (h *Heap) Len() int {
+	return len(h.entries)
+}
+
+// IsEmpty returns true if the heap has no entries.
+func (h *Heap) IsEmpty() bool {
+	return len(h.entries) == 0
+}
+
+// Clear removes all entries from the heap.
+func (h *Heap) Clear() {
+	h.Init()
+}
+
+// Push schedules value at deadline.
+// The complexity is O(log n).
+func (h *Heap) Push(deadline time.Time, value interface{}) {
+	h.entries = append(h.entries, Entry{Deadline: deadline, Value: value})
+	h.up(len(h.entries) - 1)
+	if len(h.entries) == 1 || h.entries[0].Deadline.Equal(deadline) {
+		h.rearm()
+	}
+}
+
+// Peek returns the entry with the earliest deadline without removing it.
+// The complexity is O(1).
+func (h *Heap) Peek() (Entry, bool) {
+	if h.IsEmpty() {
+		return Entry{}, false
+	}
+	return h.entries[0], true
+}
+
+// NextDeadline returns the earliest deadline in the heap.
+// The complexity is O(1).
+func (h *Heap) NextDeadline() (time.Time, bool) {
+	if h.IsEmpty() {
+		return time.Time{}, false
+	}
+	return h.entries[0].Deadline, true
+}
+
+// Pop removes and returns the entry with the earliest deadline.
+// The complexity is O(log n).
+func (h *Heap) Pop() (Entry, bool) {
+	if h.IsEmpty() {
+		return Entry{}, false
+	}
+	e := h.pop()
+	h.maybeShrink()
+	h.rearm()
+	return e, true
+}
+
+// PopExpired removes and returns, in deadline order, every entry whose
+// deadline is not after now.
+// The complexity is O(k log n) for k expired entries.
+func (h *Heap) PopExpired(now time.Time) []Entry {
+	var expired []Entry
+	for len(h.entries) > 0 && !h.entries[0].Deadline.After(now) {
+		expired = append(expired, h.pop())
+	}
+	if len(expired) > 0 {
+		h.maybeShrink()
+		h.rearm()
+	}
+	return expired
+}
+
+// C returns a channel that receives the current time once the earliest
+// deadline is reached. The underlying timer is re-armed by the heap whenever
+// the earliest deadline changes, and stays idle while the heap is empty.
+// Receiving from C does not remove anything; call PopExpired to collect the
+// due entries.
+func (h *Heap) C() <-chan time.Time {
+	if h.timer == nil {
+		h.timer = time.NewTimer(time.Hour)
+		h.rearm()
+	}
+	return h.timer.C
+}
+
+// rearm points the timer, if any, at the earliest deadline.
+func (h *Heap) rearm() {
+	if h.timer == nil {
+		return
+	}
+	if !h.timer.Stop() {
+		select {
+		case <-h.timer.C:
+		default:
+		}
+	}
+	if next, ok := h.NextDeadline(); ok {
+		h.timer.Reset(time.Until(next))
+	}
+}
+
+func (h *Heap) pop() Entry {
+	n := len(h.entries) - 1
+	e := h.entries[0]
+	h.entries[0] = h.entries[n]
+	h.entries[n] = Entry{} // release the payload
+	h.entries = h.entries[:n]
+	h.down(0)
+	return e
+}
+
+func (h *Heap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.entries[i].Deadline.Before(h.entries[parent].Deadline) {
+			break
+		}
+		h.entries[i], h.entries[parent] = h.entries[parent], h.entries[i]
+		i = parent
+	}
+}
+
+func (h *Heap) down(i int) {
+	n := len(h.entries)
+	for {
+		min := i
+		if l := 2*i + 1; l < n && h.entries[l].Deadline.Before(h.entries[min].Deadline) {
+			min = l
+		}
+		if r := 2*i + 2; r < n && h.entries[r].Deadline.Before(h.entries[min].Deadline) {
+			min = r
+		}
+		if min == i {
+			return
+		}
+		h.entries[i], h.entries[min] = h.entries[min], h.entries[i]
+		i = min
+	}
+}
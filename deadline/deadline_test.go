@@ -0,0 +1,180 @@
+package deadline
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(seconds int) time.Time {
+	return epoch.Add(time.Duration(seconds) * time.Second)
+}
+
+func TestDeadlineHeapOrder(t *testing.T) {
+	h := New()
+
+	for _, s := range rand.Perm(100) {
+		h.Push(at(s), s)
+	}
+
+	if h.Len() != 100 {
+		t.Fatalf("expected 100 entries, got %d", h.Len())
+	}
+
+	for i := 0; i < 100; i++ {
+		e, ok := h.Pop()
+		if !ok || e.Value != i || !e.Deadline.Equal(at(i)) {
+			t.Fatalf("expected entry %d, got %v", i, e)
+		}
+	}
+
+	if _, ok := h.Pop(); ok {
+		t.Fail()
+	}
+}
+
+func TestDeadlineHeapNextDeadline(t *testing.T) {
+	h := &Heap{}
+
+	if _, ok := h.NextDeadline(); ok {
+		t.Fail()
+	}
+
+	h.Push(at(5), "b")
+	h.Push(at(2), "a")
+	h.Push(at(9), "c")
+
+	next, ok := h.NextDeadline()
+	if !ok || !next.Equal(at(2)) {
+		t.Fail()
+	}
+	if e, _ := h.Peek(); e.Value != "a" {
+		t.Fail()
+	}
+
+	h.Clear()
+	if !h.IsEmpty() {
+		t.Fail()
+	}
+}
+
+func TestDeadlineHeapPopExpired(t *testing.T) {
+	h := New()
+
+	for _, s := range []int{7, 1, 4, 3, 9, 4} {
+		h.Push(at(s), s)
+	}
+
+	expired := h.PopExpired(at(4))
+	want := []int{1, 3, 4, 4}
+	if len(expired) != len(want) {
+		t.Fatalf("expected %d expired entries, got %d", len(want), len(expired))
+	}
+	for i, e := range expired {
+		if e.Value != want[i] {
+			t.Fatalf("expected %d at %d, got %v", want[i], i, e.Value)
+		}
+	}
+
+	if len(h.PopExpired(at(0))) != 0 {
+		t.Fail()
+	}
+	if h.Len() != 2 {
+		t.Fail()
+	}
+}
+
+func TestDeadlineHeapTimer(t *testing.T) {
+	h := New()
+	c := h.C()
+
+	select {
+	case <-c:
+		t.Fatal("timer fired on an empty heap")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	now := time.Now()
+	h.Push(now.Add(time.Hour), "late")
+	h.Push(now.Add(5*time.Millisecond), "soon")
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire at the next deadline")
+	}
+
+	expired := h.PopExpired(time.Now())
+	if len(expired) != 1 || expired[0].Value != "soon" {
+		t.Fatalf("unexpected expired entries %v", expired)
+	}
+
+	select {
+	case <-c:
+		t.Fatal("timer fired before the remaining deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestDeadlineHeapShrink(t *testing.T) {
+	fill := func(h *Heap, n int) {
+		for i := 0; i < n; i++ {
+			h.Push(at(i), i)
+		}
+	}
+
+	h := New()
+	fill(h, 1000)
+	peak := h.Cap()
+	h.PopExpired(at(989))
+	if h.Cap() != peak {
+		t.Fatalf("expected the capacity to stay at %d, got %d", peak, h.Cap())
+	}
+	h.Shrink()
+	if h.Cap() != 10 || h.Len() != 10 {
+		t.Fatalf("expected 10 entries in a capacity of 10, got %d of %d", h.Len(), h.Cap())
+	}
+	if e, _ := h.Pop(); e.Value != 990 {
+		t.Fatalf("unexpected entry %v", e)
+	}
+
+	h = New(WithShrinkPolicy(ShrinkAuto))
+	fill(h, 1000)
+	for h.Len() > 100 {
+		h.Pop()
+		if h.Len() > 0 && float64(h.Len()) < 0.25*float64(h.Cap()) {
+			t.Fatalf("%d entries left in a capacity of %d", h.Len(), h.Cap())
+		}
+	}
+	if h.Cap() >= peak {
+		t.Fatalf("expected the capacity to shrink below %d", peak)
+	}
+	for i := 900; i < 1000; i++ {
+		if e, _ := h.Pop(); e.Value != i {
+			t.Fatalf("expected entry %d, got %v", i, e)
+		}
+	}
+	if h.Cap() != minShrinkCap {
+		t.Fatalf("expected the capacity to stop at %d, got %d", minShrinkCap, h.Cap())
+	}
+
+	h = New(WithShrinkPolicy(ShrinkThreshold(0.5)))
+	fill(h, 1000)
+	h.PopExpired(at(600))
+	if h.Cap() != 2*h.Len() {
+		t.Fatalf("expected a capacity of %d, got %d", 2*h.Len(), h.Cap())
+	}
+
+	for _, ratio := range []float64{0, 0.6} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for threshold %v", ratio)
+				}
+			}()
+			ShrinkThreshold(ratio)
+		}()
+	}
+}
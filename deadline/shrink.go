@@ -0,0 +1,75 @@
+package deadline
+
+import (
+	"fmt"
+)
+
+// minShrinkCap is the capacity below which the heap is never shrunk, as
+// reallocating would cost more than the memory it releases.
+const minShrinkCap = 64
+
+// ShrinkPolicy decides when Pop and PopExpired release the capacity of the
+// entry array left unused after a large drain, returning it to the runtime.
+type ShrinkPolicy struct {
+	ratio float64 // shrink when fewer than ratio*cap entries are left
+}
+
+var (
+	// ShrinkNever keeps the capacity of the largest size reached, which
+	// avoids reallocating when the heap refills to a similar size.
+	ShrinkNever = ShrinkPolicy{}
+	// ShrinkAuto reallocates the entry array to twice the number of entries,
+	// but no fewer than 64, once they use less than a quarter of its
+	// capacity, keeping the amortized cost of Push and Pop O(log n).
+	ShrinkAuto = ShrinkThreshold(0.25)
+)
+
+// ShrinkThreshold returns a policy that reallocates the entry array to
+// twice the number of entries once they use less than ratio of its
+// capacity. ratio must be in (0, 0.5], so that a shrunk array is not
+// immediately full again.
+func ShrinkThreshold(ratio float64) ShrinkPolicy {
+	if !(ratio > 0 && ratio <= 0.5) {
+		panic(fmt.Sprintf("deadline: shrink threshold %v out of range (0, 0.5]", ratio))
+	}
+	return ShrinkPolicy{ratio: ratio}
+}
+
+// Cap returns the number of entries the heap can hold without reallocating.
+func (h *Heap) Cap() int {
+	return cap(h.entries)
+}
+
+// Shrink reallocates the entry array to fit the entries held, whatever the
+// shrink policy.
+// The complexity is O(n).
+func (h *Heap) Shrink() {
+	h.resize(len(h.entries))
+}
+
+// maybeShrink applies the shrink policy after a removal.
+func (h *Heap) maybeShrink() {
+	c := cap(h.entries)
+	if h.shrink.ratio == 0 || c <= minShrinkCap ||
+		float64(len(h.entries)) >= h.shrink.ratio*float64(c) {
+		return
+	}
+	n := 2 * len(h.entries)
+	if n < minShrinkCap {
+		n = minShrinkCap
+	}
+	h.resize(n)
+}
+
+func (h *Heap) resize(capacity int) {
+	if capacity == cap(h.entries) {
+		return
+	}
+	if capacity == 0 {
+		h.entries = nil
+		return
+	}
+	entries := make([]Entry, len(h.entries), capacity)
+	copy(entries, h.entries)
+	h.entries = entries
+}
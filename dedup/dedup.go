@@ -0,0 +1,117 @@
+// Package dedup wraps a heap with set semantics: an item that compares equal
+// to one already held is ignored, replaces it, or is merged into it.
+//
+// Duplicates are found with the heap's Find, so Insert costs as much as a
+// lookup in the wrapped heap (O(n) for the pairing heap).
+//
+// Structure is not thread safe.
+package dedup
+
+import (
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Finder is a heap able to look up and update arbitrary items, such as the
+// pairing heap.
+type Finder interface {
+	heap.Extended
+	Find(item heap.Item) heap.Item
+}
+
+// Policy decides what happens when a duplicate is inserted.
+type Policy int
+
+const (
+	// Ignore keeps the item already in the heap.
+	Ignore Policy = iota
+	// Replace swaps the item already in the heap for the new one.
+	Replace
+	// Merge combines both items with a user supplied function.
+	Merge
+)
+
+// MergeFunc combines the item already in the heap with a duplicate being
+// inserted and returns the item to keep.
+type MergeFunc func(old, new heap.Item) heap.Item
+
+// Heap is a heap that never holds two items comparing equal.
+type Heap struct {
+	h      Finder
+	policy Policy
+	merge  MergeFunc
+}
+
+// Heap implements the Interface interface
+var _ heap.Interface = (*Heap)(nil)
+
+// New wraps h, resolving duplicates with policy. Use NewMerging for the
+// Merge policy.
+func New(h Finder, policy Policy) *Heap {
+	if policy == Merge {
+		panic("dedup: the Merge policy requires NewMerging")
+	}
+	return &Heap{h: h, policy: policy}
+}
+
+// NewMerging wraps h, resolving duplicates with merge.
+func NewMerging(h Finder, merge MergeFunc) *Heap {
+	return &Heap{h: h, policy: Merge, merge: merge}
+}
+
+// Insert adds item unless an equal item is present, in which case the policy
+// applies. It returns the item held by the heap afterwards.
+func (d *Heap) Insert(item heap.Item) heap.Item {
+	old := d.h.Find(item)
+	if old == nil {
+		return d.h.Insert(item)
+	}
+
+	switch d.policy {
+	case Ignore:
+		return old
+	case Replace:
+		d.h.Adjust(old, item)
+		return item
+	case Merge:
+		// the merged item may no longer compare equal to old, so it is
+		// inserted again to keep the set property
+		d.h.Delete(old)
+		return d.Insert(d.merge(old, item))
+	default:
+		panic("dedup: invalid policy")
+	}
+}
+
+// Contains reports whether an item equal to item is held.
+func (d *Heap) Contains(item heap.Item) bool {
+	return d.h.Find(item) != nil
+}
+
+// DeleteMin deletes the minimum value and returns it.
+func (d *Heap) DeleteMin() heap.Item {
+	return d.h.DeleteMin()
+}
+
+// FindMin returns the minimum value.
+func (d *Heap) FindMin() heap.Item {
+	return d.h.FindMin()
+}
+
+// Delete removes the item equal to item and returns it.
+func (d *Heap) Delete(item heap.Item) heap.Item {
+	return d.h.Delete(item)
+}
+
+// Adjust replaces old with new. If new equals another item already held,
+// the policy applies as for Insert.
+func (d *Heap) Adjust(old, new heap.Item) heap.Item {
+	if d.h.Delete(old) == nil {
+		return nil
+	}
+	return d.Insert(new)
+}
+
+// Clear removes all items.
+func (d *Heap) Clear() {
+	d.h.Clear()
+}
@@ -0,0 +1,82 @@
+package dedup
+
+import (
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+func drain(d *Heap) (out []heap.Item) {
+	for v := d.DeleteMin(); v != nil; v = d.DeleteMin() {
+		out = append(out, v)
+	}
+	return
+}
+
+func TestIgnore(t *testing.T) {
+	d := New(pairing.New(), Ignore)
+
+	d.Insert(heap.KV(heap.Integer(2), "first"))
+	d.Insert(heap.KV(heap.Integer(1), "one"))
+	if got := d.Insert(heap.KV(heap.Integer(2), "second")); got.(heap.Pair).Value != "first" {
+		t.Errorf("expected the existing item, got %v", got)
+	}
+
+	items := drain(d)
+	if len(items) != 2 || items[1].(heap.Pair).Value != "first" {
+		t.Errorf("unexpected items %v", items)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	d := New(pairing.New(), Replace)
+
+	for _, v := range []int{3, 1, 2} {
+		d.Insert(heap.KV(heap.Integer(v), "old"))
+	}
+	d.Insert(heap.KV(heap.Integer(2), "new"))
+
+	if !d.Contains(heap.KV(heap.Integer(2), nil)) {
+		t.Fail()
+	}
+
+	items := drain(d)
+	if len(items) != 3 || items[1].(heap.Pair).Value != "new" {
+		t.Errorf("unexpected items %v", items)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	sum := func(old, new heap.Item) heap.Item {
+		a, b := old.(heap.Pair), new.(heap.Pair)
+		return heap.KV(a.Key, a.Value.(int)+b.Value.(int))
+	}
+	d := NewMerging(pairing.New(), sum)
+
+	for i := 0; i < 10; i++ {
+		d.Insert(heap.KV(heap.Integer(i%3), 1))
+	}
+
+	items := drain(d)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %v", items)
+	}
+	for i, count := range []int{4, 3, 3} {
+		if items[i].(heap.Pair).Value != count {
+			t.Errorf("expected count %d for key %d, got %v", count, i, items[i])
+		}
+	}
+}
+
+func TestAdjustCollision(t *testing.T) {
+	d := New(pairing.New(), Ignore)
+
+	d.Insert(heap.Integer(1))
+	d.Insert(heap.Integer(5))
+	d.Adjust(heap.Integer(5), heap.Integer(1))
+
+	if items := drain(d); len(items) != 1 {
+		t.Errorf("expected a single item, got %v", items)
+	}
+}
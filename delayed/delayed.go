@@ -0,0 +1,108 @@
+// Package delayed adds scheduled insertion to a heap: items inserted with
+// InsertAt stay invisible until their activation time, like the delayed
+// jobs of a job queue.
+//
+// Delayed items wait in a secondary deadline heap ordered by activation
+// time. Every operation that reads the heap first moves the items whose
+// time has come into the wrapped heap, where they compete on their own
+// priority with the items already visible.
+//
+// Structure is not thread safe.
+package delayed
+
+import (
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/deadline"
+)
+
+// Heap wraps a heap so that items can be inserted ahead of time.
+type Heap struct {
+	h       heap.Interface
+	pending *deadline.Heap
+	now     func() time.Time
+}
+
+// Heap implements the Interface interface
+var _ heap.Interface = (*Heap)(nil)
+
+// New wraps h, which holds the visible items.
+func New(h heap.Interface) *Heap {
+	return &Heap{
+		h:       h,
+		pending: deadline.New(),
+		now:     time.Now,
+	}
+}
+
+// Insert adds an item into the underlying heap, visible right away.
+func (d *Heap) Insert(item heap.Item) heap.Item {
+	return d.h.Insert(item)
+}
+
+// InsertAt adds item to the heap, invisible until visibleAfter. Items whose
+// activation time has already passed are inserted right away.
+// The complexity is O(log p) for p pending items.
+func (d *Heap) InsertAt(item heap.Item, visibleAfter time.Time) heap.Item {
+	if !visibleAfter.After(d.now()) {
+		return d.h.Insert(item)
+	}
+	d.pending.Push(visibleAfter, item)
+	return item
+}
+
+// InsertAfter adds item to the heap, invisible for delay.
+func (d *Heap) InsertAfter(item heap.Item, delay time.Duration) heap.Item {
+	return d.InsertAt(item, d.now().Add(delay))
+}
+
+// FindMin returns the minimum visible item.
+func (d *Heap) FindMin() heap.Item {
+	d.Promote()
+	return d.h.FindMin()
+}
+
+// DeleteMin removes and returns the minimum visible item.
+func (d *Heap) DeleteMin() heap.Item {
+	d.Promote()
+	return d.h.DeleteMin()
+}
+
+// Clear removes all items, visible or pending.
+func (d *Heap) Clear() {
+	d.h.Clear()
+	d.pending.Clear()
+}
+
+// Pending returns the number of items not visible yet.
+func (d *Heap) Pending() int {
+	return d.pending.Len()
+}
+
+// NextActivation returns the time at which the next pending item becomes
+// visible. ok is false if no item is pending.
+func (d *Heap) NextActivation() (t time.Time, ok bool) {
+	return d.pending.NextDeadline()
+}
+
+// C returns a channel that receives the current time once the next pending
+// item becomes visible, to wake up consumers waiting on an empty heap.
+func (d *Heap) C() <-chan time.Time {
+	return d.pending.C()
+}
+
+// Promote moves the pending items whose activation time has passed into
+// the underlying heap and returns how many there were. It is called by
+// FindMin and DeleteMin.
+// The complexity is O(k log p) for k promoted items.
+func (d *Heap) Promote() int {
+	if d.pending.IsEmpty() {
+		return 0
+	}
+	due := d.pending.PopExpired(d.now())
+	for _, e := range due {
+		d.h.Insert(e.Value.(heap.Item))
+	}
+	return len(due)
+}
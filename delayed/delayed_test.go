@@ -0,0 +1,87 @@
+package delayed
+
+import (
+	"testing"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newDelayed() (*Heap, *clock) {
+	c := &clock{t: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}
+	d := New(pairing.New())
+	d.now = c.now
+	return d, c
+}
+
+func TestInsertAt(t *testing.T) {
+	d, c := newDelayed()
+	d.Insert(heap.Integer(5))
+	d.InsertAt(heap.Integer(1), c.t.Add(time.Second))
+	d.InsertAfter(heap.Integer(3), 2*time.Second)
+	d.InsertAt(heap.Integer(4), c.t.Add(-time.Second))
+	if d.Pending() != 2 {
+		t.Fatalf("expected 2 pending items, got %d", d.Pending())
+	}
+	if next, ok := d.NextActivation(); !ok || !next.Equal(c.t.Add(time.Second)) {
+		t.Fatalf("unexpected next activation %v", next)
+	}
+
+	if min := d.FindMin(); min != heap.Integer(4) {
+		t.Fatalf("expected 4 before any activation, got %v", min)
+	}
+
+	c.t = c.t.Add(time.Second)
+	for _, want := range []int{1, 4, 5} {
+		if got := d.DeleteMin(); got != heap.Integer(want) {
+			t.Fatalf("expected %d, got %v", want, got)
+		}
+	}
+	if d.DeleteMin() != nil || d.Pending() != 1 {
+		t.Fatal("expected 3 to be still pending")
+	}
+
+	c.t = c.t.Add(time.Second)
+	if d.DeleteMin() != heap.Integer(3) || d.Pending() != 0 {
+		t.Fatal("expected 3 once visible")
+	}
+	if _, ok := d.NextActivation(); ok {
+		t.Fatal("expected no pending item")
+	}
+}
+
+func TestClear(t *testing.T) {
+	d, c := newDelayed()
+	d.Insert(heap.Integer(1))
+	d.InsertAt(heap.Integer(2), c.t.Add(time.Minute))
+	d.Clear()
+	c.t = c.t.Add(time.Hour)
+	if d.FindMin() != nil || d.Pending() != 0 {
+		t.Fatal("expected an empty heap")
+	}
+}
+
+func TestC(t *testing.T) {
+	d := New(pairing.New())
+	d.InsertAfter(heap.Integer(1), 10*time.Millisecond)
+	if d.FindMin() != nil {
+		t.Fatal("expected the item to be invisible")
+	}
+	select {
+	case <-d.C():
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+	if d.DeleteMin() != heap.Integer(1) {
+		t.Fatal("expected the item once the timer fired")
+	}
+}
@@ -0,0 +1,140 @@
+// Package edf implements an earliest-deadline-first executor.
+//
+// Submitted tasks wait in a deadline heap and a pool of workers always picks
+// the task with the earliest deadline next, blocking while there is none.
+// Tasks that finish after their deadline are reported to an optional miss
+// callback.
+package edf
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/theodesp/go-heaps/deadline"
+)
+
+// ErrClosed is returned by Submit after Close.
+var ErrClosed = errors.New("edf: executor closed")
+
+// MissFunc is called after a task finished, or was skipped, past its
+// deadline, with the deadline and the time it was missed by.
+type MissFunc func(deadline time.Time, late time.Duration)
+
+// Option configures an Executor created by New.
+type Option func(*Executor)
+
+// OnMiss registers fn to be called for every missed deadline. It runs on the
+// worker that ran the task.
+func OnMiss(fn MissFunc) Option {
+	return func(e *Executor) {
+		e.onMiss = fn
+	}
+}
+
+// SkipMissed drops the tasks whose deadline has already passed when a worker
+// picks them up instead of running them late. They are still reported to the
+// miss callback.
+func SkipMissed() Option {
+	return func(e *Executor) {
+		e.skipMissed = true
+	}
+}
+
+// Executor runs tasks on a pool of workers in earliest-deadline-first order.
+// It is safe for concurrent use.
+type Executor struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	queue  *deadline.Heap
+	closed bool
+	wg     sync.WaitGroup
+
+	onMiss     MissFunc
+	skipMissed bool
+	now        func() time.Time
+}
+
+// New starts an Executor with the given number of workers, at least one.
+func New(workers int, opts ...Option) *Executor {
+	e := &Executor{queue: deadline.New(), now: time.Now}
+	e.ready = sync.NewCond(&e.mu)
+	for _, opt := range opts {
+		opt(e)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+// Submit schedules task to run before deadline.
+// The complexity is O(log n) for n pending tasks.
+func (e *Executor) Submit(task func(), deadline time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return ErrClosed
+	}
+	e.queue.Push(deadline, task)
+	e.ready.Signal()
+	return nil
+}
+
+// Pending returns the number of tasks waiting for a worker.
+func (e *Executor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.queue.Len()
+}
+
+// Close stops accepting tasks, lets the workers finish the pending ones and
+// waits for them to exit.
+func (e *Executor) Close() {
+	e.mu.Lock()
+	e.closed = true
+	e.ready.Broadcast()
+	e.mu.Unlock()
+	e.wg.Wait()
+}
+
+func (e *Executor) work() {
+	defer e.wg.Done()
+	for {
+		entry, ok := e.next()
+		if !ok {
+			return
+		}
+		if e.skipMissed {
+			if now := e.now(); now.After(entry.Deadline) {
+				e.miss(entry.Deadline, now)
+				continue
+			}
+		}
+		entry.Value.(func())()
+		if now := e.now(); now.After(entry.Deadline) {
+			e.miss(entry.Deadline, now)
+		}
+	}
+}
+
+// next blocks until a task is pending and pops it. It returns false once the
+// executor is closed and drained.
+func (e *Executor) next() (deadline.Entry, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for e.queue.IsEmpty() && !e.closed {
+		e.ready.Wait()
+	}
+	return e.queue.Pop()
+}
+
+func (e *Executor) miss(deadline, now time.Time) {
+	if e.onMiss != nil {
+		e.onMiss(deadline, now.Sub(deadline))
+	}
+}
@@ -0,0 +1,106 @@
+package edf
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEarliestDeadlineFirst(t *testing.T) {
+	e := New(1)
+	gate := make(chan struct{})
+	e.Submit(func() { <-gate }, time.Now().Add(time.Hour))
+	for e.Pending() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var order []int
+	base := time.Now().Add(time.Hour)
+	for _, i := range []int{3, 1, 4, 0, 2} {
+		i := i
+		e.Submit(func() { order = append(order, i) }, base.Add(time.Duration(i)*time.Second))
+	}
+	close(gate)
+	e.Close()
+
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("expected tasks in deadline order, got %v", order)
+		}
+	}
+	if len(order) != 5 {
+		t.Fatalf("expected 5 tasks to run, got %d", len(order))
+	}
+	if err := e.Submit(func() {}, base); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestMissedDeadlines(t *testing.T) {
+	var mu sync.Mutex
+	var missed []time.Time
+	onMiss := OnMiss(func(deadline time.Time, late time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if late <= 0 {
+			t.Errorf("expected a positive delay, got %v", late)
+		}
+		missed = append(missed, deadline)
+	})
+
+	for _, skip := range []bool{false, true} {
+		missed = nil
+		opts := []Option{onMiss}
+		if skip {
+			opts = append(opts, SkipMissed())
+		}
+		e := New(4, opts...)
+		ran := make(chan int, 10)
+		past := time.Now().Add(-time.Second)
+		future := time.Now().Add(time.Hour)
+		e.Submit(func() { ran <- 1 }, past)
+		e.Submit(func() { ran <- 2 }, future)
+		e.Close()
+		close(ran)
+
+		count := 0
+		for range ran {
+			count++
+		}
+		want := 2
+		if skip {
+			want = 1
+		}
+		if count != want {
+			t.Fatalf("skip %v: expected %d tasks to run, got %d", skip, want, count)
+		}
+		if len(missed) != 1 || !missed[0].Equal(past) {
+			t.Fatalf("skip %v: expected one missed deadline, got %v", skip, missed)
+		}
+	}
+}
+
+func TestConcurrentSubmit(t *testing.T) {
+	e := New(8)
+	var mu sync.Mutex
+	count := 0
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				e.Submit(func() {
+					mu.Lock()
+					count++
+					mu.Unlock()
+				}, time.Now().Add(time.Duration(i)*time.Millisecond))
+			}
+		}()
+	}
+	wg.Wait()
+	e.Close()
+	if count != 1000 {
+		t.Fatalf("expected 1000 tasks to run, got %d", count)
+	}
+}
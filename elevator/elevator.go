@@ -0,0 +1,149 @@
+// Package elevator implements a LOOK (elevator) scheduling queue.
+//
+// Requests are keyed by an offset, such as a disk offset. The queue sweeps in
+// one direction, serving requests in offset order, and reverses only when
+// there is nothing left ahead of it. Requests ahead of the current position
+// wait in a min heap and requests behind it in a max heap, both pairing
+// heaps, so each step is O(log n) amortized.
+//
+// Structure is not thread safe.
+package elevator
+
+import (
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// Direction is the direction of a sweep.
+type Direction int
+
+const (
+	// Up serves increasing offsets.
+	Up Direction = iota
+	// Down serves decreasing offsets.
+	Down
+)
+
+func (d Direction) String() string {
+	if d == Down {
+		return "Down"
+	}
+	return "Up"
+}
+
+type request struct {
+	offset int64
+	seq    uint64 // requests at the same offset are served first-in first-out
+	desc   bool   // order by decreasing offset
+	op     interface{}
+}
+
+func (r request) Compare(than heap.Item) int {
+	o := than.(request)
+	switch {
+	case r.offset < o.offset:
+		return r.sign(-1)
+	case r.offset > o.offset:
+		return r.sign(1)
+	case r.seq < o.seq:
+		return -1
+	case r.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+func (r request) sign(cmp int) int {
+	if r.desc {
+		return -cmp
+	}
+	return cmp
+}
+
+// Queue is a LOOK scheduling queue.
+type Queue struct {
+	ascending  *pairing.PairHeap // requests at or above the position
+	descending *pairing.PairHeap // requests below the position
+	pos        int64
+	dir        Direction
+	seq        uint64
+	len        int
+}
+
+// New returns an empty Queue positioned at start and moving Up.
+func New(start int64) *Queue {
+	return &Queue{
+		ascending:  pairing.New(),
+		descending: pairing.New(),
+		pos:        start,
+	}
+}
+
+// Add queues op at offset.
+// The complexity is O(1).
+func (q *Queue) Add(offset int64, op interface{}) {
+	q.seq++
+	r := request{offset: offset, seq: q.seq, op: op}
+	// requests at the position go to the current sweep
+	if offset > q.pos || offset == q.pos && q.dir == Up {
+		q.ascending.Insert(r)
+	} else {
+		r.desc = true
+		q.descending.Insert(r)
+	}
+	q.len++
+}
+
+// NextInDirection removes and returns the next request of the sweep: the
+// closest one ahead in the current direction or, when there is none, the
+// closest one after reversing. ok is false if the queue is empty.
+// The complexity is O(log n) amortized.
+func (q *Queue) NextInDirection() (offset int64, op interface{}, ok bool) {
+	if q.len == 0 {
+		return 0, nil, false
+	}
+	ahead, behind := q.ascending, q.descending
+	if q.dir == Down {
+		ahead, behind = behind, ahead
+	}
+	if ahead.IsEmpty() {
+		q.dir = 1 - q.dir
+		ahead = behind
+	}
+	r := ahead.DeleteMin().(request)
+	q.pos = r.offset
+	q.len--
+	return r.offset, r.op, true
+}
+
+// Peek returns the request NextInDirection would return, without removing it.
+func (q *Queue) Peek() (offset int64, op interface{}, ok bool) {
+	if q.len == 0 {
+		return 0, nil, false
+	}
+	ahead, behind := q.ascending, q.descending
+	if q.dir == Down {
+		ahead, behind = behind, ahead
+	}
+	if ahead.IsEmpty() {
+		ahead = behind
+	}
+	r := ahead.FindMin().(request)
+	return r.offset, r.op, true
+}
+
+// Position returns the offset of the last request served, or the start
+// offset.
+func (q *Queue) Position() int64 {
+	return q.pos
+}
+
+// Direction returns the direction of the current sweep.
+func (q *Queue) Direction() Direction {
+	return q.dir
+}
+
+// Len returns the number of queued requests.
+func (q *Queue) Len() int {
+	return q.len
+}
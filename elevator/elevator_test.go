@@ -0,0 +1,105 @@
+package elevator
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func drain(q *Queue) (offsets []int64) {
+	for {
+		offset, _, ok := q.NextInDirection()
+		if !ok {
+			return
+		}
+		offsets = append(offsets, offset)
+	}
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSweep(t *testing.T) {
+	q := New(50)
+	for _, offset := range []int64{98, 183, 37, 122, 14, 124, 65, 67} {
+		q.Add(offset, nil)
+	}
+	want := []int64{65, 67, 98, 122, 124, 183, 37, 14}
+	if got := drain(q); !equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if q.Direction() != Down || q.Position() != 14 {
+		t.Fatalf("expected to end moving down at 14, got %v at %d", q.Direction(), q.Position())
+	}
+}
+
+func TestAddDuringSweep(t *testing.T) {
+	q := New(0)
+	q.Add(10, "a")
+	q.Add(30, "b")
+	if offset, op, _ := q.NextInDirection(); offset != 10 || op != "a" {
+		t.Fatalf("unexpected request %d %v", offset, op)
+	}
+	// behind the head: waits for the way back
+	q.Add(5, "c")
+	// at the head: served in this sweep, after earlier requests there
+	q.Add(10, "d")
+	q.Add(20, "e")
+	want := []int64{10, 20, 30, 5}
+	if offset, op, _ := q.Peek(); offset != 10 || op != "d" {
+		t.Fatalf("unexpected peek %d %v", offset, op)
+	}
+	if got := drain(q); !equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// moving down now
+	q.Add(1, "f")
+	q.Add(5, "g")
+	q.Add(9, "h")
+	if got := drain(q); !equal(got, []int64{5, 1, 9}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if _, _, ok := q.Peek(); ok || q.Len() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func TestSameOffsetFIFO(t *testing.T) {
+	q := New(0)
+	for i := 0; i < 5; i++ {
+		q.Add(7, i)
+		q.Add(-7, i)
+	}
+	for i := 0; i < 10; i++ {
+		_, op, _ := q.NextInDirection()
+		if op != i%5 {
+			t.Fatalf("expected request %d, got %v", i%5, op)
+		}
+	}
+}
+
+func TestAtMostOneReversalPerSweep(t *testing.T) {
+	q := New(500)
+	for i := 0; i < 1000; i++ {
+		q.Add(rand.Int63n(1000), nil)
+	}
+	got := drain(q)
+	reversals := 0
+	for i := 2; i < len(got); i++ {
+		if (got[i]-got[i-1])*(got[i-1]-got[i-2]) < 0 {
+			reversals++
+		}
+	}
+	if len(got) != 1000 || reversals > 1 {
+		t.Fatalf("expected 1000 requests with one reversal, got %d with %d", len(got), reversals)
+	}
+}
@@ -0,0 +1,215 @@
+// Package fairqueue implements a fair multi-tenant queue with per-tenant
+// rate quotas.
+//
+// Every tenant has its own heap of items, and a scheduler heap orders the
+// backlogged tenants by virtual time: serving an item of a tenant advances
+// its virtual time by 1/weight, so tenants receive a share of the pops
+// proportional to their weight whatever their backlog. A tenant that
+// becomes backlogged again starts at the virtual time of the last served
+// tenant, so being idle earns no credit.
+//
+// A tenant can also be given a quota, a token bucket limiting how fast its
+// items are popped. A tenant that runs out of tokens is parked in a
+// deadline heap until its next token is due, letting the other tenants
+// through meanwhile.
+//
+// Within a tenant items are popped in heap order.
+//
+// Structure is not thread safe.
+package fairqueue
+
+import (
+	"fmt"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/deadline"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/ratelimit"
+)
+
+type state int
+
+const (
+	idle state = iota
+	active
+	throttled
+)
+
+type tenant struct {
+	key    interface{}
+	id     uint64 // breaks ties between tenants, in order of creation
+	weight float64
+	vtime  float64
+	state  state
+	items  *pairing.PairHeap
+	limit  *ratelimit.Heap // gates removals from items, nil without a quota
+}
+
+// entry is the scheduler heap item of an active tenant.
+type entry struct {
+	vtime  float64
+	tenant *tenant
+}
+
+func (e entry) Compare(than heap.Item) int {
+	o := than.(entry)
+	switch {
+	case e.vtime < o.vtime:
+		return -1
+	case e.vtime > o.vtime:
+		return 1
+	case e.tenant.id < o.tenant.id:
+		return -1
+	case e.tenant.id > o.tenant.id:
+		return 1
+	}
+	return 0
+}
+
+// Queue is a fair multi-tenant queue.
+type Queue struct {
+	tenants   map[interface{}]*tenant
+	active    *pairing.PairHeap // backlogged tenants with tokens, by virtual time
+	throttled *deadline.Heap    // backlogged tenants out of tokens, by next token
+	vtime     float64
+	nextID    uint64
+	len       int
+}
+
+// New returns an empty Queue.
+func New() *Queue {
+	return &Queue{
+		tenants:   make(map[interface{}]*tenant),
+		active:    pairing.New(),
+		throttled: deadline.New(),
+	}
+}
+
+// SetWeight sets the weight of tenant, 1 by default. It panics if weight is
+// not positive.
+func (q *Queue) SetWeight(key interface{}, weight float64) {
+	if !(weight > 0) {
+		panic(fmt.Sprintf("fairqueue: invalid weight %v", weight))
+	}
+	q.tenant(key).weight = weight
+}
+
+// SetQuota limits tenant to rate pops per second, with bursts of up to
+// burst pops. The bucket starts full. A rate of zero removes the quota. A
+// tenant already parked for lack of tokens stays parked until its next
+// token was due under the previous quota.
+func (q *Queue) SetQuota(key interface{}, rate float64, burst int) {
+	if rate < 0 || (rate > 0 && burst < 1) {
+		panic(fmt.Sprintf("fairqueue: invalid quota of %v per second with burst %d", rate, burst))
+	}
+	t := q.tenant(key)
+	if rate == 0 {
+		t.limit = nil
+		return
+	}
+	t.limit = ratelimit.New(t.items, rate, burst)
+}
+
+// Len returns the number of queued items.
+func (q *Queue) Len() int {
+	return q.len
+}
+
+// TenantLen returns the number of items queued by tenant.
+func (q *Queue) TenantLen(key interface{}) int {
+	if t, ok := q.tenants[key]; ok {
+		return t.items.Len()
+	}
+	return 0
+}
+
+// Push queues item for tenant.
+// The complexity is O(1) amortized.
+func (q *Queue) Push(key interface{}, item heap.Item) {
+	t := q.tenant(key)
+	t.items.Insert(item)
+	q.len++
+	if t.state == idle {
+		if t.vtime < q.vtime {
+			t.vtime = q.vtime
+		}
+		t.state = active
+		q.active.Insert(entry{vtime: t.vtime, tenant: t})
+	}
+}
+
+// Pop removes and returns the smallest item of the tenant with the lowest
+// virtual time among the tenants within their quota. ok is false if the
+// queue is empty or every backlogged tenant is out of tokens; Delay tells
+// how long to wait then.
+// The complexity is O(log n) amortized for n backlogged tenants, plus
+// O(log n) for each tenant found out of tokens.
+func (q *Queue) Pop() (key interface{}, item heap.Item, ok bool) {
+	q.wake()
+	for !q.active.IsEmpty() {
+		e := q.active.FindMin().(entry)
+		t := e.tenant
+		if t.limit != nil {
+			if item, ok = t.limit.Pop(); !ok {
+				q.active.DeleteMin()
+				t.state = throttled
+				q.throttled.Push(time.Now().Add(t.limit.Delay()), t)
+				continue
+			}
+		} else {
+			item = t.items.DeleteMin()
+		}
+
+		q.vtime = t.vtime
+		t.vtime += 1 / t.weight
+		q.len--
+		if t.items.IsEmpty() {
+			q.active.DeleteMin()
+			t.state = idle
+		} else {
+			q.active.Adjust(e, entry{vtime: t.vtime, tenant: t})
+		}
+		return t.key, item, true
+	}
+	return nil, nil, false
+}
+
+// Delay returns how long to wait until a tenant out of tokens gets one
+// back, or 0 if an item can be popped now or the queue is empty.
+func (q *Queue) Delay() time.Duration {
+	q.wake()
+	if !q.active.IsEmpty() {
+		return 0
+	}
+	next, ok := q.throttled.NextDeadline()
+	if !ok {
+		return 0
+	}
+	if d := time.Until(next); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// wake moves the tenants whose next token is due back to the scheduler.
+func (q *Queue) wake() {
+	if q.throttled.IsEmpty() {
+		return
+	}
+	for _, e := range q.throttled.PopExpired(time.Now()) {
+		t := e.Value.(*tenant)
+		t.state = active
+		q.active.Insert(entry{vtime: t.vtime, tenant: t})
+	}
+}
+
+func (q *Queue) tenant(key interface{}) *tenant {
+	t, ok := q.tenants[key]
+	if !ok {
+		t = &tenant{key: key, id: q.nextID, weight: 1, items: pairing.New()}
+		q.nextID++
+		q.tenants[key] = t
+	}
+	return t
+}
@@ -0,0 +1,123 @@
+package fairqueue
+
+import (
+	"testing"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestWeightedShares(t *testing.T) {
+	q := New()
+	if _, _, ok := q.Pop(); ok {
+		t.Fatal("expected nothing from an empty queue")
+	}
+
+	q.SetWeight("a", 2)
+	for i := 0; i < 100; i++ {
+		q.Push("a", heap.Integer(100-i))
+		q.Push("b", heap.Integer(100-i))
+	}
+	if q.Len() != 200 || q.TenantLen("a") != 100 {
+		t.Fatalf("unexpected lengths %d, %d", q.Len(), q.TenantLen("a"))
+	}
+
+	served := map[interface{}]int{}
+	for i := 0; i < 60; i++ {
+		tenant, item, ok := q.Pop()
+		if !ok {
+			t.Fatal("unexpected empty queue")
+		}
+		served[tenant]++
+		if item != heap.Integer(served[tenant]) {
+			t.Fatalf("tenant %v: expected item %d, got %v", tenant, served[tenant], item)
+		}
+	}
+	if served["a"] != 40 || served["b"] != 20 {
+		t.Fatalf("expected a 2:1 share, got %v", served)
+	}
+}
+
+func TestIdleEarnsNoCredit(t *testing.T) {
+	q := New()
+	for i := 0; i < 20; i++ {
+		q.Push("a", heap.Integer(i))
+	}
+	for i := 0; i < 10; i++ {
+		q.Pop()
+	}
+	for i := 0; i < 10; i++ {
+		q.Push("b", heap.Integer(i))
+	}
+	// b joins at the current virtual time and alternates with a
+	var order []interface{}
+	for i := 0; i < 6; i++ {
+		tenant, _, _ := q.Pop()
+		order = append(order, tenant)
+	}
+	for i := 0; i+1 < len(order); i++ {
+		if order[i] == order[i+1] {
+			t.Fatalf("expected a and b to alternate, got %v", order)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	q := New()
+	q.SetQuota("a", 5, 3)
+	for i := 0; i < 10; i++ {
+		q.Push("a", heap.Integer(i))
+	}
+	for i := 0; i < 4; i++ {
+		q.Push("b", heap.Integer(i))
+	}
+
+	served := map[interface{}]int{}
+	for {
+		tenant, _, ok := q.Pop()
+		if !ok {
+			break
+		}
+		served[tenant]++
+	}
+	if served["a"] != 3 || served["b"] != 4 {
+		t.Fatalf("expected a to be held to its burst, got %v", served)
+	}
+	d := q.Delay()
+	if d <= 0 || d > 200*time.Millisecond {
+		t.Fatalf("unexpected delay %v", d)
+	}
+
+	time.Sleep(d)
+	if tenant, item, ok := q.Pop(); !ok || tenant != "a" || item != heap.Integer(3) {
+		t.Fatalf("expected a to get a token back, got %v %v %v", tenant, item, ok)
+	}
+
+	q.SetQuota("a", 0, 0)
+	time.Sleep(q.Delay())
+	for i := 4; i < 10; i++ {
+		if _, item, ok := q.Pop(); !ok || item != heap.Integer(i) {
+			t.Fatalf("expected %d without a quota, got %v", i, item)
+		}
+	}
+	if q.Len() != 0 || q.Delay() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func TestInvalidSettingsPanic(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"weight": func() { New().SetWeight("a", 0) },
+		"rate":   func() { New().SetQuota("a", -1, 1) },
+		"burst":  func() { New().SetQuota("a", 1, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
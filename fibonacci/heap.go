@@ -114,6 +114,40 @@ func (fh *FibonacciHeap) consolidate() {
 
 }
 
+// Potential reports the number of trees in the root list and the number of
+// marked nodes, together with the potential trees + 2*marked of the
+// amortized analysis of Fibonacci heaps.
+// The complexity is O(n).
+func (fh *FibonacciHeap) Potential() map[string]int {
+	trees, marked := 0, 0
+	if fh.root != nil {
+		stack := []*node{}
+		for n := fh.root; ; n = n.next {
+			trees++
+			stack = append(stack, n)
+			if n.next == fh.root {
+				break
+			}
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if n.isMarked {
+				marked++
+			}
+			if c := n.child; c != nil {
+				for x := c; ; x = x.next {
+					stack = append(stack, x)
+					if x.next == c {
+						break
+					}
+				}
+			}
+		}
+	}
+	return map[string]int{"trees": trees, "marked": marked, "potential": trees + 2*marked}
+}
+
 // Clear resets heap.
 func (fh *FibonacciHeap) Clear() {
 	fh.root = nil
@@ -0,0 +1,133 @@
+package go_heaps
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Float64 implements the Item interface.
+//
+// Comparisons involving NaN are always false in Go, so ordering raw float64
+// values with < silently corrupts a heap once a NaN gets in. Float64 orders
+// NaN after every number, +Inf included, and equal to any other NaN, which
+// keeps the order total. Use Float64Heap to decide what happens to NaN
+// priorities before they reach the heap.
+type Float64 float64
+
+func (a Float64) Compare(b Item) int {
+	x, y := float64(a), float64(b.(Float64))
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	case x == y:
+		return 0
+	}
+	// at least one NaN
+	switch xNaN, yNaN := math.IsNaN(x), math.IsNaN(y); {
+	case xNaN && yNaN:
+		return 0
+	case xNaN:
+		return 1
+	}
+	return -1
+}
+
+// NaNPolicy decides how a Float64Heap handles NaN priorities.
+type NaNPolicy int
+
+const (
+	// NaNReject makes Push return ErrNaN and leave the heap unchanged.
+	NaNReject NaNPolicy = iota
+	// NaNAsInf pushes NaN as +Inf, so it is popped last.
+	NaNAsInf
+	// NaNPanic makes Push panic, for programs where a NaN priority is a bug.
+	NaNPanic
+)
+
+func (p NaNPolicy) String() string {
+	switch p {
+	case NaNReject:
+		return "NaNReject"
+	case NaNAsInf:
+		return "NaNAsInf"
+	case NaNPanic:
+		return "NaNPanic"
+	}
+	return fmt.Sprintf("NaNPolicy(%d)", int(p))
+}
+
+// ErrNaN is returned by Float64Heap.Push for NaN priorities under the
+// NaNReject policy.
+var ErrNaN = errors.New("go_heaps: NaN priority")
+
+// Float64Heap specializes a heap to float64 priorities, applying a NaN
+// policy on Push. It wraps any heap implementation, which must be empty or
+// hold only Float64 items.
+type Float64Heap struct {
+	h      Interface
+	policy NaNPolicy
+}
+
+// NewFloat64Heap returns a Float64Heap storing its priorities in h.
+func NewFloat64Heap(h Interface, policy NaNPolicy) *Float64Heap {
+	return &Float64Heap{h: h, policy: policy}
+}
+
+// Heap returns the underlying heap.
+func (f *Float64Heap) Heap() Interface {
+	return f.h
+}
+
+// Policy returns the NaN policy of f.
+func (f *Float64Heap) Policy() NaNPolicy {
+	return f.policy
+}
+
+// Push inserts x, applying the NaN policy if x is NaN.
+func (f *Float64Heap) Push(x float64) error {
+	if math.IsNaN(x) {
+		switch f.policy {
+		case NaNAsInf:
+			x = math.Inf(1)
+		case NaNPanic:
+			panic(ErrNaN)
+		default:
+			return ErrNaN
+		}
+	}
+	f.h.Insert(Float64(x))
+	return nil
+}
+
+// Pop removes and returns the smallest priority. It returns false if the
+// heap is empty.
+func (f *Float64Heap) Pop() (float64, bool) {
+	item := f.h.DeleteMin()
+	if item == nil {
+		return 0, false
+	}
+	return float64(item.(Float64)), true
+}
+
+// Peek returns the smallest priority without removing it. It returns false
+// if the heap is empty.
+func (f *Float64Heap) Peek() (float64, bool) {
+	item := f.h.FindMin()
+	if item == nil {
+		return 0, false
+	}
+	return float64(item.(Float64)), true
+}
+
+// IsEmpty returns true if the heap holds no priority.
+func (f *Float64Heap) IsEmpty() bool {
+	return f.h.FindMin() == nil
+}
+
+// Clear removes all priorities from the heap.
+func (f *Float64Heap) Clear() {
+	f.h.Clear()
+}
@@ -0,0 +1,220 @@
+// Package frequency implements a frequency-aware priority queue, the
+// building block of an LFU-like eviction queue.
+//
+// Every key has a base priority and counts its accesses. Its effective
+// priority is the base priority plus the number of accesses times a
+// configurable weight, so keys used often rise away from the front of the
+// queue and Pop returns the least valuable key. Keys of equal effective
+// priority are popped least recently pushed or touched first.
+//
+// Keys live in the slots of an indexpq.IndexMinPQ, whose ChangeKey moves a
+// key in O(log n) when it is touched, so the capacity is fixed.
+//
+// Structure is not thread safe.
+package frequency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/indexpq"
+)
+
+// ErrFull is returned by Push when the queue holds as many keys as its
+// capacity.
+var ErrFull = errors.New("frequency: queue is full")
+
+// Option configures a Queue created by New.
+type Option func(*Queue)
+
+// WithWeight sets the priority every access adds to a key, 1 by default. A
+// weight of 0 ignores the accesses and orders keys by base priority alone,
+// then by recency like an LRU queue.
+func WithWeight(weight float64) Option {
+	if math.IsNaN(weight) || math.IsInf(weight, 0) {
+		panic(fmt.Sprintf("frequency: invalid weight %v", weight))
+	}
+	return func(q *Queue) {
+		q.weight = weight
+	}
+}
+
+// score is the effective priority of a key in the index queue.
+type score struct {
+	value float64
+	tick  uint64 // when the key was last pushed or touched
+}
+
+// Compare orders scores by value, then from the least recent.
+func (s score) Compare(than heap.Item) int {
+	o := than.(score)
+	switch {
+	case s.value < o.value:
+		return -1
+	case s.value > o.value:
+		return 1
+	case s.tick < o.tick:
+		return -1
+	case s.tick > o.tick:
+		return 1
+	}
+	return 0
+}
+
+// entry is a key of the queue, stored at its slot.
+type entry struct {
+	key      interface{}
+	priority float64
+	hits     int
+}
+
+// Queue is a priority queue of keys whose priority grows with their accesses.
+type Queue struct {
+	pq      *indexpq.IndexMinPQ
+	slots   map[interface{}]int
+	entries []entry // by slot
+	free    []int   // the slots not in use
+	weight  float64
+	tick    uint64
+}
+
+// New returns an empty Queue holding up to capacity keys.
+func New(capacity int, opts ...Option) *Queue {
+	q := &Queue{
+		pq:      indexpq.New(capacity),
+		slots:   make(map[interface{}]int, capacity),
+		entries: make([]entry, capacity),
+		free:    make([]int, capacity),
+		weight:  1,
+	}
+	for i := range q.free {
+		q.free[i] = capacity - 1 - i
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Len returns the number of keys in the queue.
+func (q *Queue) Len() int {
+	return q.pq.Len()
+}
+
+// Cap returns the number of keys the queue can hold.
+func (q *Queue) Cap() int {
+	return q.pq.Cap()
+}
+
+// Push adds key with the given base priority and no access, or sets the
+// base priority of key if it is already queued, keeping its accesses. It
+// returns heap.ErrNaN for a NaN priority and ErrFull when a new key does not
+// fit; Pop makes room.
+// The complexity is O(log n).
+func (q *Queue) Push(key interface{}, priority float64) error {
+	if math.IsNaN(priority) {
+		return heap.ErrNaN
+	}
+	if slot, ok := q.slots[key]; ok {
+		q.entries[slot].priority = priority
+		q.update(slot)
+		return nil
+	}
+	if len(q.free) == 0 {
+		return ErrFull
+	}
+	slot := q.free[len(q.free)-1]
+	q.free = q.free[:len(q.free)-1]
+	q.slots[key] = slot
+	q.entries[slot] = entry{key: key, priority: priority}
+	q.pq.Insert(slot, q.score(slot))
+	return nil
+}
+
+// Touch records an access to key, raising its effective priority by the
+// weight, and reports whether key is queued.
+// The complexity is O(log n).
+func (q *Queue) Touch(key interface{}) bool {
+	slot, ok := q.slots[key]
+	if !ok {
+		return false
+	}
+	q.entries[slot].hits++
+	q.update(slot)
+	return true
+}
+
+// Peek returns the key Pop would remove next and its effective priority,
+// or false if the queue is empty.
+// The complexity is O(1).
+func (q *Queue) Peek() (key interface{}, priority float64, ok bool) {
+	if q.pq.IsEmpty() {
+		return nil, 0, false
+	}
+	return q.entries[q.pq.MinIndex()].key, q.pq.MinKey().(score).value, true
+}
+
+// Pop removes the key of the lowest effective priority, the one to evict,
+// and returns it, or false if the queue is empty.
+// The complexity is O(log n).
+func (q *Queue) Pop() (key interface{}, ok bool) {
+	if q.pq.IsEmpty() {
+		return nil, false
+	}
+	slot := q.pq.DelMin()
+	key = q.entries[slot].key
+	q.release(slot)
+	return key, true
+}
+
+// Remove removes key and reports whether it was queued.
+// The complexity is O(log n).
+func (q *Queue) Remove(key interface{}) bool {
+	slot, ok := q.slots[key]
+	if !ok {
+		return false
+	}
+	q.pq.Delete(slot)
+	q.release(slot)
+	return true
+}
+
+// Hits returns the number of accesses recorded for key since it was
+// pushed, or 0 if it is not queued.
+func (q *Queue) Hits(key interface{}) int {
+	if slot, ok := q.slots[key]; ok {
+		return q.entries[slot].hits
+	}
+	return 0
+}
+
+// Priority returns the effective priority of key, and false if it is not
+// queued.
+func (q *Queue) Priority(key interface{}) (float64, bool) {
+	if slot, ok := q.slots[key]; ok {
+		return q.pq.KeyOf(slot).(score).value, true
+	}
+	return 0, false
+}
+
+// score returns a new score for the entry at slot, making it the most
+// recent.
+func (q *Queue) score(slot int) score {
+	e := &q.entries[slot]
+	q.tick++
+	return score{value: e.priority + q.weight*float64(e.hits), tick: q.tick}
+}
+
+// update moves the entry at slot after its priority or hits changed.
+func (q *Queue) update(slot int) {
+	q.pq.ChangeKey(slot, q.score(slot))
+}
+
+// release frees slot once its entry left the index queue.
+func (q *Queue) release(slot int) {
+	delete(q.slots, q.entries[slot].key)
+	q.entries[slot] = entry{}
+	q.free = append(q.free, slot)
+}
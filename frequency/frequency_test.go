@@ -0,0 +1,89 @@
+package frequency
+
+import (
+	"math"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestEviction(t *testing.T) {
+	q := New(3)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := q.Push(key, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Push("d", 0); err != ErrFull {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+
+	q.Touch("a")
+	q.Touch("a")
+	q.Touch("b")
+	if q.Touch("d") {
+		t.Fatal("touched a key not queued")
+	}
+	if key, priority, _ := q.Peek(); key != "c" || priority != 0 {
+		t.Fatalf("expected c at 0 to be evicted first, got %v at %v", key, priority)
+	}
+	if key, _ := q.Pop(); key != "c" {
+		t.Fatalf("expected c, got %v", key)
+	}
+
+	// d takes the slot of c and is evicted before b, touched once
+	if err := q.Push("d", 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"d", "b", "a"} {
+		if key, ok := q.Pop(); !ok || key != want {
+			t.Fatalf("expected %s, got %v", want, key)
+		}
+	}
+	if _, ok := q.Pop(); ok || q.Len() != 0 {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func TestWeight(t *testing.T) {
+	q := New(2, WithWeight(0.5))
+	q.Push("cheap", 1)
+	q.Push("dear", 3)
+	for i := 0; i < 5; i++ {
+		q.Touch("cheap")
+	}
+	if p, _ := q.Priority("cheap"); p != 3.5 || q.Hits("cheap") != 5 {
+		t.Fatalf("expected 5 hits and a priority of 3.5, got %d and %v", q.Hits("cheap"), p)
+	}
+	if key, _ := q.Pop(); key != "dear" {
+		t.Fatalf("expected dear, got %v", key)
+	}
+
+	// pushing again sets the base priority and keeps the hits
+	q.Push("cheap", -10)
+	if p, _ := q.Priority("cheap"); p != -7.5 {
+		t.Fatalf("expected -7.5, got %v", p)
+	}
+}
+
+func TestRecency(t *testing.T) {
+	q := New(3, WithWeight(0))
+	q.Push("a", 1)
+	q.Push("b", 1)
+	q.Push("c", 1)
+	q.Touch("a")
+	if !q.Remove("b") || q.Remove("b") {
+		t.Fatal("expected b to be removed once")
+	}
+	for _, want := range []string{"c", "a"} {
+		if key, _ := q.Pop(); key != want {
+			t.Fatalf("expected %s, got %v", want, key)
+		}
+	}
+	if err := q.Push("nan", math.NaN()); err != heap.ErrNaN {
+		t.Fatalf("expected ErrNaN, got %v", err)
+	}
+	if q.Len() != 0 || q.Cap() != 3 {
+		t.Fatalf("unexpected size %d of %d", q.Len(), q.Cap())
+	}
+}
@@ -0,0 +1,37 @@
+package go_heaps
+
+import (
+	"fmt"
+	"math"
+)
+
+// DegenerateFactor is how many times deeper than a balanced binary tree of
+// the same size a heap may grow before it is reported as degenerate.
+const DegenerateFactor = 4
+
+// Health reports how far the shape of a heap has drifted from a balanced
+// tree of the same size. Heaps return it from their DetectDegenerate
+// diagnostics.
+type Health struct {
+	Size       int
+	MaxDepth   int     // nodes on the longest path from the root to a leaf
+	Log2N      float64 // log2(Size+1), the depth of a balanced binary tree
+	Degenerate bool    // MaxDepth exceeds DegenerateFactor * ceil(Log2N)
+}
+
+// NewHealth returns the Health of a heap holding size items with the given
+// maximum depth.
+func NewHealth(size, maxDepth int) Health {
+	log2n := math.Log2(float64(size + 1))
+	return Health{
+		Size:       size,
+		MaxDepth:   maxDepth,
+		Log2N:      log2n,
+		Degenerate: float64(maxDepth) > DegenerateFactor*math.Ceil(log2n),
+	}
+}
+
+func (h Health) String() string {
+	return fmt.Sprintf("size=%d depth=%d log2n=%.2f degenerate=%t",
+		h.Size, h.MaxDepth, h.Log2N, h.Degenerate)
+}
@@ -0,0 +1,559 @@
+package go_heaps_test
+
+import (
+	container "container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/treap"
+)
+
+func TestPairCompare(t *testing.T) {
+	a := heap.KV(heap.Integer(1), "one")
+	b := heap.KV(heap.Integer(2), "two")
+
+	if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+		t.Fail()
+	}
+	if a.Compare(heap.KV(heap.Integer(1), "uno")) != 0 {
+		t.Fail()
+	}
+	if b.Compare(heap.Integer(2)) != 0 || b.Compare(heap.Integer(3)) >= 0 {
+		t.Fail()
+	}
+}
+
+func TestCompositeCompare(t *testing.T) {
+	tests := []struct {
+		a, b heap.CompositeItem
+		want int
+	}{
+		{heap.Composite(heap.Integer(1), heap.String("b")), heap.Composite(heap.Integer(2), heap.String("a")), -1},
+		{heap.Composite(heap.Integer(1), heap.String("b")), heap.Composite(heap.Integer(1), heap.String("a")), 1},
+		{heap.Composite(heap.Integer(1), heap.String("a")), heap.Composite(heap.Integer(1), heap.String("a")), 0},
+		{heap.Composite(heap.Integer(1)), heap.Composite(heap.Integer(1), heap.String("a")), -1},
+		{heap.Composite(heap.Integer(1), heap.String("a")), heap.Composite(heap.Integer(1)), 1},
+		{heap.Composite(), heap.Composite(), 0},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Compare(test.b); got != test.want {
+			t.Errorf("%v.Compare(%v) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCompositeTieBreaking(t *testing.T) {
+	// (class, deadline, sequence): sequence breaks ties between equal
+	// classes and deadlines so extraction is fully determined.
+	items := []heap.CompositeItem{
+		heap.Composite(heap.Integer(1), heap.Integer(30), heap.Integer(0)),
+		heap.Composite(heap.Integer(0), heap.Integer(50), heap.Integer(1)),
+		heap.Composite(heap.Integer(1), heap.Integer(10), heap.Integer(2)),
+		heap.Composite(heap.Integer(1), heap.Integer(30), heap.Integer(3)),
+		heap.Composite(heap.Integer(0), heap.Integer(50), heap.Integer(4)),
+		heap.Composite(heap.Integer(1), heap.Integer(10), heap.Integer(5)),
+	}
+	want := []int{1, 4, 2, 5, 0, 3}
+
+	heaps := map[string]heap.Interface{
+		"pairing": pairing.New(),
+		"leftist": leftist.New(),
+	}
+
+	for name, h := range heaps {
+		for _, item := range items {
+			h.Insert(item)
+		}
+		for _, seq := range want {
+			got := h.DeleteMin().(heap.CompositeItem)
+			if got[2] != heap.Integer(seq) {
+				t.Errorf("%s: expected sequence %d, got %v", name, seq, got)
+			}
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	if heap.Reverse(heap.Integer(1)).Compare(heap.Reverse(heap.Integer(2))) <= 0 {
+		t.Fail()
+	}
+	if heap.Reverse(heap.Integer(1)).Compare(heap.Reverse(heap.Integer(1))) != 0 {
+		t.Fail()
+	}
+
+	h := pairing.New()
+	for _, v := range []int{4, 9, 1, 7} {
+		h.Insert(heap.Reverse(heap.Integer(v)))
+	}
+
+	if h.Find(heap.Reverse(heap.Integer(7))) == nil {
+		t.Fail()
+	}
+	h.Adjust(heap.Reverse(heap.Integer(1)), heap.Reverse(heap.Integer(8)))
+
+	for _, want := range []int{9, 8, 7, 4} {
+		got := h.DeleteMin().(heap.Reversed)
+		if got.Item != heap.Integer(want) {
+			t.Errorf("expected %d, got %v", want, got.Item)
+		}
+	}
+}
+
+func TestHeapify(t *testing.T) {
+	if !heap.IsHeap(nil) {
+		t.Fail()
+	}
+	if heap.IsHeap([]heap.Item{heap.Integer(2), heap.Integer(1)}) {
+		t.Fail()
+	}
+
+	for n := 0; n < 50; n++ {
+		items := make([]heap.Item, n)
+		for i, v := range rand.Perm(n) {
+			items[i] = heap.Integer(v % 7)
+		}
+		heap.Heapify(items)
+		if !heap.IsHeap(items) {
+			t.Fatalf("not a heap after Heapify: %v", items)
+		}
+	}
+}
+
+func TestItemsWithContainerHeap(t *testing.T) {
+	items := &heap.Items{}
+	for _, v := range rand.Perm(100) {
+		container.Push(items, heap.Integer(v))
+	}
+	if !heap.IsHeap(*items) {
+		t.Fatal("container/heap did not keep heap order")
+	}
+	for want := 0; want < 100; want++ {
+		if got := container.Pop(items); got != heap.Integer(want) {
+			t.Fatalf("expected %d, got %v", want, got)
+		}
+	}
+}
+
+func TestFromContainer(t *testing.T) {
+	h := heap.FromContainer(&heap.Items{})
+	if h.FindMin() != nil || h.DeleteMin() != nil {
+		t.Fail()
+	}
+
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	if h.FindMin() != heap.Integer(0) {
+		t.Fail()
+	}
+	for want := 0; want < 50; want++ {
+		if got := h.DeleteMin(); got != heap.Integer(want) {
+			t.Fatalf("expected %d, got %v", want, got)
+		}
+	}
+
+	h.Clear()
+	if h.FindMin() != nil {
+		t.Fail()
+	}
+}
+
+// btreeItem mimics the google/btree item convention.
+type btreeItem interface {
+	Less(than btreeItem) bool
+}
+
+type account struct {
+	id      int
+	balance int
+}
+
+func (a account) Less(than btreeItem) bool {
+	return a.balance < than.(account).balance
+}
+
+func TestLess(t *testing.T) {
+	h := pairing.New()
+	for i, balance := range []int{30, 10, 20, 10} {
+		h.Insert(heap.Less(account{id: i, balance: balance}))
+	}
+
+	if h.Find(heap.Less(account{balance: 20})) == nil {
+		t.Fail()
+	}
+	for _, want := range []int{10, 10, 20, 30} {
+		got := h.DeleteMin().(heap.LessItem).Value.(account)
+		if got.balance != want {
+			t.Errorf("expected balance %d, got %d", want, got.balance)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a value without Less")
+		}
+	}()
+	heap.Less(42)
+}
+
+func TestSort(t *testing.T) {
+	heaps := map[string]func() heap.Interface{
+		"pairing":      func() heap.Interface { return pairing.New() },
+		"leftist":      func() heap.Interface { return leftist.New() },
+		"skew":         func() heap.Interface { return skew.New() },
+		"fibonacci":    func() heap.Interface { return fibonacci.New() },
+		"binomial":     func() heap.Interface { return &binomial.BinomialHeap{} },
+		"rank pairing": func() heap.Interface { return rpheap.New() },
+		"treap":        func() heap.Interface { return treap.New() },
+	}
+
+	for name, newHeap := range heaps {
+		items := make(heap.Items, 500)
+		for i := range items {
+			items[i] = heap.Integer(rand.Intn(100))
+		}
+		want := append(heap.Items(nil), items...)
+		sort.Sort(want)
+
+		heap.Sort(items, newHeap())
+		for i := range items {
+			if items[i] != want[i] {
+				t.Errorf("%s: expected %v at %d, got %v", name, want[i], i, items[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	items := make([]heap.Item, 500)
+	for i := range items {
+		items[i] = heap.KV(heap.Integer(rand.Intn(10)), i)
+	}
+	want := append(heap.Items(nil), items...)
+	sort.Stable(want)
+
+	heap.Sort(items, pairing.New(pairing.WithStable()))
+	for i := range items {
+		if items[i] != want[i] {
+			t.Fatalf("expected %v at %d, got %v", want[i], i, items[i])
+		}
+	}
+}
+
+func TestMinOf(t *testing.T) {
+	a, b, c := pairing.New(), leftist.New(), pairing.New()
+	if min, h := heap.MinOf(a, nil, b); min != nil || h != nil {
+		t.Fatalf("expected no minimum, got %v from %T", min, h)
+	}
+
+	a.Insert(heap.Integer(5))
+	b.Insert(heap.Integer(3))
+	b.Insert(heap.Integer(7))
+	c.Insert(heap.Integer(3))
+	min, h := heap.MinOf(a, b, c)
+	if min != heap.Integer(3) || h != b {
+		t.Fatalf("expected 3 from the leftist heap, got %v from %T", min, h)
+	}
+
+	b.DeleteMin()
+	if min, h = heap.MinOf(a, b, c); min != heap.Integer(3) || h != c {
+		t.Fatalf("expected 3 from the second pairing heap, got %v", min)
+	}
+	if b.FindMin() != heap.Integer(7) {
+		t.Fatal("MinOf removed an item")
+	}
+}
+
+func TestNestedHeaps(t *testing.T) {
+	users := pairing.New()
+	queues := []heap.Interface{pairing.New(), leftist.New(), pairing.New()}
+	for i, q := range queues {
+		for j := 0; j < 5; j++ {
+			q.Insert(heap.Integer(10*j + i))
+		}
+		users.Insert(q.(heap.Item))
+	}
+	users.Insert(pairing.New()) // empty queues sort last
+
+	// pop the most urgent queue, take its task and put it back
+	for want := 0; want < 50; want++ {
+		if want%10 >= len(queues) {
+			continue
+		}
+		q := users.DeleteMin().(heap.Interface)
+		if got := q.DeleteMin(); got != heap.Integer(want) {
+			t.Fatalf("expected task %d, got %v", want, got)
+		}
+		users.Insert(q.(heap.Item))
+	}
+	if q := users.FindMin().(heap.Interface); q.FindMin() != nil {
+		t.Fatalf("expected only empty queues, got %v", q.FindMin())
+	}
+
+	if heap.CompareMin(pairing.New(), heap.Integer(1)) <= 0 {
+		t.Fatal("expected an empty heap to compare greater than an item")
+	}
+}
+
+func TestMeldAll(t *testing.T) {
+	if heap.MeldAll(nil, nil) != nil {
+		t.Fatal("expected nil when every heap is nil")
+	}
+
+	heaps := map[string]func() heap.Interface{
+		"pairing":      func() heap.Interface { return pairing.New() },
+		"rank pairing": func() heap.Interface { return rpheap.New() },
+		"leftist":      func() heap.Interface { return leftist.New() },
+	}
+	for name, newHeap := range heaps {
+		var shards []heap.Interface
+		for i := 0; i < 7; i++ {
+			shards = append(shards, newHeap())
+		}
+		for _, v := range rand.Perm(200) {
+			shards[rand.Intn(len(shards))].Insert(heap.Integer(v))
+		}
+		shards = append(shards, nil, newHeap())
+
+		all := heap.MeldAll(shards...)
+		for _, h := range shards {
+			if h != nil && h != all && h.FindMin() != nil {
+				t.Fatalf("%s: expected melded heaps to be empty", name)
+			}
+		}
+		for i := 0; i < 200; i++ {
+			if item := all.DeleteMin(); item != heap.Integer(i) {
+				t.Fatalf("%s: expected %d, got %v", name, i, item)
+			}
+		}
+	}
+}
+
+func TestPushPopMany(t *testing.T) {
+	heaps := map[string]func() heap.Interface{
+		"pairing": func() heap.Interface { return pairing.New() },
+		"skew":    func() heap.Interface { return skew.New() },
+	}
+	for name, newHeap := range heaps {
+		h := newHeap()
+		var items []heap.Item
+		for _, v := range rand.Perm(100) {
+			items = append(items, heap.Integer(v))
+		}
+		heap.PushMany(h, items...)
+		if heap.PopMany(h, 0) != nil {
+			t.Fatalf("%s: expected no items for n = 0", name)
+		}
+		popped := heap.PopMany(h, 60)
+		popped = append(popped, heap.PopMany(h, 60)...)
+		if len(popped) != 100 {
+			t.Fatalf("%s: expected 100 items, got %d", name, len(popped))
+		}
+		for i, item := range popped {
+			if item != heap.Integer(i) {
+				t.Fatalf("%s: expected %d, got %v", name, i, item)
+			}
+		}
+	}
+}
+
+func TestFloat64Compare(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	// in increasing order, equal values grouped
+	groups := [][]float64{{math.Inf(-1)}, {-1.5}, {0, math.Copysign(0, -1)}, {2}, {inf}, {nan, -nan}}
+	for i, gi := range groups {
+		for j, gj := range groups {
+			for _, x := range gi {
+				for _, y := range gj {
+					got := heap.Float64(x).Compare(heap.Float64(y))
+					want := 0
+					if i < j {
+						want = -1
+					} else if i > j {
+						want = 1
+					}
+					if got != want {
+						t.Errorf("Compare(%v, %v) = %d, want %d", x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFloat64Sort(t *testing.T) {
+	items := make([]heap.Item, 500)
+	for i := range items {
+		switch rand.Intn(10) {
+		case 0:
+			items[i] = heap.Float64(math.NaN())
+		case 1:
+			items[i] = heap.Float64(math.Inf(1))
+		default:
+			items[i] = heap.Float64(rand.NormFloat64())
+		}
+	}
+	heap.Sort(items, pairing.New())
+	for i := 1; i < len(items); i++ {
+		if items[i-1].Compare(items[i]) > 0 {
+			t.Fatalf("%v sorted before %v", items[i-1], items[i])
+		}
+	}
+	if !math.IsNaN(float64(items[len(items)-1].(heap.Float64))) {
+		t.Fatal("expected NaN to sort last")
+	}
+}
+
+func TestFloat64Heap(t *testing.T) {
+	nan := math.NaN()
+	for _, policy := range []heap.NaNPolicy{heap.NaNReject, heap.NaNAsInf, heap.NaNPanic} {
+		f := heap.NewFloat64Heap(leftist.New(), policy)
+		if _, ok := f.Pop(); ok || !f.IsEmpty() {
+			t.Fatalf("%v: expected an empty heap", policy)
+		}
+		for _, x := range []float64{3, math.Inf(1), -1, 2.5} {
+			if err := f.Push(x); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var err error
+		panicked := func() (p bool) {
+			defer func() { p = recover() == heap.ErrNaN }()
+			err = f.Push(nan)
+			return false
+		}()
+		switch policy {
+		case heap.NaNReject:
+			if err != heap.ErrNaN || panicked {
+				t.Fatalf("%v: expected ErrNaN, got %v", policy, err)
+			}
+		case heap.NaNAsInf:
+			if err != nil || panicked {
+				t.Fatalf("%v: unexpected error %v", policy, err)
+			}
+		case heap.NaNPanic:
+			if !panicked {
+				t.Fatalf("%v: expected a panic", policy)
+			}
+		}
+
+		want := []float64{-1, 2.5, 3, math.Inf(1)}
+		if policy == heap.NaNAsInf {
+			want = append(want, math.Inf(1))
+		}
+		if x, _ := f.Peek(); x != -1 {
+			t.Fatalf("%v: expected -1, got %v", policy, x)
+		}
+		for _, w := range want {
+			if x, ok := f.Pop(); !ok || x != w {
+				t.Fatalf("%v: expected %v, got %v", policy, w, x)
+			}
+		}
+		if !f.IsEmpty() {
+			t.Fatalf("%v: expected an empty heap", policy)
+		}
+	}
+}
+
+func TestSampleWithoutReplacement(t *testing.T) {
+	heaps := map[string]func() heap.Interface{
+		"pairing": func() heap.Interface { return pairing.New() },
+		"skew":    func() heap.Interface { return skew.New() },
+	}
+	weight := func(item heap.Item) float64 { return float64(item.(heap.Integer)) }
+	for name, newHeap := range heaps {
+		h := newHeap()
+		for _, v := range rand.Perm(10) {
+			h.Insert(heap.Integer(v))
+		}
+		if heap.SampleWithoutReplacement(h, 0, weight) != nil {
+			t.Fatalf("%s: expected no items for k = 0", name)
+		}
+		// 0 has no weight, so only nine items can be drawn
+		sample := heap.SampleWithoutReplacement(h, 20, weight)
+		if len(sample) != 9 {
+			t.Fatalf("%s: expected 9 items, got %v", name, sample)
+		}
+		seen := map[heap.Item]bool{}
+		for _, item := range sample {
+			if item == heap.Integer(0) || seen[item] {
+				t.Fatalf("%s: unexpected sample %v", name, sample)
+			}
+			seen[item] = true
+		}
+		for i := 0; i < 10; i++ {
+			if item := h.DeleteMin(); item != heap.Integer(i) {
+				t.Fatalf("%s: expected the heap to be unchanged, got %v for %d", name, item, i)
+			}
+		}
+	}
+
+	// 9 outweighs 1 nine to one, so it is drawn first about 90% of the time
+	h := pairing.New()
+	h.Insert(heap.Integer(1))
+	h.Insert(heap.Integer(9))
+	first := 0
+	for i := 0; i < 2000; i++ {
+		sample := heap.SampleWithoutReplacement(h, 2, weight)
+		if len(sample) != 2 {
+			t.Fatalf("expected 2 items, got %v", sample)
+		}
+		if sample[0] == heap.Integer(9) {
+			first++
+		}
+	}
+	if first < 1700 || first > 1900 {
+		t.Fatalf("expected 9 to be drawn first about 1800 times, got %d", first)
+	}
+}
+
+func TestContainerHeapConversion(t *testing.T) {
+	heaps := map[string]func() heap.Interface{
+		"pairing":   func() heap.Interface { return pairing.New() },
+		"skew":      func() heap.Interface { return skew.New() },
+		"container": func() heap.Interface { return heap.FromContainer(&heap.Items{}) },
+	}
+	for name, newHeap := range heaps {
+		h := newHeap()
+		if items := heap.ToContainerHeap(h); len(items) != 0 {
+			t.Fatalf("%s: expected no items, got %v", name, items)
+		}
+		for _, v := range rand.Perm(100) {
+			h.Insert(heap.Integer(v))
+		}
+		items := heap.ToContainerHeap(h)
+		if len(items) != 100 || !heap.IsHeap(items) {
+			t.Fatalf("%s: expected 100 items in heap order, got %v", name, items)
+		}
+		// the legacy code takes the items over
+		container.Push(&items, heap.Integer(-1))
+		if container.Pop(&items) != heap.Integer(-1) {
+			t.Fatalf("%s: expected container/heap to pop -1", name)
+		}
+		container.Pop(&items)
+
+		// h is unchanged, and takes the items back
+		if h.FindMin() != heap.Integer(0) {
+			t.Fatalf("%s: expected h to keep its minimum, got %v", name, h.FindMin())
+		}
+		h.Clear()
+		heap.FromContainerHeap(items, h)
+		for want := 1; want < 100; want++ {
+			if got := h.DeleteMin(); got != heap.Integer(want) {
+				t.Fatalf("%s: expected %d, got %v", name, want, got)
+			}
+		}
+	}
+}
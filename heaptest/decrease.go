@@ -0,0 +1,73 @@
+package heaptest
+
+import (
+	"math/rand"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// DecreaseKeyer is a heap whose items can be decreased in place through a
+// handle returned when they are pushed, like the elements of the quake and
+// violation heaps. Heaps with their own handle type need a small adapter,
+// such as the ones of package decreasetest.
+type DecreaseKeyer interface {
+	Push(item heap.Item) interface{}
+	DecreaseKey(handle interface{}, item heap.Item)
+	DeleteMin() heap.Item
+}
+
+// BenchDecreaseKey runs a workload shaped like Dijkstra's shortest paths
+// on heaps returned by newHeap, which must be empty: n items are pushed,
+// then every DeleteMin is followed by d decreases of random items still in
+// the heap, until it is empty. The workload is the same for every heap.
+func BenchDecreaseKey(b *testing.B, n, d int, newHeap func() DecreaseKeyer) {
+	for i := 0; i < b.N; i++ {
+		r := rand.New(rand.NewSource(1))
+		h := newHeap()
+		handles := make([]interface{}, n)
+		keys := make([]int, n)
+		live := make([]int, n) // the ids still in the heap
+		pos := make([]int, n)  // the index of every id in live
+		for id := range handles {
+			keys[id] = n + r.Intn(n)
+			handles[id] = h.Push(keyed{keys[id], id})
+			live[id], pos[id] = id, id
+		}
+		for len(live) > 0 {
+			item, ok := h.DeleteMin().(keyed)
+			if !ok {
+				b.Fatalf("DeleteMin returned no item with %d left", len(live))
+			}
+			last := live[len(live)-1]
+			live[pos[item.id]], pos[last] = last, pos[item.id]
+			live = live[:len(live)-1]
+
+			for k := 0; k < d && len(live) > 0; k++ {
+				id := live[r.Intn(len(live))]
+				keys[id] -= 1 + r.Intn(n)
+				h.DecreaseKey(handles[id], keyed{keys[id], id})
+			}
+		}
+	}
+}
+
+// keyed is an item of BenchDecreaseKey, ordered by key then id.
+type keyed struct {
+	key, id int
+}
+
+func (k keyed) Compare(than heap.Item) int {
+	o := than.(keyed)
+	switch {
+	case k.key < o.key:
+		return -1
+	case k.key > o.key:
+		return 1
+	case k.id < o.id:
+		return -1
+	case k.id > o.id:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+// Package decreasetest adapts the quake and violation heaps to
+// heaptest.DecreaseKeyer, for tests and benchmarks comparing them. It is
+// kept apart from heaptest so that users of heaptest do not depend on
+// either heap.
+package decreasetest
+
+import (
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heaptest"
+	"github.com/theodesp/go-heaps/quake"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+// Quake adapts h to a DecreaseKeyer whose handles are its *quake.Element.
+func Quake(h *quake.QuakeHeap) heaptest.DecreaseKeyer { return quakeHeap{h} }
+
+type quakeHeap struct{ *quake.QuakeHeap }
+
+func (h quakeHeap) Push(item heap.Item) interface{} { return h.QuakeHeap.Push(item) }
+
+func (h quakeHeap) DecreaseKey(e interface{}, item heap.Item) {
+	h.QuakeHeap.DecreaseKey(e.(*quake.Element), item)
+}
+
+// Violation adapts h to a DecreaseKeyer whose handles are its
+// *violation.Element.
+func Violation(h *violation.ViolationHeap) heaptest.DecreaseKeyer { return violationHeap{h} }
+
+type violationHeap struct{ *violation.ViolationHeap }
+
+func (h violationHeap) Push(item heap.Item) interface{} { return h.ViolationHeap.Push(item) }
+
+func (h violationHeap) DecreaseKey(e interface{}, item heap.Item) {
+	h.ViolationHeap.DecreaseKey(e.(*violation.Element), item)
+}
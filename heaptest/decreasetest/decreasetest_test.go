@@ -0,0 +1,23 @@
+package decreasetest
+
+import (
+	"testing"
+
+	"github.com/theodesp/go-heaps/heaptest"
+	"github.com/theodesp/go-heaps/quake"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+func BenchmarkDecreaseKey(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		newHeap func() heaptest.DecreaseKeyer
+	}{
+		{"Quake", func() heaptest.DecreaseKeyer { return Quake(quake.New()) }},
+		{"Violation", func() heaptest.DecreaseKeyer { return Violation(violation.New()) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			heaptest.BenchDecreaseKey(b, 10000, 4, bc.newHeap)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package heaptest
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+// Diff applies ops to every heap in heaps and checks after each operation
+// that they all agree on what they observably hold. The heaps must be empty
+// and must support the kinds of ops; Delete and Adjust require the Delete
+// and Adjust methods of heap.Extended, which trace.Recorder also forwards.
+//
+// After every operation Diff compares the result of the call, except for
+// Adjust whose result differs between implementations, and the result of
+// FindMin. Heaps with a Len method must also report the number of items
+// left by the operations so far. Items are compared with Compare, so equal
+// items with different payloads match.
+//
+// The returned error describes the first disagreement, naming the operation
+// by its index in ops. Diff stops there, leaving the heaps in the state that
+// exposed it.
+func Diff(ops []Op, heaps ...heap.Interface) error {
+	if len(heaps) == 0 {
+		return nil
+	}
+	size := 0
+	results := make([]heap.Item, len(heaps))
+	for i, op := range ops {
+		for j, h := range heaps {
+			results[j] = op.Apply(h)
+		}
+		if op.Kind != Adjust {
+			if err := agree(results, heaps); err != nil {
+				return fmt.Errorf("heaptest: operation %d %v: %v", i, op, err)
+			}
+		}
+
+		switch op.Kind {
+		case Insert:
+			size++
+		case DeleteMin, Delete:
+			if results[0] != nil {
+				size--
+			}
+		}
+
+		for j, h := range heaps {
+			results[j] = h.FindMin()
+		}
+		if err := agree(results, heaps); err != nil {
+			return fmt.Errorf("heaptest: FindMin after operation %d %v: %v", i, op, err)
+		}
+		for _, h := range heaps {
+			if l, ok := h.(interface{ Len() int }); ok && l.Len() != size {
+				return fmt.Errorf("heaptest: Len after operation %d %v: %T returned %d, want %d",
+					i, op, h, l.Len(), size)
+			}
+		}
+	}
+	return nil
+}
+
+// agree returns an error if the results of heaps differ from the first one.
+func agree(results []heap.Item, heaps []heap.Interface) error {
+	for j := 1; j < len(results); j++ {
+		if !trace.Same(results[0], results[j]) {
+			return fmt.Errorf("%T returned %v, %T returned %v",
+				heaps[0], results[0], heaps[j], results[j])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package heaptest provides utilities for testing and benchmarking heap
+// implementations.
+//
+// RandomOps generates reproducible sequences of operations from a seed, so a
+// failing sequence can be reported and replayed by its seed alone.
+// BenchDecreaseKey runs the same decrease-key heavy workload on heaps with
+// handles, to compare them. MeasureStability and WriteStability report in
+// which order heaps return equal items and how many comparisons they make on
+// duplicate-heavy data; run TestStability with -v for the report of every
+// heap in this collection.
+package heaptest
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+// Kind is the kind of a heap operation. It is the operation of the events
+// of package trace, so that generated operations share one model with the
+// recorded ones.
+type Kind = trace.Op
+
+// The kinds of operations RandomOps generates.
+const (
+	Insert    = trace.Insert
+	DeleteMin = trace.DeleteMin
+	Delete    = trace.Delete
+	Adjust    = trace.Adjust
+)
+
+// Op is a single heap operation.
+type Op struct {
+	Kind Kind
+	// Item is the item to insert, delete or adjust.
+	Item heap.Item
+	// New is the new value of an adjusted item.
+	New heap.Item
+	// Want is the item a correct heap returns for DeleteMin and Delete.
+	// It is nil for the other kinds.
+	Want heap.Item
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case Insert, Delete:
+		return fmt.Sprintf("%v(%v)", op.Kind, op.Item)
+	case Adjust:
+		return fmt.Sprintf("%v(%v, %v)", op.Kind, op.Item, op.New)
+	}
+	return op.Kind.String() + "()"
+}
+
+// Apply performs op on h and returns the result of the call, like the
+// Apply method of a trace.Event. Delete and Adjust require h to have Delete
+// and Adjust methods, as heap.Extended and trace.Recorder do.
+func (op Op) Apply(h heap.Interface) heap.Item {
+	return trace.Event{Op: op.Kind, Item: op.Item, New: op.New}.Apply(h)
+}
+
+// RandomOps returns n operations generated from seed. Only operations of the
+// given kinds are generated, or of every kind when none is given. Items are
+// heap.Integer values in [0, n), so duplicates are common; DeleteMin and
+// Delete are only generated while the heap is not empty and always target
+// an item it holds, so kinds must include Insert for anything to be
+// generated. It panics on a kind other than Insert, DeleteMin, Delete and
+// Adjust.
+// The same seed, n and kinds always produce the same sequence.
+func RandomOps(seed int64, n int, kinds ...Kind) []Op {
+	if len(kinds) == 0 {
+		kinds = []Kind{Insert, DeleteMin, Delete, Adjust}
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case Insert, DeleteMin, Delete, Adjust:
+		default:
+			panic(fmt.Sprintf("heaptest: cannot generate %v operations", kind))
+		}
+	}
+	r := rand.New(rand.NewSource(seed))
+	value := func() heap.Item {
+		return heap.Integer(r.Intn(n))
+	}
+
+	var contents []int // the values held by the heap, sorted
+	ops := make([]Op, 0, n)
+	for len(ops) < n {
+		kind := kinds[r.Intn(len(kinds))]
+		if kind != Insert && len(contents) == 0 {
+			if !has(kinds, Insert) {
+				break
+			}
+			kind = Insert
+		}
+		op := Op{Kind: kind}
+		switch kind {
+		case Insert:
+			op.Item = value()
+			contents = insertSorted(contents, int(op.Item.(heap.Integer)))
+		case DeleteMin:
+			op.Want = heap.Integer(contents[0])
+			contents = contents[1:]
+		case Delete:
+			i := r.Intn(len(contents))
+			op.Item = heap.Integer(contents[i])
+			op.Want = op.Item
+			contents = append(contents[:i], contents[i+1:]...)
+		case Adjust:
+			i := r.Intn(len(contents))
+			op.Item = heap.Integer(contents[i])
+			op.New = value()
+			contents = append(contents[:i], contents[i+1:]...)
+			contents = insertSorted(contents, int(op.New.(heap.Integer)))
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func has(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func insertSorted(values []int, v int) []int {
+	i := sort.SearchInts(values, v)
+	values = append(values, 0)
+	copy(values[i+1:], values[i:])
+	values[i] = v
+	return values
+}
@@ -0,0 +1,109 @@
+package heaptest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+)
+
+func TestRandomOpsDeterministic(t *testing.T) {
+	a := RandomOps(42, 1000)
+	if !reflect.DeepEqual(a, RandomOps(42, 1000)) {
+		t.Fatal("the same seed produced different operations")
+	}
+	if reflect.DeepEqual(a, RandomOps(43, 1000)) {
+		t.Fatal("different seeds produced the same operations")
+	}
+	if len(a) != 1000 {
+		t.Fatalf("expected 1000 operations, got %d", len(a))
+	}
+}
+
+func TestRandomOpsKinds(t *testing.T) {
+	for _, op := range RandomOps(1, 1000, Insert, DeleteMin) {
+		if op.Kind != Insert && op.Kind != DeleteMin {
+			t.Fatalf("unexpected operation %v", op)
+		}
+	}
+	if ops := RandomOps(1, 10, DeleteMin); len(ops) != 0 {
+		t.Fatalf("expected no operations without Insert, got %v", ops)
+	}
+}
+
+func TestRandomOpsApply(t *testing.T) {
+	heaps := []struct {
+		h     heap.Interface
+		kinds []Kind
+	}{
+		{pairing.New(), nil},
+		{pairing.New(pairing.WithStrategy(pairing.MultiPass)), nil},
+		{leftist.New(), []Kind{Insert, DeleteMin}},
+	}
+	for _, tc := range heaps {
+		for seed := int64(0); seed < 10; seed++ {
+			for i, op := range RandomOps(seed, 500, tc.kinds...) {
+				got := op.Apply(tc.h)
+				if op.Want != nil && got != op.Want {
+					t.Fatalf("%T seed %d: operation %d %v returned %v, want %v",
+						tc.h, seed, i, op, got, op.Want)
+				}
+			}
+			tc.h.Clear()
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		err := Diff(RandomOps(seed, 500),
+			pairing.New(),
+			pairing.New(pairing.WithStrategy(pairing.MultiPass)),
+			pairing.New(pairing.WithLazyDelete(0.5)),
+			rpheap.New())
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		err = Diff(RandomOps(seed, 500, Insert, DeleteMin),
+			pairing.New(), leftist.New(), skew.New(), fibonacci.New())
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+// lossy drops every tenth inserted item.
+type lossy struct {
+	heap.Interface
+	n int
+}
+
+func (l *lossy) Insert(item heap.Item) heap.Item {
+	if l.n++; l.n%10 != 0 {
+		l.Interface.Insert(item)
+	}
+	return item
+}
+
+func TestDiffDisagreement(t *testing.T) {
+	err := Diff(RandomOps(1, 500, Insert, DeleteMin), pairing.New(), &lossy{Interface: leftist.New()})
+	if err == nil {
+		t.Fatal("expected the heaps to disagree")
+	}
+	if !strings.HasPrefix(err.Error(), "heaptest: ") || !strings.Contains(err.Error(), "*heaptest.lossy") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = Diff([]Op{{Kind: Insert, Item: heap.Integer(1)}, {Kind: DeleteMin}},
+		pairing.New(), &lossy{Interface: pairing.New(), n: 9})
+	if err == nil || !strings.Contains(err.Error(), "operation 0 Insert(1)") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package heaptest
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"text/tabwriter"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Stability is the outcome of MeasureStability on one heap.
+type Stability struct {
+	Name  string
+	Items int // number of items inserted and drained
+	Keys  int // number of distinct keys among them
+	// Displaced counts the items extracted after an item of the same key
+	// that was inserted later. It is zero for a stable heap.
+	Displaced int
+	// Comparisons counts the calls to Compare made by the heap while the
+	// items were inserted and drained.
+	Comparisons int
+}
+
+// Stable reports whether equal items came out in insertion order.
+func (s Stability) Stable() bool {
+	return s.Displaced == 0
+}
+
+// MeasureStability inserts n items into the empty heap returned by newHeap,
+// with keys drawn from [0, keys) by seed, so that most keys are repeated
+// when keys is small against n, then drains the heap and reports in which
+// order the equal items came out and how many comparisons it took. The
+// items are the same for every heap given the same n, keys and seed.
+//
+// An error is returned if the heap loses an item or extracts the keys out of
+// order.
+func MeasureStability(name string, newHeap func() heap.Interface, n, keys int, seed int64) (Stability, error) {
+	s := Stability{Name: name, Items: n}
+	r := rand.New(rand.NewSource(seed))
+	h := newHeap()
+	seen := map[int]bool{}
+	for seq := 0; seq < n; seq++ {
+		key := r.Intn(keys)
+		seen[key] = true
+		h.Insert(stamped{key: key, seq: seq, comparisons: &s.Comparisons})
+	}
+	s.Keys = len(seen)
+
+	last := map[int]int{} // the greatest seq extracted so far by key
+	prev := -1
+	for i := 0; i < n; i++ {
+		item, ok := h.DeleteMin().(stamped)
+		if !ok {
+			return s, fmt.Errorf("heaptest: %s: DeleteMin returned no item with %d left", name, n-i)
+		}
+		if item.key < prev {
+			return s, fmt.Errorf("heaptest: %s: DeleteMin returned key %d after %d", name, item.key, prev)
+		}
+		prev = item.key
+		if seq, ok := last[item.key]; ok && seq > item.seq {
+			s.Displaced++
+			continue
+		}
+		last[item.key] = item.seq
+	}
+	if item := h.DeleteMin(); item != nil {
+		return s, fmt.Errorf("heaptest: %s: DeleteMin returned %v after %d items were drained", name, item, n)
+	}
+	return s, nil
+}
+
+// WriteStability writes reports to w as a table aligned in columns, one line
+// per heap, with the comparisons averaged per item.
+func WriteStability(w io.Writer, reports []Stability) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "heap\titems\tkeys\tcomparisons/item\tdisplaced\tstable")
+	for _, s := range reports {
+		var perItem float64
+		if s.Items > 0 {
+			perItem = float64(s.Comparisons) / float64(s.Items)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%d\t%t\n",
+			s.Name, s.Items, s.Keys, perItem, s.Displaced, s.Stable())
+	}
+	return tw.Flush()
+}
+
+// stamped is an item of MeasureStability. It is ordered by key alone and
+// counts its comparisons; seq records the insertion order.
+type stamped struct {
+	key, seq    int
+	comparisons *int
+}
+
+func (s stamped) Compare(than heap.Item) int {
+	*s.comparisons++
+	o := than.(stamped)
+	switch {
+	case s.key < o.key:
+		return -1
+	case s.key > o.key:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package heaptest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/quake"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/treap"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+var stabilityHeaps = []struct {
+	name    string
+	newHeap func() heap.Interface
+}{
+	{"Pairing", func() heap.Interface { return pairing.New() }},
+	{"PairingStable", func() heap.Interface { return pairing.New(pairing.WithStable()) }},
+	{"Leftist", func() heap.Interface { return leftist.New() }},
+	{"Skew", func() heap.Interface { return skew.New() }},
+	{"Fibonacci", func() heap.Interface { return fibonacci.New() }},
+	{"Binomial", func() heap.Interface { return &binomial.BinomialHeap{} }},
+	{"RankPairing", func() heap.Interface { return rpheap.New() }},
+	{"Treap", func() heap.Interface { return treap.New() }},
+	{"Quake", func() heap.Interface { return quake.New() }},
+	{"Violation", func() heap.Interface { return violation.New() }},
+}
+
+// TestStability drains every heap of duplicate-heavy items and checks the
+// order of the keys. Run it with -v for the report.
+func TestStability(t *testing.T) {
+	for _, keys := range []int{4, 100} {
+		var reports []Stability
+		for _, sh := range stabilityHeaps {
+			s, err := MeasureStability(sh.name, sh.newHeap, 2000, keys, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Keys != keys {
+				t.Fatalf("%s: expected %d keys, got %d", sh.name, keys, s.Keys)
+			}
+			if sh.name == "PairingStable" && !s.Stable() {
+				t.Errorf("%s: %d items displaced", sh.name, s.Displaced)
+			}
+			reports = append(reports, s)
+		}
+		var buf bytes.Buffer
+		if err := WriteStability(&buf, reports); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("%d items of %d keys:\n%s", 2000, keys, buf.String())
+	}
+}
+
+// unstable returns equal items newest first.
+type unstable struct{ items []heap.Item }
+
+func (u *unstable) Insert(item heap.Item) heap.Item {
+	i := 0
+	for i < len(u.items) && u.items[i].Compare(item) < 0 {
+		i++
+	}
+	u.items = append(u.items[:i], append([]heap.Item{item}, u.items[i:]...)...)
+	return item
+}
+
+func (u *unstable) DeleteMin() heap.Item {
+	if len(u.items) == 0 {
+		return nil
+	}
+	min := u.items[0]
+	u.items = u.items[1:]
+	return min
+}
+
+func (u *unstable) FindMin() heap.Item {
+	if len(u.items) == 0 {
+		return nil
+	}
+	return u.items[0]
+}
+
+func (u *unstable) Clear() { u.items = nil }
+
+func TestMeasureStability(t *testing.T) {
+	s, err := MeasureStability("unstable", func() heap.Interface { return &unstable{} }, 10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Stable() || s.Displaced != 9 {
+		t.Fatalf("expected 9 items displaced, got %+v", s)
+	}
+	if s.Comparisons != 9 {
+		t.Fatalf("expected 9 comparisons, got %d", s.Comparisons)
+	}
+
+	_, err = MeasureStability("lossy", func() heap.Interface { return &lossy{Interface: pairing.New()} }, 100, 10, 1)
+	if err == nil || !strings.Contains(err.Error(), "no item") {
+		t.Fatalf("expected a lost item, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStability(&buf, []Stability{s}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "unstable") || !strings.HasSuffix(lines[1], "false") {
+		t.Fatalf("unexpected report %q", buf.String())
+	}
+}
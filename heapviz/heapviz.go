@@ -0,0 +1,168 @@
+// Package heapviz renders the structure of a pairing heap, to teach how
+// the heap links and consolidates its trees and to debug small
+// reproductions.
+//
+// The structure is taken from a live heap with Tree, which walks it without
+// changing it, or from a dump written by PairHeap.DumpState and attached to
+// a bug report with ReadTree. Trees are exported as Graphviz DOT with
+// WriteDOT or as JSON, and Steps replays a recorded trace, capturing the
+// tree after every operation. Handler serves a web page that steps through
+// them.
+package heapviz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+// Node is a node of a heap tree.
+type Node struct {
+	// Item is the item of the node formatted with fmt.Sprint.
+	Item string `json:"item"`
+	// Seq is the insertion sequence number of the item.
+	Seq      uint64  `json:"seq"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// Size returns the number of nodes of the tree rooted at n.
+func (n *Node) Size() int {
+	if n == nil {
+		return 0
+	}
+	size := 1
+	for _, c := range n.Children {
+		size += c.Size()
+	}
+	return size
+}
+
+// Tree returns the tree of the root of p, or nil if it has none, and the
+// forest of the trees inserted lazily that the next read of p links with
+// the root. It reads p with PairHeap.WalkState, which leaves p as it is, so
+// the items deleted lazily are skipped and stepping through a heap built
+// with pairing.WithLazyInsert shows when its forest is consolidated.
+// The complexity is O(n).
+func Tree(p *pairing.PairHeap) (root *Node, forest []*Node) {
+	var b builder
+	p.WalkState(func(s pairing.StateNode) bool {
+		b.add(s)
+		return true
+	})
+	trees := b.trees
+	if len(trees) > p.Unlinked() {
+		root, trees = trees[0], trees[1:]
+	}
+	if len(trees) > 0 {
+		forest = trees
+	}
+	return root, forest
+}
+
+// ReadTree parses a dump written by PairHeap.DumpState and returns its
+// tree, or nil if the dumped heap was empty.
+func ReadTree(r io.Reader) (*Node, error) {
+	var b builder
+	err := pairing.ReadState(r, func(s pairing.StateNode) error {
+		b.add(s)
+		return nil
+	})
+	if err != nil || len(b.trees) == 0 {
+		return nil, err
+	}
+	return b.trees[0], nil
+}
+
+// builder assembles the trees of the nodes of a walk or a dump, given in
+// depth-first order.
+type builder struct {
+	trees []*Node
+	// path holds the last node added at each depth
+	path []*Node
+}
+
+func (b *builder) add(s pairing.StateNode) {
+	n := &Node{Item: s.Item, Seq: s.Seq}
+	if s.Depth == 0 {
+		b.trees = append(b.trees, n)
+	} else {
+		parent := b.path[s.Depth-1]
+		parent.Children = append(parent.Children, n)
+	}
+	b.path = append(b.path[:s.Depth], n)
+}
+
+// WriteDOT writes the tree rooted at root and the trees of forest, as
+// returned by Tree, to w in the Graphviz DOT language. Nodes are labelled
+// with their item and sequence number and edges go from parents to
+// children, left to right. The roots of the forest are dashed.
+func WriteDOT(w io.Writer, root *Node, forest ...*Node) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph heap {")
+	fmt.Fprintln(bw, "\tnode [shape=circle];")
+	id := 0
+	var walk func(n *Node, style string) int
+	walk = func(n *Node, style string) int {
+		self := id
+		id++
+		fmt.Fprintf(bw, "\tn%d [label=%s%s];\n", self, strconv.Quote(fmt.Sprintf("%s\n#%d", n.Item, n.Seq)), style)
+		for _, c := range n.Children {
+			fmt.Fprintf(bw, "\tn%d -> n%d;\n", self, walk(c, ""))
+		}
+		return self
+	}
+	if root != nil {
+		walk(root, "")
+	}
+	for _, t := range forest {
+		walk(t, ", style=dashed")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// Step is the state of a heap after an operation of a trace.
+type Step struct {
+	// Event describes the operation. It is empty for the initial state.
+	Event string `json:"event"`
+	// Result is the item returned by the operation formatted with
+	// fmt.Sprint, or empty if it returned nil.
+	Result string `json:"result"`
+	Tree   *Node  `json:"tree"`
+	// Forest holds the trees inserted lazily and not linked yet.
+	Forest []*Node `json:"forest,omitempty"`
+}
+
+// Steps applies events to p in order and returns the state of p before the
+// first event followed by its state after each of them. The states are read
+// with Tree, so p changes only through the events.
+func Steps(p *pairing.PairHeap, events []trace.Event) []Step {
+	steps := make([]Step, 0, len(events)+1)
+	tree, forest := Tree(p)
+	steps = append(steps, Step{Tree: tree, Forest: forest})
+	for _, e := range events {
+		step := Step{Event: describe(e)}
+		if result := e.Apply(p); result != nil {
+			step.Result = fmt.Sprint(result)
+		}
+		step.Tree, step.Forest = Tree(p)
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// describe formats the operation of e without its recorded result, which
+// the replayed heap may not reproduce.
+func describe(e trace.Event) string {
+	switch e.Op {
+	case trace.Insert, trace.Delete:
+		return fmt.Sprintf("#%d %v(%v)", e.Seq, e.Op, e.Item)
+	case trace.Adjust:
+		return fmt.Sprintf("#%d %v(%v, %v)", e.Seq, e.Op, e.Item, e.New)
+	}
+	return fmt.Sprintf("#%d %v()", e.Seq, e.Op)
+}
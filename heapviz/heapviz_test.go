@@ -0,0 +1,150 @@
+package heapviz
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/trace"
+)
+
+func parseInt(s string) (heap.Item, error) {
+	i, err := strconv.Atoi(s)
+	return heap.Integer(i), err
+}
+
+func TestTree(t *testing.T) {
+	p := pairing.New()
+	if tree, forest := Tree(p); tree != nil || forest != nil {
+		t.Fatalf("expected no tree for an empty heap, got %v, %v", tree, forest)
+	}
+	for _, i := range []int{5, 3, 8, 1} {
+		p.Insert(heap.Integer(i))
+	}
+	tree, forest := Tree(p)
+	if forest != nil {
+		t.Fatalf("unexpected forest %v", forest)
+	}
+	if tree.Item != "1" || tree.Seq != 4 || tree.Size() != 4 {
+		t.Fatalf("unexpected root %s #%d of %d nodes", tree.Item, tree.Seq, tree.Size())
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, tree); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, "digraph heap {") || strings.Count(dot, "->") != 3 ||
+		!strings.Contains(dot, `n0 [label="1\n#4"];`) {
+		t.Fatalf("unexpected DOT:\n%s", dot)
+	}
+}
+
+func TestTreeLazyInsert(t *testing.T) {
+	p := pairing.New(pairing.WithLazyInsert())
+	p.Insert(heap.Integer(5))
+	p.Insert(heap.Integer(3))
+	tree, forest := Tree(p)
+	if tree != nil || len(forest) != 2 {
+		t.Fatalf("expected a forest of 2 trees, got %v and %v", tree, forest)
+	}
+	p.FindMin()
+	p.Insert(heap.Integer(1))
+	tree, forest = Tree(p)
+	if tree == nil || tree.Size() != 2 || len(forest) != 1 || forest[0].Item != "1" {
+		t.Fatalf("expected a root of 2 nodes and a forest of 1, got %v and %v", tree, forest)
+	}
+	// reading the tree does not link the forest
+	if p.Unlinked() != 1 {
+		t.Fatalf("Tree linked the forest")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, tree, forest...); err != nil {
+		t.Fatal(err)
+	}
+	if dot := buf.String(); strings.Count(dot, "style=dashed") != 1 || strings.Count(dot, "->") != 1 {
+		t.Fatalf("unexpected DOT:\n%s", dot)
+	}
+}
+
+func TestReadTreeErrors(t *testing.T) {
+	for _, dump := range []string{
+		"",
+		"binary-heap v1\n",
+		"pairing-heap v1\n1 \"1\"\n",
+		"pairing-heap v2\n0 1 \"1\"\n0 2 \"2\"\n",
+		"pairing-heap v2\n0 1 \"1\"\n2 2 \"2\"\n",
+		"pairing-heap v2\nx 1 \"1\"\n",
+		"pairing-heap v2\n1 \"1\"\n",
+	} {
+		if _, err := ReadTree(strings.NewReader(dump)); err == nil {
+			t.Errorf("expected an error reading %q", dump)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	var buf bytes.Buffer
+	r := trace.NewRecorder(pairing.New(), &buf)
+	for _, i := range []int{4, 2, 7, 1, 9} {
+		r.Insert(heap.Integer(i))
+	}
+	r.DeleteMin()
+	r.Adjust(heap.Integer(9), heap.Integer(0))
+	events, err := trace.Read(&buf, parseInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := Steps(pairing.New(), events)
+	if len(steps) != len(events)+1 || steps[0].Tree != nil || steps[0].Event != "" {
+		t.Fatalf("unexpected steps %v", steps)
+	}
+	want := []int{1, 2, 3, 4, 5, 4, 4}
+	for i, step := range steps[1:] {
+		if step.Tree.Size() != want[i] {
+			t.Errorf("step %d %s: expected %d nodes, got %d", i+1, step.Event, want[i], step.Tree.Size())
+		}
+	}
+	if last := steps[len(steps)-1]; last.Event != "#7 Adjust(9, 0)" || last.Tree.Item != "0" {
+		t.Fatalf("unexpected last step %s with root %s", last.Event, last.Tree.Item)
+	}
+	if steps[6].Result != "1" {
+		t.Fatalf("expected DeleteMin to return 1, got %q", steps[6].Result)
+	}
+
+	srv := httptest.NewServer(Handler(steps))
+	defer srv.Close()
+	res, err := srv.Client().Get(srv.URL + "/steps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Step
+	err = json.NewDecoder(res.Body).Decode(&got)
+	res.Body.Close()
+	if err != nil || len(got) != len(steps) || got[5].Tree.Size() != 5 {
+		t.Fatalf("unexpected JSON steps %v, %v", got, err)
+	}
+	for path, status := range map[string]int{
+		"/":            200,
+		"/dot":         200,
+		"/dot?step=0":  200,
+		"/dot?step=99": 400,
+		"/missing":     404,
+	} {
+		res, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, res.StatusCode)
+		}
+	}
+}
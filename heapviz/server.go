@@ -0,0 +1,151 @@
+package heapviz
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Handler returns an http.Handler serving a page that steps through steps,
+// drawing the tree of each of them. It also serves:
+//
+//	/steps          the steps as JSON
+//	/dot?step=i     the tree of step i as DOT, the last step by default
+func Handler(steps []Step) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	})
+	mux.HandleFunc("/steps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(steps)
+	})
+	mux.HandleFunc("/dot", func(w http.ResponseWriter, r *http.Request) {
+		i := len(steps) - 1
+		if s := r.URL.Query().Get("step"); s != "" {
+			var err error
+			if i, err = strconv.Atoi(s); err != nil || i < 0 || i >= len(steps) {
+				http.Error(w, fmt.Sprintf("heapviz: no step %q", s), http.StatusBadRequest)
+				return
+			}
+		}
+		if i < 0 {
+			http.Error(w, "heapviz: no steps", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		WriteDOT(w, steps[i].Tree, steps[i].Forest...)
+	})
+	return mux
+}
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>heapviz</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+#bar { margin-bottom: 1em; }
+#bar button { min-width: 3em; }
+#event { font-family: monospace; margin-left: 1em; }
+svg text { font: 12px monospace; text-anchor: middle; dominant-baseline: middle; }
+svg circle { fill: #fff; stroke: #333; }
+svg .root circle { fill: #ffe9a8; }
+svg .forest circle { stroke-dasharray: 4 3; }
+svg line { stroke: #999; }
+</style>
+</head>
+<body>
+<div id="bar">
+<button id="first">|&lt;</button>
+<button id="prev">&lt;</button>
+<button id="next">&gt;</button>
+<button id="last">&gt;|</button>
+<input id="slider" type="range" min="0" value="0">
+<span id="pos"></span>
+<span id="event"></span>
+<a id="dot" href="/dot">DOT</a>
+</div>
+<svg id="tree" xmlns="http://www.w3.org/2000/svg"></svg>
+<script>
+var steps = [], current = 0;
+var NS = "http://www.w3.org/2000/svg", DX = 44, DY = 60, R = 16;
+
+function el(name, attrs, parent) {
+	var e = document.createElementNS(NS, name);
+	for (var k in attrs) e.setAttribute(k, attrs[k]);
+	parent.appendChild(e);
+	return e;
+}
+
+// layout places leaves left to right and centers parents over their children.
+function layout(n, depth, next) {
+	n.y = depth;
+	if (!n.children || n.children.length === 0) {
+		n.x = next.x++;
+		return;
+	}
+	n.children.forEach(function (c) { layout(c, depth + 1, next); });
+	n.x = (n.children[0].x + n.children[n.children.length - 1].x) / 2;
+}
+
+function draw(n, svg, cls) {
+	(n.children || []).forEach(function (c) {
+		el("line", {x1: DX * n.x + DX, y1: DY * n.y + DY / 2, x2: DX * c.x + DX, y2: DY * c.y + DY / 2}, svg);
+		draw(c, svg, "");
+	});
+	var g = el("g", {"class": cls}, svg);
+	el("circle", {cx: DX * n.x + DX, cy: DY * n.y + DY / 2, r: R}, g);
+	el("text", {x: DX * n.x + DX, y: DY * n.y + DY / 2}, g).textContent = n.item;
+	el("title", {}, g).textContent = n.item + " #" + n.seq;
+}
+
+function show(i) {
+	if (steps.length === 0) return;
+	current = Math.max(0, Math.min(steps.length - 1, i));
+	var step = steps[current], svg = document.getElementById("tree");
+	while (svg.firstChild) svg.removeChild(svg.firstChild);
+	// the trees inserted lazily are drawn dashed right of the root
+	var trees = (step.tree ? [step.tree] : []).concat(step.forest || []);
+	var next = {x: 0}, height = 0;
+	trees.forEach(function (t, i) {
+		layout(t, 0, next);
+		(function depth(n) { height = Math.max(height, n.y + 1); (n.children || []).forEach(depth); })(t);
+		draw(t, svg, i === 0 && step.tree ? "root" : "forest");
+	});
+	var width = Math.max(next.x, 1);
+	svg.setAttribute("width", DX * (width + 1));
+	svg.setAttribute("height", DY * height);
+	document.getElementById("slider").value = current;
+	document.getElementById("pos").textContent = current + "/" + (steps.length - 1);
+	document.getElementById("event").textContent =
+		(step.event || "initial state") + (step.result ? " = " + step.result : "");
+	document.getElementById("dot").href = "/dot?step=" + current;
+}
+
+document.getElementById("first").onclick = function () { show(0); };
+document.getElementById("prev").onclick = function () { show(current - 1); };
+document.getElementById("next").onclick = function () { show(current + 1); };
+document.getElementById("last").onclick = function () { show(steps.length - 1); };
+document.getElementById("slider").oninput = function () { show(+this.value); };
+document.onkeydown = function (e) {
+	if (e.key === "ArrowLeft") show(current - 1);
+	if (e.key === "ArrowRight") show(current + 1);
+};
+
+fetch("/steps").then(function (r) { return r.json(); }).then(function (s) {
+	steps = s;
+	document.getElementById("slider").max = steps.length - 1;
+	show(0);
+});
+</script>
+</body>
+</html>
+`
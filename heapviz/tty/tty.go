@@ -0,0 +1,255 @@
+// Package tty prints the tree of a pairing heap to a terminal after every
+// operation, to debug small reproductions without Graphviz.
+//
+// A Heap wraps a pairing heap and, when enabled, renders its tree after
+// each operation, highlighting the changes since the previous rendering:
+// nodes that were inserted are green, nodes that moved under another parent
+// are yellow and items that left the heap are listed in red. The root is
+// printed in bold. The trees of the items inserted with
+// pairing.WithLazyInsert and not linked yet follow, marked unlinked; once a
+// read links them, they show up as moved. Rendering does not change the
+// heap.
+//
+//	#5 DeleteMin() = 1
+//	2 #2 moved
+//	├── 7 #3
+//	└── 4 #1
+//	- 1 #4
+//
+// Rendering is off unless the HEAPVIZ_TTY environment variable is set to a
+// value other than 0, or WithEnabled is given, so wrapped heaps can be left
+// in tests and switched on from the command line. Colors are used unless
+// HEAPVIZ_TTY is "nocolor", NO_COLOR is set or WithColor(false) is given.
+package tty
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heapviz"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// EnvVar is the environment variable that enables rendering.
+const EnvVar = "HEAPVIZ_TTY"
+
+const (
+	bold   = "\x1b[1m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	reset  = "\x1b[0m"
+)
+
+// Option configures a Renderer or a Heap.
+type Option func(*Renderer)
+
+// WithEnabled turns rendering on or off regardless of HEAPVIZ_TTY.
+func WithEnabled(enabled bool) Option {
+	return func(r *Renderer) {
+		r.enabled = enabled
+	}
+}
+
+// WithColor turns colors on or off regardless of the environment.
+func WithColor(color bool) Option {
+	return func(r *Renderer) {
+		r.color = color
+	}
+}
+
+// Renderer prints trees to a writer, highlighting the differences with
+// the tree it printed before.
+type Renderer struct {
+	w       io.Writer
+	enabled bool
+	color   bool
+	// prev maps the sequence number of each node printed last to the
+	// sequence number of its parent, or 0 for the root
+	prev  map[uint64]uint64
+	items map[uint64]string
+	err   error
+}
+
+// NewRenderer returns a Renderer printing to w.
+func NewRenderer(w io.Writer, opts ...Option) *Renderer {
+	env := os.Getenv(EnvVar)
+	_, noColor := os.LookupEnv("NO_COLOR")
+	r := &Renderer{
+		w:       w,
+		enabled: env != "" && env != "0",
+		color:   env != "nocolor" && !noColor,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Enabled returns true if the renderer prints anything.
+func (r *Renderer) Enabled() bool {
+	return r.enabled
+}
+
+// Err returns the first error encountered writing to the output.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+// Render prints title followed by tree, which is nil if the heap has no
+// root, and the trees of forest, as returned by heapviz.Tree.
+// It does nothing if the renderer is not enabled.
+func (r *Renderer) Render(title string, tree *heapviz.Node, forest ...*heapviz.Node) {
+	if !r.enabled || r.err != nil {
+		return
+	}
+	bw := bufio.NewWriter(r.w)
+	fmt.Fprintln(bw, title)
+
+	cur := make(map[uint64]uint64)
+	items := make(map[uint64]string)
+	var walk func(n *heapviz.Node, parent uint64, prefix string, last bool, depth int, root bool)
+	walk = func(n *heapviz.Node, parent uint64, prefix string, last bool, depth int, root bool) {
+		cur[n.Seq], items[n.Seq] = parent, n.Item
+		branch, indent := "", ""
+		if depth > 0 {
+			branch, indent = "├── ", "│   "
+			if last {
+				branch, indent = "└── ", "    "
+			}
+		}
+		label := fmt.Sprintf("%s #%d", n.Item, n.Seq)
+		old, seen := r.prev[n.Seq]
+		switch {
+		case r.prev == nil:
+		case !seen:
+			label = r.paint(green, label+" new")
+		case old != parent:
+			label = r.paint(yellow, label+" moved")
+		}
+		switch {
+		case depth == 0 && root:
+			label = r.paint(bold, label)
+		case depth == 0:
+			label += " unlinked"
+		}
+		fmt.Fprintf(bw, "%s%s%s\n", prefix, branch, label)
+		for i, c := range n.Children {
+			walk(c, n.Seq, prefix+indent, i == len(n.Children)-1, depth+1, root)
+		}
+	}
+	if tree == nil && len(forest) == 0 {
+		fmt.Fprintln(bw, "(empty)")
+	}
+	if tree != nil {
+		walk(tree, 0, "", true, 0, true)
+	}
+	for _, t := range forest {
+		walk(t, 0, "", true, 0, false)
+	}
+
+	var removed []uint64
+	for seq := range r.prev {
+		if _, ok := cur[seq]; !ok {
+			removed = append(removed, seq)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	for _, seq := range removed {
+		fmt.Fprintln(bw, r.paint(red, fmt.Sprintf("- %s #%d", r.items[seq], seq)))
+	}
+	fmt.Fprintln(bw)
+	r.prev, r.items = cur, items
+	r.err = bw.Flush()
+}
+
+func (r *Renderer) paint(color, s string) string {
+	if !r.color {
+		return s
+	}
+	return color + s + reset
+}
+
+// Heap is a pairing heap that renders its tree after every operation.
+// Rendering reads the tree with heapviz.Tree, which leaves the heap as it
+// is, so the heap behaves the same whether rendering is enabled or not.
+// Structure is not thread safe.
+type Heap struct {
+	p   *pairing.PairHeap
+	r   *Renderer
+	seq int
+}
+
+// Heap implements the Interface interface
+var _ heap.Interface = (*Heap)(nil)
+
+// Wrap returns a Heap operating on p and rendering to w.
+func Wrap(p *pairing.PairHeap, w io.Writer, opts ...Option) *Heap {
+	h := &Heap{p: p, r: NewRenderer(w, opts...)}
+	h.render("initial state", nil)
+	return h
+}
+
+// Heap returns the wrapped pairing heap.
+func (h *Heap) Heap() *pairing.PairHeap {
+	return h.p
+}
+
+// Renderer returns the renderer of h.
+func (h *Heap) Renderer() *Renderer {
+	return h.r
+}
+
+// Insert inserts item into the heap and returns it.
+func (h *Heap) Insert(item heap.Item) heap.Item {
+	return h.render(fmt.Sprintf("Insert(%v)", item), h.p.Insert(item))
+}
+
+// DeleteMin removes and returns the smallest item of the heap.
+func (h *Heap) DeleteMin() heap.Item {
+	return h.render("DeleteMin()", h.p.DeleteMin())
+}
+
+// FindMin returns the smallest item of the heap.
+func (h *Heap) FindMin() heap.Item {
+	return h.render("FindMin()", h.p.FindMin())
+}
+
+// Clear removes all items from the heap.
+func (h *Heap) Clear() {
+	h.p.Clear()
+	h.render("Clear()", nil)
+}
+
+// Delete removes item from the heap and returns it.
+func (h *Heap) Delete(item heap.Item) heap.Item {
+	return h.render(fmt.Sprintf("Delete(%v)", item), h.p.Delete(item))
+}
+
+// Adjust changes the item old of the heap to new.
+func (h *Heap) Adjust(old, new heap.Item) heap.Item {
+	return h.render(fmt.Sprintf("Adjust(%v, %v)", old, new), h.p.Adjust(old, new))
+}
+
+// render renders the tree of the heap after the operation described by op
+// and returns its result.
+func (h *Heap) render(op string, result heap.Item) heap.Item {
+	if !h.r.Enabled() {
+		return result
+	}
+	title := op
+	if h.seq > 0 {
+		title = fmt.Sprintf("#%d %s", h.seq, op)
+		if result != nil {
+			title += fmt.Sprintf(" = %v", result)
+		}
+	}
+	h.seq++
+	tree, forest := heapviz.Tree(h.p)
+	h.r.Render(title, tree, forest...)
+	return result
+}
@@ -0,0 +1,97 @@
+package tty
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+func TestHeap(t *testing.T) {
+	var buf bytes.Buffer
+	h := Wrap(pairing.New(), &buf, WithEnabled(true), WithColor(false))
+	for _, i := range []int{4, 2, 7, 1} {
+		h.Insert(heap.Integer(i))
+	}
+	buf.Reset()
+	h.DeleteMin()
+
+	want := `#5 DeleteMin() = 1
+2 #2 moved
+├── 7 #3
+└── 4 #1
+- 1 #4
+
+`
+	if buf.String() != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	h.Insert(heap.Integer(0))
+	if !strings.Contains(buf.String(), "0 #5 new\n└── 2 #2 moved\n") {
+		t.Fatalf("unexpected rendering\n%s", buf.String())
+	}
+	buf.Reset()
+	h.Clear()
+	if buf.String() != "#7 Clear()\n(empty)\n- 4 #1\n- 2 #2\n- 7 #3\n- 0 #5\n\n" {
+		t.Fatalf("unexpected rendering\n%s", buf.String())
+	}
+	if h.Renderer().Err() != nil {
+		t.Fatal(h.Renderer().Err())
+	}
+}
+
+func TestLazyInsert(t *testing.T) {
+	var buf bytes.Buffer
+	h := Wrap(pairing.New(pairing.WithLazyInsert()), &buf, WithEnabled(true), WithColor(false))
+	h.Insert(heap.Integer(2))
+	h.Insert(heap.Integer(1))
+	if !strings.HasSuffix(buf.String(), "#2 Insert(1) = 1\n1 #2 new unlinked\n2 #1 unlinked\n\n") {
+		t.Fatalf("unexpected rendering\n%s", buf.String())
+	}
+	if h.Heap().Unlinked() != 2 {
+		t.Fatal("rendering linked the forest")
+	}
+	buf.Reset()
+	h.FindMin()
+	if buf.String() != "#3 FindMin() = 1\n1 #2\n└── 2 #1 moved\n\n" {
+		t.Fatalf("unexpected rendering\n%s", buf.String())
+	}
+}
+
+func TestColor(t *testing.T) {
+	var buf bytes.Buffer
+	h := Wrap(pairing.New(), &buf, WithEnabled(true), WithColor(true))
+	h.Insert(heap.Integer(1))
+	h.DeleteMin()
+	out := buf.String()
+	for _, code := range []string{bold, green, red, reset} {
+		if !strings.Contains(out, code) {
+			t.Errorf("expected %q in\n%s", code, out)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	defer os.Setenv(EnvVar, os.Getenv(EnvVar))
+	for env, enabled := range map[string]bool{"": false, "0": false, "1": true, "nocolor": true} {
+		os.Setenv(EnvVar, env)
+		var buf bytes.Buffer
+		h := Wrap(pairing.New(), &buf)
+		h.Insert(heap.Integer(1))
+		if h.Renderer().Enabled() != enabled || (buf.Len() > 0) != enabled {
+			t.Errorf("%s=%q: expected enabled %v, got %v", EnvVar, env, enabled, h.Renderer().Enabled())
+		}
+		if _, noColor := os.LookupEnv("NO_COLOR"); !noColor && h.Renderer().color != (env != "nocolor") {
+			t.Errorf("%s=%q: unexpected color %v", EnvVar, env, h.Renderer().color)
+		}
+	}
+	os.Setenv(EnvVar, "1")
+	if NewRenderer(nil, WithEnabled(false)).Enabled() {
+		t.Fatal("expected WithEnabled to override the environment")
+	}
+}
@@ -0,0 +1,234 @@
+// Package indexpq implements an indexed priority queue over a fixed index
+// space, the IndexMinPQ of Sedgewick and Wayne's Algorithms.
+//
+// Every index in [0, n) is associated with at most one key, and the key of
+// an index can be changed in place. This is the queue expected by graph
+// algorithms such as Dijkstra's and Prim's, where indexes are vertices and
+// keys are tentative distances.
+//
+// Keys are kept in an array-backed binary heap together with the inverse
+// permutation, so looking up the position of an index is O(1).
+//
+// Structure is not thread safe.
+//
+// Reference: https://algs4.cs.princeton.edu/24pq/IndexMinPQ.java.html
+package indexpq
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// IndexMinPQ is a min priority queue of indexes in [0, n) ordered by their
+// keys.
+type IndexMinPQ struct {
+	pq   []int       // binary heap of indexes
+	qp   []int       // position of each index in pq, or -1
+	keys []heap.Item // key of each index
+}
+
+// New returns an empty IndexMinPQ for the indexes 0 to n-1.
+func New(n int) *IndexMinPQ {
+	if n < 0 {
+		panic(fmt.Sprintf("indexpq: negative capacity %d", n))
+	}
+	q := &IndexMinPQ{
+		pq:   make([]int, 0, n),
+		qp:   make([]int, n),
+		keys: make([]heap.Item, n),
+	}
+	for i := range q.qp {
+		q.qp[i] = -1
+	}
+	return q
+}
+
+// Cap returns the size of the index space.
+func (q *IndexMinPQ) Cap() int {
+	return len(q.qp)
+}
+
+// Len returns the number of indexes in the queue.
+func (q *IndexMinPQ) Len() int {
+	return len(q.pq)
+}
+
+// IsEmpty returns true if the queue holds no index.
+func (q *IndexMinPQ) IsEmpty() bool {
+	return len(q.pq) == 0
+}
+
+// Contains returns true if i is in the queue.
+// The complexity is O(1).
+func (q *IndexMinPQ) Contains(i int) bool {
+	q.check(i)
+	return q.qp[i] != -1
+}
+
+// Insert associates key with index i. It panics if i is already in the
+// queue.
+// The complexity is O(log n).
+func (q *IndexMinPQ) Insert(i int, key heap.Item) {
+	if q.Contains(i) {
+		panic(fmt.Sprintf("indexpq: index %d is already in the queue", i))
+	}
+	q.qp[i] = len(q.pq)
+	q.pq = append(q.pq, i)
+	q.keys[i] = key
+	q.up(q.qp[i])
+}
+
+// MinIndex returns the index associated with the smallest key. It panics if
+// the queue is empty.
+// The complexity is O(1).
+func (q *IndexMinPQ) MinIndex() int {
+	q.checkNotEmpty()
+	return q.pq[0]
+}
+
+// MinKey returns the smallest key. It panics if the queue is empty.
+// The complexity is O(1).
+func (q *IndexMinPQ) MinKey() heap.Item {
+	q.checkNotEmpty()
+	return q.keys[q.pq[0]]
+}
+
+// DelMin removes the smallest key and returns its index. It panics if the
+// queue is empty.
+// The complexity is O(log n).
+func (q *IndexMinPQ) DelMin() int {
+	q.checkNotEmpty()
+	min := q.pq[0]
+	q.remove(0)
+	return min
+}
+
+// KeyOf returns the key associated with index i. It panics if i is not in
+// the queue.
+// The complexity is O(1).
+func (q *IndexMinPQ) KeyOf(i int) heap.Item {
+	q.checkContains(i)
+	return q.keys[i]
+}
+
+// ChangeKey changes the key associated with index i to key. It panics if i
+// is not in the queue.
+// The complexity is O(log n).
+func (q *IndexMinPQ) ChangeKey(i int, key heap.Item) {
+	q.checkContains(i)
+	q.keys[i] = key
+	q.up(q.qp[i])
+	q.down(q.qp[i])
+}
+
+// DecreaseKey decreases the key associated with index i to key. It panics
+// if i is not in the queue or key is greater than its current key.
+// The complexity is O(log n).
+func (q *IndexMinPQ) DecreaseKey(i int, key heap.Item) {
+	q.checkContains(i)
+	if key.Compare(q.keys[i]) > 0 {
+		panic(fmt.Sprintf("indexpq: DecreaseKey(%d) from %v to greater key %v", i, q.keys[i], key))
+	}
+	q.keys[i] = key
+	q.up(q.qp[i])
+}
+
+// IncreaseKey increases the key associated with index i to key. It panics
+// if i is not in the queue or key is less than its current key.
+// The complexity is O(log n).
+func (q *IndexMinPQ) IncreaseKey(i int, key heap.Item) {
+	q.checkContains(i)
+	if key.Compare(q.keys[i]) < 0 {
+		panic(fmt.Sprintf("indexpq: IncreaseKey(%d) from %v to smaller key %v", i, q.keys[i], key))
+	}
+	q.keys[i] = key
+	q.down(q.qp[i])
+}
+
+// Delete removes index i and its key. It panics if i is not in the queue.
+// The complexity is O(log n).
+func (q *IndexMinPQ) Delete(i int) {
+	q.checkContains(i)
+	q.remove(q.qp[i])
+}
+
+// Clear removes all indexes from the queue.
+func (q *IndexMinPQ) Clear() {
+	for _, i := range q.pq {
+		q.qp[i] = -1
+		q.keys[i] = nil
+	}
+	q.pq = q.pq[:0]
+}
+
+// remove removes the index at position pos of the heap.
+func (q *IndexMinPQ) remove(pos int) {
+	i := q.pq[pos]
+	last := len(q.pq) - 1
+	q.swap(pos, last)
+	q.pq = q.pq[:last]
+	q.qp[i] = -1
+	q.keys[i] = nil
+	if pos < last {
+		q.up(pos)
+		q.down(pos)
+	}
+}
+
+func (q *IndexMinPQ) less(a, b int) bool {
+	return q.keys[q.pq[a]].Compare(q.keys[q.pq[b]]) < 0
+}
+
+func (q *IndexMinPQ) swap(a, b int) {
+	q.pq[a], q.pq[b] = q.pq[b], q.pq[a]
+	q.qp[q.pq[a]] = a
+	q.qp[q.pq[b]] = b
+}
+
+func (q *IndexMinPQ) up(pos int) {
+	for pos > 0 {
+		parent := (pos - 1) / 2
+		if !q.less(pos, parent) {
+			break
+		}
+		q.swap(pos, parent)
+		pos = parent
+	}
+}
+
+func (q *IndexMinPQ) down(pos int) {
+	n := len(q.pq)
+	for {
+		child := 2*pos + 1
+		if child >= n {
+			break
+		}
+		if right := child + 1; right < n && q.less(right, child) {
+			child = right
+		}
+		if !q.less(child, pos) {
+			break
+		}
+		q.swap(pos, child)
+		pos = child
+	}
+}
+
+func (q *IndexMinPQ) check(i int) {
+	if i < 0 || i >= len(q.qp) {
+		panic(fmt.Sprintf("indexpq: index %d out of range [0, %d)", i, len(q.qp)))
+	}
+}
+
+func (q *IndexMinPQ) checkContains(i int) {
+	if !q.Contains(i) {
+		panic(fmt.Sprintf("indexpq: index %d is not in the queue", i))
+	}
+}
+
+func (q *IndexMinPQ) checkNotEmpty() {
+	if q.IsEmpty() {
+		panic("indexpq: queue is empty")
+	}
+}
@@ -0,0 +1,121 @@
+package indexpq
+
+import (
+	"math/rand"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestIndexMinPQOrder(t *testing.T) {
+	q := New(100)
+	for i, k := range rand.Perm(100) {
+		q.Insert(i, heap.Integer(k))
+	}
+	if q.Len() != 100 || q.Cap() != 100 {
+		t.Fatalf("unexpected size %d of %d", q.Len(), q.Cap())
+	}
+
+	for k := 0; k < 100; k++ {
+		if q.MinKey() != heap.Integer(k) {
+			t.Fatalf("expected min key %d, got %v", k, q.MinKey())
+		}
+		i := q.MinIndex()
+		if q.DelMin() != i || q.Contains(i) {
+			t.Fatalf("index %d was not removed", i)
+		}
+	}
+	if !q.IsEmpty() {
+		t.Fail()
+	}
+}
+
+func TestIndexMinPQChangeKey(t *testing.T) {
+	q := New(10)
+	for i := 0; i < 10; i++ {
+		q.Insert(i, heap.Integer(10*i))
+	}
+
+	q.DecreaseKey(7, heap.Integer(-1))
+	q.IncreaseKey(0, heap.Integer(55))
+	q.ChangeKey(3, heap.Integer(95))
+	q.Delete(5)
+	if q.Contains(5) || q.KeyOf(0) != heap.Integer(55) {
+		t.Fail()
+	}
+
+	want := []int{7, 1, 2, 4, 0, 6, 8, 9, 3}
+	for _, i := range want {
+		if got := q.DelMin(); got != i {
+			t.Fatalf("expected index %d, got %d", i, got)
+		}
+	}
+
+	// indexes can be reused once removed
+	q.Insert(5, heap.Integer(1))
+	q.Insert(7, heap.Integer(0))
+	q.Clear()
+	if !q.IsEmpty() || q.Contains(7) {
+		t.Fail()
+	}
+	q.Insert(7, heap.Integer(0))
+}
+
+func TestIndexMinPQRandom(t *testing.T) {
+	const n = 50
+	q := New(n)
+	keys := map[int]int{}
+	r := rand.New(rand.NewSource(1))
+	for step := 0; step < 10000; step++ {
+		i := r.Intn(n)
+		k := r.Intn(1000)
+		switch {
+		case !q.Contains(i):
+			q.Insert(i, heap.Integer(k))
+			keys[i] = k
+		case r.Intn(3) == 0:
+			q.Delete(i)
+			delete(keys, i)
+		default:
+			q.ChangeKey(i, heap.Integer(k))
+			keys[i] = k
+		}
+
+		if len(keys) != q.Len() {
+			t.Fatalf("expected %d indexes, got %d", len(keys), q.Len())
+		}
+		min := -1
+		for _, k := range keys {
+			if min == -1 || k < min {
+				min = k
+			}
+		}
+		if q.MinKey() != heap.Integer(min) || keys[q.MinIndex()] != min {
+			t.Fatalf("step %d: expected min key %d, got %v", step, min, q.MinKey())
+		}
+	}
+}
+
+func TestIndexMinPQPanics(t *testing.T) {
+	q := New(3)
+	q.Insert(0, heap.Integer(5))
+	for name, fn := range map[string]func(){
+		"out of range":      func() { q.Contains(3) },
+		"negative index":    func() { q.Insert(-1, heap.Integer(0)) },
+		"duplicate":         func() { q.Insert(0, heap.Integer(0)) },
+		"missing":           func() { q.KeyOf(1) },
+		"decrease to more":  func() { q.DecreaseKey(0, heap.Integer(6)) },
+		"increase to less":  func() { q.IncreaseKey(0, heap.Integer(4)) },
+		"empty":             func() { New(1).DelMin() },
+		"negative capacity": func() { New(-1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,229 @@
+// Package inherit implements a task queue with priority inheritance.
+//
+// Tasks are pushed with a priority and the keys of the tasks they depend
+// on. A task is blocked until every task it depends on has been popped or
+// removed, and only ready tasks are popped. To keep a blocked urgent task
+// from waiting behind unrelated work, every task inherits the priority of
+// the tasks that depend on it, directly or transitively:
+//
+//	effective(t) = min(priority(t), effective(d) for each dependent d of t)
+//
+// so the task holding up an urgent one is boosted as urgent as it. Removing
+// a dependent or lowering its priority takes the boost back, which is the
+// part that is easy to get wrong on top of a raw heap.
+//
+// Ready tasks are kept in a pairing heap keyed on their effective priority,
+// boosts adjusting their entries in place. Priorities are compared with
+// Compare, lower first, and ties are broken in order of push.
+//
+// Structure is not thread safe.
+package inherit
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+type task struct {
+	key        interface{}
+	id         uint64 // breaks ties between tasks, in order of push
+	priority   heap.Item
+	effective  heap.Item
+	deps       map[*task]bool // queued tasks this one waits for
+	dependents map[*task]bool // queued tasks waiting for this one
+}
+
+func (t *task) ready() bool {
+	return len(t.deps) == 0
+}
+
+// entry is the heap item of a ready task.
+type entry struct {
+	priority heap.Item
+	task     *task
+}
+
+func (e entry) Compare(than heap.Item) int {
+	o := than.(entry)
+	if c := e.priority.Compare(o.priority); c != 0 {
+		return c
+	}
+	switch {
+	case e.task.id < o.task.id:
+		return -1
+	case e.task.id > o.task.id:
+		return 1
+	}
+	return 0
+}
+
+// Queue is a task queue with priority inheritance.
+type Queue struct {
+	tasks  map[interface{}]*task
+	heap   *pairing.PairHeap
+	nextID uint64
+}
+
+// New returns an empty Queue.
+func New() *Queue {
+	return &Queue{
+		tasks: make(map[interface{}]*task),
+		heap:  pairing.New(),
+	}
+}
+
+// Len returns the number of queued tasks, ready or blocked.
+func (q *Queue) Len() int {
+	return len(q.tasks)
+}
+
+// Ready returns the number of tasks that are not blocked.
+func (q *Queue) Ready() int {
+	return q.heap.Len()
+}
+
+// Push queues the task key with priority, blocked until the tasks deps are
+// popped or removed. Keys in deps that are not queued are taken as done.
+// It panics if key is already queued.
+// The complexity is O(d) plus O(n) for each task boosted, as adjusting a
+// heap entry looks it up.
+func (q *Queue) Push(key interface{}, priority heap.Item, deps ...interface{}) {
+	if _, ok := q.tasks[key]; ok {
+		panic(fmt.Sprintf("inherit: task %v is already queued", key))
+	}
+	t := &task{
+		key:        key,
+		id:         q.nextID,
+		priority:   priority,
+		effective:  priority,
+		deps:       make(map[*task]bool),
+		dependents: make(map[*task]bool),
+	}
+	q.nextID++
+	q.tasks[key] = t
+	for _, key := range deps {
+		if d, ok := q.tasks[key]; ok && d != t {
+			t.deps[d] = true
+			d.dependents[t] = true
+			q.update(d)
+		}
+	}
+	if t.ready() {
+		q.heap.Insert(entry{priority: t.effective, task: t})
+	}
+}
+
+// Peek returns the ready task Pop would return and its effective priority
+// without removing it. ok is false if the queue is empty.
+// The complexity is O(1).
+func (q *Queue) Peek() (key interface{}, priority heap.Item, ok bool) {
+	if q.heap.IsEmpty() {
+		return nil, nil, false
+	}
+	e := q.heap.FindMin().(entry)
+	return e.task.key, e.priority, true
+}
+
+// Pop removes and returns the ready task with the lowest effective
+// priority, together with that priority, and unblocks the tasks that were
+// waiting only for it. ok is false if the queue is empty; a queue that is
+// not empty always holds a ready task.
+// The complexity is O(log n) amortized plus O(k) for k dependents.
+func (q *Queue) Pop() (key interface{}, priority heap.Item, ok bool) {
+	if q.heap.IsEmpty() {
+		return nil, nil, false
+	}
+	e := q.heap.DeleteMin().(entry)
+	q.release(e.task)
+	return e.task.key, e.priority, true
+}
+
+// Priority returns the priority key was pushed with and the effective
+// priority it inherits from its dependents. ok is false if key is not
+// queued.
+// The complexity is O(1).
+func (q *Queue) Priority(key interface{}) (priority, effective heap.Item, ok bool) {
+	t, ok := q.tasks[key]
+	if !ok {
+		return nil, nil, false
+	}
+	return t.priority, t.effective, true
+}
+
+// Blocked returns true if key is queued and waits for another task.
+// The complexity is O(1).
+func (q *Queue) Blocked(key interface{}) bool {
+	t, ok := q.tasks[key]
+	return ok && !t.ready()
+}
+
+// SetPriority changes the priority of key, boosting or releasing the tasks
+// it depends on accordingly. It returns false if key is not queued.
+// The complexity is O(n) for each task whose effective priority changes.
+func (q *Queue) SetPriority(key interface{}, priority heap.Item) bool {
+	t, ok := q.tasks[key]
+	if !ok {
+		return false
+	}
+	t.priority = priority
+	q.update(t)
+	return true
+}
+
+// Remove removes key from the queue without running it. The tasks it
+// depends on lose the priority they inherited from it and the tasks
+// waiting for it are unblocked as if it was popped. It returns false if
+// key is not queued.
+// The complexity is O(n) in the worst case.
+func (q *Queue) Remove(key interface{}) bool {
+	t, ok := q.tasks[key]
+	if !ok {
+		return false
+	}
+	if t.ready() {
+		q.heap.Delete(entry{priority: t.effective, task: t})
+	}
+	for d := range t.deps {
+		delete(d.dependents, t)
+		q.update(d)
+	}
+	q.release(t)
+	return true
+}
+
+// release forgets t, which left the queue, and unblocks its dependents.
+func (q *Queue) release(t *task) {
+	delete(q.tasks, t.key)
+	for d := range t.dependents {
+		delete(d.deps, t)
+		if d.ready() {
+			q.heap.Insert(entry{priority: d.effective, task: d})
+		}
+	}
+	t.deps, t.dependents = nil, nil
+}
+
+// update recomputes the effective priority of t and, if it changed,
+// adjusts its heap entry and updates the tasks it depends on in turn.
+// Dependencies form a DAG, as a task can only depend on tasks queued before
+// it, so the propagation ends.
+func (q *Queue) update(t *task) {
+	effective := t.priority
+	for d := range t.dependents {
+		if d.effective.Compare(effective) < 0 {
+			effective = d.effective
+		}
+	}
+	if effective.Compare(t.effective) == 0 {
+		return
+	}
+	if t.ready() {
+		q.heap.Adjust(entry{priority: t.effective, task: t}, entry{priority: effective, task: t})
+	}
+	t.effective = effective
+	for d := range t.deps {
+		q.update(d)
+	}
+}
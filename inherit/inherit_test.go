@@ -0,0 +1,185 @@
+package inherit
+
+import (
+	"math/rand"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestInheritance(t *testing.T) {
+	q := New()
+	if _, _, ok := q.Pop(); ok {
+		t.Fatal("expected nothing from an empty queue")
+	}
+
+	q.Push("low", heap.Integer(10))
+	q.Push("medium", heap.Integer(5))
+	q.Push("high", heap.Integer(1), "low")
+	if !q.Blocked("high") || q.Ready() != 2 || q.Len() != 3 {
+		t.Fatalf("unexpected state: %d ready of %d", q.Ready(), q.Len())
+	}
+	if p, e, _ := q.Priority("low"); p != heap.Integer(10) || e != heap.Integer(1) {
+		t.Fatalf("expected low to inherit 1, got %v, %v", p, e)
+	}
+
+	want := []struct {
+		key      string
+		priority int
+	}{{"low", 1}, {"high", 1}, {"medium", 5}}
+	for _, w := range want {
+		key, priority, ok := q.Pop()
+		if !ok || key != w.key || priority != heap.Integer(w.priority) {
+			t.Fatalf("expected %s at %d, got %v at %v", w.key, w.priority, key, priority)
+		}
+	}
+}
+
+func TestTransitiveAndRelease(t *testing.T) {
+	q := New()
+	q.Push("a", heap.Integer(9))
+	q.Push("b", heap.Integer(8), "a")
+	q.Push("c", heap.Integer(7))
+	q.Push("d", heap.Integer(1), "b", "missing")
+	if _, e, _ := q.Priority("a"); e != heap.Integer(1) {
+		t.Fatalf("expected a to inherit 1 through b, got %v", e)
+	}
+	if key, _, _ := q.Peek(); key != "a" {
+		t.Fatalf("expected a first, got %v", key)
+	}
+
+	// removing d takes the boost back from b and a
+	if !q.Remove("d") || q.Remove("d") {
+		t.Fatal("expected d to be removed once")
+	}
+	if _, e, _ := q.Priority("a"); e != heap.Integer(8) {
+		t.Fatalf("expected a to inherit 8 from b, got %v", e)
+	}
+	if key, _, _ := q.Peek(); key != "c" {
+		t.Fatalf("expected c first, got %v", key)
+	}
+
+	// raising b boosts a again, lowering it releases a
+	q.SetPriority("b", heap.Integer(0))
+	if key, p, _ := q.Peek(); key != "a" || p != heap.Integer(0) {
+		t.Fatalf("expected a at 0, got %v at %v", key, p)
+	}
+	q.SetPriority("b", heap.Integer(20))
+	if key, _, _ := q.Peek(); key != "c" {
+		t.Fatalf("expected c first, got %v", key)
+	}
+
+	// removing a blocker unblocks its dependents
+	q.Remove("a")
+	if q.Blocked("b") || q.Ready() != 2 {
+		t.Fatal("expected b to be ready")
+	}
+	if q.SetPriority("a", heap.Integer(0)) {
+		t.Fatal("expected no priority change for a removed task")
+	}
+}
+
+func TestPushDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	q := New()
+	q.Push(1, heap.Integer(1))
+	q.Push(1, heap.Integer(2))
+}
+
+// model recomputes effective priorities from scratch.
+type model struct {
+	priority map[int]int
+	deps     map[int][]int
+	order    []int
+}
+
+func (m *model) effective(key int) int {
+	e := m.priority[key]
+	for _, k := range m.order {
+		for _, d := range m.deps[k] {
+			if d == key {
+				if x := m.effective(k); x < e {
+					e = x
+				}
+			}
+		}
+	}
+	return e
+}
+
+func (m *model) next() int {
+	best := -1
+	for _, k := range m.order {
+		if len(m.deps[k]) > 0 {
+			continue
+		}
+		if best == -1 || m.effective(k) < m.effective(best) {
+			best = k
+		}
+	}
+	return best
+}
+
+func (m *model) remove(key int) {
+	for i, k := range m.order {
+		if k == key {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	for _, k := range m.order {
+		deps := m.deps[k][:0]
+		for _, d := range m.deps[k] {
+			if d != key {
+				deps = append(deps, d)
+			}
+		}
+		m.deps[k] = deps
+	}
+	delete(m.priority, key)
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := New()
+	m := &model{priority: map[int]int{}, deps: map[int][]int{}}
+	next := 0
+	for step := 0; step < 3000; step++ {
+		switch op := r.Intn(10); {
+		case op < 5 || len(m.order) == 0:
+			var deps []interface{}
+			for _, k := range m.order {
+				if r.Intn(len(m.order)) < 2 {
+					deps = append(deps, k)
+					m.deps[next] = append(m.deps[next], k)
+				}
+			}
+			m.priority[next] = r.Intn(100)
+			m.order = append(m.order, next)
+			q.Push(next, heap.Integer(m.priority[next]), deps...)
+			next++
+		case op < 8:
+			key, p, ok := q.Pop()
+			want := m.next()
+			if !ok || key != want || p != heap.Integer(m.effective(want)) {
+				t.Fatalf("step %d: expected %d at %d, got %v at %v", step, want, m.effective(want), key, p)
+			}
+			m.remove(want)
+		case op < 9:
+			key := m.order[r.Intn(len(m.order))]
+			m.priority[key] = r.Intn(100)
+			q.SetPriority(key, heap.Integer(m.priority[key]))
+		default:
+			key := m.order[r.Intn(len(m.order))]
+			q.Remove(key)
+			m.remove(key)
+		}
+		if q.Len() != len(m.order) {
+			t.Fatalf("step %d: expected %d tasks, got %d", step, len(m.order), q.Len())
+		}
+	}
+}
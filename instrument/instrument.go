@@ -0,0 +1,232 @@
+// Package instrument measures the cost of every operation performed on a
+// heap, to compare implementations against their theoretical bounds.
+//
+// A Heap wraps another heap and records, for each operation, the number of
+// items afterwards, the number of item comparisons it made, its duration
+// and, for heaps implementing Potential, the quantities of their potential
+// function. The records can be exported as CSV for plotting, for example to
+// check that DeleteMin comparisons grow with log n while Insert stays flat.
+//
+// Comparisons are counted by wrapping every item handed to the heap, so
+// Items returned by the heap are unwrapped again and callers see their own
+// values. Structure is not thread safe.
+package instrument
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Potential is implemented by heaps that report the quantities their
+// amortized analysis is based on, such as the number of trees in the root
+// list or the number of marked nodes, by name.
+type Potential interface {
+	Potential() map[string]int
+}
+
+// Record holds the cost of a single operation.
+type Record struct {
+	Op string
+	// Size is the number of items after the operation.
+	Size        int
+	Comparisons int
+	Duration    time.Duration
+	// Potential holds the values reported by the heap after the operation,
+	// or nil if it does not implement Potential.
+	Potential map[string]int
+}
+
+// Heap records the cost of the operations performed on a heap.
+type Heap struct {
+	h           heap.Interface
+	comparisons int
+	size        int
+	records     []Record
+	melded      *Heap // the heap this one was melded into, if any
+}
+
+// Heap implements the Extended interface
+var _ heap.Extended = (*Heap)(nil)
+
+// New returns a Heap recording the operations on h, which must be empty.
+func New(h heap.Interface) *Heap {
+	return &Heap{h: h}
+}
+
+// Heap returns the underlying heap. Its items are wrapped.
+func (h *Heap) Heap() heap.Interface {
+	return h.h
+}
+
+// Insert inserts item into the heap and returns it.
+func (h *Heap) Insert(item heap.Item) heap.Item {
+	h.measure("Insert", func() heap.Item {
+		h.h.Insert(h.wrap(item))
+		return item
+	}, 1)
+	return item
+}
+
+// DeleteMin removes and returns the smallest item of the heap.
+func (h *Heap) DeleteMin() heap.Item {
+	return h.measure("DeleteMin", func() heap.Item {
+		return unwrap(h.h.DeleteMin())
+	}, -1)
+}
+
+// FindMin returns the smallest item of the heap.
+func (h *Heap) FindMin() heap.Item {
+	return h.measure("FindMin", func() heap.Item {
+		return unwrap(h.h.FindMin())
+	}, 0)
+}
+
+// Clear removes all items from the heap.
+func (h *Heap) Clear() {
+	h.measure("Clear", func() heap.Item {
+		h.h.Clear()
+		h.size = 0
+		return nil
+	}, 0)
+}
+
+// Delete removes item from the heap and returns it. The heap must implement
+// heap.Extended.
+func (h *Heap) Delete(item heap.Item) heap.Item {
+	return h.measure("Delete", func() heap.Item {
+		return unwrap(h.extended("Delete").Delete(h.wrap(item)))
+	}, -1)
+}
+
+// Adjust changes the value of item old to new and returns the result of the
+// heap's Adjust. The heap must implement heap.Extended.
+func (h *Heap) Adjust(old, new heap.Item) heap.Item {
+	return h.measure("Adjust", func() heap.Item {
+		return unwrap(h.extended("Adjust").Adjust(h.wrap(old), h.wrap(new)))
+	}, 0)
+}
+
+// Meld melds the heap underlying a, which must be a *Heap, into the heap
+// underlying h and returns h. The comparisons made by the items of a are
+// counted by h from then on, including those of the meld itself. The heap
+// must implement heap.Extended.
+func (h *Heap) Meld(a heap.Interface) heap.Interface {
+	other, ok := a.(*Heap)
+	if !ok {
+		panic(fmt.Sprintf("instrument: unexpected type %T", a))
+	}
+	h.measure("Meld", func() heap.Item {
+		other.melded = h
+		h.extended("Meld").Meld(other.h)
+		h.size += other.size
+		other.size = 0
+		return nil
+	}, 0)
+	return h
+}
+
+func (h *Heap) extended(op string) heap.Extended {
+	x, ok := h.h.(heap.Extended)
+	if !ok {
+		panic(fmt.Sprintf("instrument: %s requires heap.Extended, got %T", op, h.h))
+	}
+	return x
+}
+
+// measure runs op and records its cost. delta is the change in size when op
+// returns an item.
+func (h *Heap) measure(name string, op func() heap.Item, delta int) heap.Item {
+	h.comparisons = 0
+	start := time.Now()
+	result := op()
+	r := Record{Op: name, Comparisons: h.comparisons, Duration: time.Since(start)}
+	if result != nil {
+		h.size += delta
+	}
+	r.Size = h.size
+	if p, ok := h.h.(Potential); ok {
+		r.Potential = p.Potential()
+	}
+	h.records = append(h.records, r)
+	return result
+}
+
+// Records returns the records of the operations since the Heap was created
+// or last reset.
+func (h *Heap) Records() []Record {
+	return h.records
+}
+
+// Reset discards the records. The heap is left as is.
+func (h *Heap) Reset() {
+	h.records = nil
+}
+
+// WriteCSV writes the records to w as CSV with a header line. The columns
+// are op, size, comparisons and nanos, followed by one column per potential
+// quantity in alphabetical order.
+func (h *Heap) WriteCSV(w io.Writer) error {
+	names := map[string]bool{}
+	for _, r := range h.records {
+		for name := range r.Potential {
+			names[name] = true
+		}
+	}
+	potential := make([]string, 0, len(names))
+	for name := range names {
+		potential = append(potential, name)
+	}
+	sort.Strings(potential)
+
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"op", "size", "comparisons", "nanos"}, potential...))
+	for _, r := range h.records {
+		row := []string{
+			r.Op,
+			strconv.Itoa(r.Size),
+			strconv.Itoa(r.Comparisons),
+			strconv.FormatInt(int64(r.Duration), 10),
+		}
+		for _, name := range potential {
+			row = append(row, strconv.Itoa(r.Potential[name]))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// counted wraps the items handed to the heap to count their comparisons.
+type counted struct {
+	item heap.Item
+	h    *Heap
+}
+
+func (c counted) Compare(than heap.Item) int {
+	h := c.h
+	for h.melded != nil {
+		h = h.melded
+	}
+	h.comparisons++
+	return c.item.Compare(unwrap(than))
+}
+
+func (h *Heap) wrap(item heap.Item) heap.Item {
+	if item == nil {
+		return nil
+	}
+	return counted{item: item, h: h}
+}
+
+func unwrap(item heap.Item) heap.Item {
+	if c, ok := item.(counted); ok {
+		return c.item
+	}
+	return item
+}
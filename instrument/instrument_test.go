@@ -0,0 +1,134 @@
+package instrument
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/heaptest"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+func TestRecords(t *testing.T) {
+	h := New(pairing.New())
+	ops := heaptest.RandomOps(1, 1000)
+	for i, op := range ops {
+		if got := op.Apply(h); op.Want != nil && got != op.Want {
+			t.Fatalf("operation %d %v returned %v, want %v", i, op, got, op.Want)
+		}
+	}
+	h.FindMin()
+	h.Clear()
+
+	records := h.Records()
+	if len(records) != len(ops)+2 {
+		t.Fatalf("expected %d records, got %d", len(ops)+2, len(records))
+	}
+	size, comparisons := 0, 0
+	for i, op := range ops {
+		r := records[i]
+		switch op.Kind {
+		case heaptest.Insert:
+			size++
+		case heaptest.DeleteMin, heaptest.Delete:
+			size--
+		}
+		if r.Op != op.Kind.String() || r.Size != size {
+			t.Fatalf("record %d: expected %v at size %d, got %+v", i, op, size, r)
+		}
+		if r.Potential == nil {
+			t.Fatalf("record %d: expected the potential of the pairing heap", i)
+		}
+		comparisons += r.Comparisons
+	}
+	if comparisons == 0 {
+		t.Fatal("no comparison was counted")
+	}
+	if r := records[len(records)-1]; r.Op != "Clear" || r.Size != 0 {
+		t.Fatalf("unexpected last record %+v", r)
+	}
+
+	other := New(pairing.New())
+	other.Insert(heap.Integer(1))
+	other.Insert(heap.Integer(2))
+	h.Insert(heap.Integer(3))
+	h.Meld(other)
+	if r := h.Records()[len(h.Records())-1]; r.Op != "Meld" || r.Size != 3 || r.Comparisons == 0 {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if h.DeleteMin() != heap.Integer(1) {
+		t.Fail()
+	}
+
+	h.Reset()
+	if len(h.Records()) != 0 {
+		t.Fail()
+	}
+}
+
+// TestDeleteMinBound checks that the comparisons of DeleteMin on a pairing
+// heap stay within a few times log2 n on average.
+func TestDeleteMinBound(t *testing.T) {
+	const n = 1 << 14
+	h := New(pairing.New())
+	for i := 0; i < n; i++ {
+		h.Insert(heap.Integer((i * 7919) % n))
+	}
+	h.Reset()
+	for i := 0; i < n; i++ {
+		h.DeleteMin()
+	}
+	total := 0
+	for _, r := range h.Records() {
+		total += r.Comparisons
+	}
+	if avg := float64(total) / n; avg > 4*math.Log2(n) {
+		t.Fatalf("DeleteMin made %.1f comparisons on average for %d items", avg, n)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	h := New(fibonacci.New())
+	for _, v := range []int{3, 1, 2} {
+		h.Insert(heap.Integer(v))
+	}
+	h.DeleteMin()
+
+	var buf bytes.Buffer
+	if err := h.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []string{"op", "size", "comparisons", "nanos", "marked", "potential", "trees"}
+	if len(rows) != 5 || len(rows[0]) != len(header) {
+		t.Fatalf("unexpected CSV %v", rows)
+	}
+	for i, name := range header {
+		if rows[0][i] != name {
+			t.Fatalf("expected column %q, got %q", name, rows[0][i])
+		}
+	}
+	if rows[3][0] != "Insert" || rows[3][6] != "3" || rows[4][0] != "DeleteMin" || rows[4][1] != "2" {
+		t.Fatalf("unexpected rows %v", rows[3:])
+	}
+	if _, err := strconv.Atoi(rows[4][2]); err != nil {
+		t.Fatal(err)
+	}
+
+	// heaps without Potential only have the common columns
+	h = New(leftist.New())
+	h.Insert(heap.Integer(1))
+	buf.Reset()
+	h.WriteCSV(&buf)
+	if buf.String()[:len("op,size,comparisons,nanos\n")] != "op,size,comparisons,nanos\n" {
+		t.Fatalf("unexpected CSV %q", buf.String())
+	}
+}
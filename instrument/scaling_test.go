@@ -0,0 +1,219 @@
+package instrument
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/binomial"
+	"github.com/theodesp/go-heaps/fibonacci"
+	"github.com/theodesp/go-heaps/heaptest"
+	"github.com/theodesp/go-heaps/heaptest/decreasetest"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+	"github.com/theodesp/go-heaps/quake"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+	"github.com/theodesp/go-heaps/skew"
+	"github.com/theodesp/go-heaps/treap"
+	"github.com/theodesp/go-heaps/violation"
+)
+
+// bound is the expected growth of the comparisons of an operation.
+type bound int
+
+const (
+	constant bound = iota
+	logarithmic
+)
+
+// growth returns the factor by which the comparisons of an operation are
+// expected to grow from n to 2n items.
+func (b bound) growth(n int) float64 {
+	if b == logarithmic {
+		return math.Log2(float64(2*n)) / math.Log2(float64(n))
+	}
+	return 1
+}
+
+// slack is the factor allowed on top of the expected growth. An operation
+// turned linear grows by 2, well above it for the sizes measured.
+const slack = 1.3
+
+var scalings = []struct {
+	name    string
+	newHeap func() heap.Interface
+	// bounds holds the amortized bound of every operation measured. Meld
+	// is only measured for heaps implementing heap.Extended.
+	bounds map[string]bound
+}{
+	{"Pairing", func() heap.Interface { return pairing.New() },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": constant}},
+	{"PairingMultiPass", func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.MultiPass)) },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": constant}},
+	{"PairingAuxiliary", func() heap.Interface { return pairing.New(pairing.WithStrategy(pairing.Auxiliary)) },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": constant}},
+	{"Leftist", func() heap.Interface { return leftist.New() },
+		map[string]bound{"Insert": logarithmic, "FindMin": constant, "DeleteMin": logarithmic}},
+	{"Skew", func() heap.Interface { return skew.New() },
+		map[string]bound{"Insert": logarithmic, "FindMin": constant, "DeleteMin": logarithmic}},
+	{"Fibonacci", func() heap.Interface { return fibonacci.New() },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic}},
+	{"Binomial", func() heap.Interface { return &binomial.BinomialHeap{} },
+		map[string]bound{"Insert": constant, "FindMin": logarithmic, "DeleteMin": logarithmic}},
+	{"RankPairing", func() heap.Interface { return rpheap.New() },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": constant}},
+	{"Treap", func() heap.Interface { return treap.New() },
+		map[string]bound{"Insert": logarithmic, "FindMin": logarithmic, "DeleteMin": logarithmic}},
+	{"Quake", func() heap.Interface { return quake.New() },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": logarithmic}},
+	{"Violation", func() heap.Interface { return violation.New() },
+		map[string]bound{"Insert": constant, "FindMin": constant, "DeleteMin": logarithmic, "Meld": logarithmic}},
+}
+
+// hidden hides the Potential method of a heap, which is not constant time
+// and would make recording every operation quadratic.
+type hidden struct{ heap.Extended }
+
+func (h hidden) Meld(a heap.Interface) heap.Interface {
+	h.Extended.Meld(a.(hidden).Extended)
+	return h
+}
+
+func hide(h heap.Interface) heap.Interface {
+	if x, ok := h.(heap.Extended); ok {
+		return hidden{x}
+	}
+	return struct{ heap.Interface }{h}
+}
+
+// comparisons returns the average comparisons of the operations of a
+// workload on heaps of n items: n Insert, then n/2 DeleteMin, then n/2
+// FindMin, and Meld of heaps of n items on which DeleteMin was called once,
+// leaving them consolidated.
+func comparisons(newHeap func() heap.Interface, n int, meld bool) map[string]float64 {
+	r := rand.New(rand.NewSource(int64(n)))
+	h := New(hide(newHeap()))
+	for _, v := range r.Perm(n) {
+		h.Insert(heap.Integer(v))
+	}
+	for i := 0; i < n/2; i++ {
+		h.DeleteMin()
+	}
+	for i := 0; i < n/2; i++ {
+		h.FindMin()
+	}
+
+	records := h.Records()
+	if meld {
+		const melds = 16
+		for i := 0; i < melds; i++ {
+			a, b := New(hide(newHeap())), New(hide(newHeap()))
+			for _, v := range r.Perm(n) {
+				a.Insert(heap.Integer(v))
+				b.Insert(heap.Integer(v))
+			}
+			a.DeleteMin()
+			b.DeleteMin()
+			a.Reset()
+			a.Meld(b)
+			records = append(records, a.Records()...)
+		}
+	}
+
+	total := map[string]int{}
+	count := map[string]int{}
+	for _, r := range records {
+		total[r.Op] += r.Comparisons
+		count[r.Op]++
+	}
+	avg := map[string]float64{}
+	for op := range total {
+		avg[op] = float64(total[op]) / float64(count[op])
+	}
+	return avg
+}
+
+// TestScaling checks that the average comparisons of the operations of
+// every heap grow from n to 2n items within their amortized bounds, to catch
+// an operation turning linear.
+func TestScaling(t *testing.T) {
+	const n = 1 << 12
+	for _, s := range scalings {
+		_, meld := s.bounds["Meld"]
+		small := comparisons(s.newHeap, n, meld)
+		large := comparisons(s.newHeap, 2*n, meld)
+		for op, b := range s.bounds {
+			// operations making less than a comparison are constant anyway
+			ratio := math.Max(large[op], 1) / math.Max(small[op], 1)
+			if ratio > b.growth(n)*slack {
+				t.Errorf("%s: %s made %.1f comparisons on average for %d items and %.1f for %d, growing %.2f times",
+					s.name, op, small[op], n, large[op], 2*n, ratio)
+			}
+		}
+	}
+}
+
+// decreaseComparisons returns the average comparisons of DecreaseKey and of
+// the DeleteMin calls following it on a heap of n items: after n Push and a
+// DeleteMin, n/2 items are decreased and n/2 removed.
+func decreaseComparisons(newHeap func() heaptest.DecreaseKeyer, n int) (decrease, deleteMin float64) {
+	r := rand.New(rand.NewSource(int64(n)))
+	counter := &Heap{} // counts the comparisons of the items it wraps
+	h := newHeap()
+	keys := r.Perm(n)
+	handles := make([]interface{}, n)
+	for i, k := range keys {
+		handles[i] = h.Push(counter.wrap(heap.Integer(n + k)))
+	}
+	h.DeleteMin() // removes the item of key 0
+
+	counter.comparisons = 0
+	decreases := 0
+	for _, i := range r.Perm(n)[:n/2] {
+		if keys[i] == 0 {
+			continue
+		}
+		decreases++
+		keys[i] -= 1 + r.Intn(n)
+		h.DecreaseKey(handles[i], counter.wrap(heap.Integer(n+keys[i])))
+	}
+	decrease = float64(counter.comparisons) / float64(decreases)
+
+	counter.comparisons = 0
+	for i := 0; i < n/2; i++ {
+		h.DeleteMin()
+	}
+	deleteMin = float64(counter.comparisons) / float64(n/2)
+	return decrease, deleteMin
+}
+
+// TestDecreaseKeyScaling checks that DecreaseKey stays constant and the
+// following DeleteMin logarithmic on the heaps with handles.
+func TestDecreaseKeyScaling(t *testing.T) {
+	const n = 1 << 12
+	for _, s := range []struct {
+		name    string
+		newHeap func() heaptest.DecreaseKeyer
+	}{
+		{"Quake", func() heaptest.DecreaseKeyer { return decreasetest.Quake(quake.New()) }},
+		{"Violation", func() heaptest.DecreaseKeyer { return decreasetest.Violation(violation.New()) }},
+	} {
+		smallDecrease, smallDelete := decreaseComparisons(s.newHeap, n)
+		largeDecrease, largeDelete := decreaseComparisons(s.newHeap, 2*n)
+		for _, c := range []struct {
+			op           string
+			b            bound
+			small, large float64
+		}{
+			{"DecreaseKey", constant, smallDecrease, largeDecrease},
+			{"DeleteMin", logarithmic, smallDelete, largeDelete},
+		} {
+			ratio := math.Max(c.large, 1) / math.Max(c.small, 1)
+			if ratio > c.b.growth(n)*slack {
+				t.Errorf("%s: %s made %.1f comparisons on average for %d items and %.1f for %d, growing %.2f times",
+					s.name, c.op, c.small, n, c.large, 2*n, ratio)
+			}
+		}
+	}
+}
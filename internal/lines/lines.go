@@ -0,0 +1,21 @@
+// Package lines reads text line by line without bufio.Scanner's limit on
+// the length of a line, for the dump and trace formats whose lines hold
+// items of any size.
+package lines
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Read returns the next line of br without its line ending, however long it
+// is, and io.EOF at the end of the input. A last line without a line ending
+// is returned without error.
+func Read(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
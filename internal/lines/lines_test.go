@@ -0,0 +1,22 @@
+package lines
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	br := bufio.NewReader(strings.NewReader("a\r\n\n" + long + "\nlast"))
+	for _, want := range []string{"a", "", long, "last"} {
+		line, err := Read(br)
+		if err != nil || line != want {
+			t.Fatalf("expected a line of %d bytes, got %d bytes and %v", len(want), len(line), err)
+		}
+	}
+	if _, err := Read(br); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package leftist
 
 import (
+	"context"
+	"fmt"
+
 	heap "github.com/theodesp/go-heaps"
 )
 
@@ -8,48 +11,98 @@ import (
 type Node struct {
 	item        heap.Item
 	left, right *Node
-	s           int // s-value (or rank)
+	s           int // s-value (or rank), or the subtree weight when weight-biased
 }
 
 // LeftistHeap is a leftist heap implementation.
 type LeftistHeap struct {
 	root *Node
+
+	weightBiased bool
+	spine        []*Node // scratch space reused by mergeNodes
 }
 
-func mergeNodes(x, y *Node) *Node {
-	if x == nil {
-		return y
+// Option configures a LeftistHeap created by New.
+type Option func(*LeftistHeap)
+
+// WithWeightBias keeps the heap weight-biased instead of rank-biased: each
+// left subtree holds at least as many items as its right sibling, which also
+// bounds the right spine to O(log n) nodes.
+func WithWeightBias() Option {
+	return func(h *LeftistHeap) {
+		h.weightBiased = true
 	}
+}
 
-	if y == nil {
-		return x
+// mergeNodes merges the heaps rooted at x and y and returns the new root.
+// It walks down the right spines, which are O(log n) long, iteratively and
+// then restores the leftist property bottom up, so merging never recurses.
+func (h *LeftistHeap) mergeNodes(x, y *Node) *Node {
+	// spine holds the nodes whose right subtree is being merged, top down
+	spine := h.spine[:0]
+	var merged *Node
+	for {
+		if x == nil {
+			merged = y
+			break
+		}
+		if y == nil {
+			merged = x
+			break
+		}
+		// Compare the roots of two heaps.
+		if x.item.Compare(y.item) > 0 {
+			x, y = y, x
+		}
+		if !h.weightBiased && x.left == nil {
+			// left child doesn't exist, so move right child to the smallest key
+			// to maintain the leftList invariant
+			x.left = y
+			x.right = nil
+			merged = x
+			break
+		}
+		spine = append(spine, x)
+		x = x.right
 	}
-	// Compare the roots of two heaps.
-	if x.item.Compare(y.item) > 0 {
-		return merge(y, x)
-	} else {
-		return merge(x, y)
+
+	for i := len(spine) - 1; i >= 0; i-- {
+		x := spine[i]
+		spine[i] = nil
+		x.right = merged
+		if h.weightBiased {
+			if weight(x.left) < weight(x.right) {
+				x.left, x.right = x.right, x.left
+			}
+			x.s = weight(x.left) + weight(x.right) + 1
+		} else {
+			// left child does exist, so compare s-values
+			if x.left.s < x.right.s {
+				x.left, x.right = x.right, x.left
+			}
+			// since we know the right child has the lower s-value, we can just
+			// add one to its s-value
+			x.s = x.right.s + 1
+		}
+		merged = x
 	}
+	h.spine = spine[:0]
+	return merged
 }
 
-func merge(x, y *Node) *Node {
-	if x.left == nil {
-		// left child doesn't exist, so move right child to the smallest key
-		// to maintain the leftList invariant
-		x.left = y
-		x.right = nil
-	} else {
-		x.right = mergeNodes(x.right, y)
-		// left child does exist, so compare s-values
-		if x.left.s < x.right.s {
-			x.left, x.right = x.right, x.left
-		}
-		// since we know the right child has the lower s-value, we can just
-		// add one to its s-value
-		x.s = x.right.s + 1
+func weight(n *Node) int {
+	if n == nil {
+		return 0
 	}
+	return n.s
+}
 
-	return x
+func (h *LeftistHeap) newNode(item heap.Item) *Node {
+	n := &Node{item: item}
+	if h.weightBiased {
+		n.s = 1
+	}
+	return n
 }
 
 // Init initializes or clears the LeftistHeap
@@ -58,15 +111,80 @@ func (h *LeftistHeap) Init() *LeftistHeap {
 	return h
 }
 
-// New returns an initialized LeftistHeap.
-func New() *LeftistHeap { return new(LeftistHeap).Init() }
+// New returns an initialized LeftistHeap configured with opts.
+func New(opts ...Option) *LeftistHeap {
+	h := new(LeftistHeap)
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h.Init()
+}
+
+// Build returns a LeftistHeap configured with opts holding items. Rather than
+// inserting items one by one, it melds single-item heaps pairwise, round after
+// round, until one heap remains.
+// The complexity is O(n).
+func Build(items []heap.Item, opts ...Option) *LeftistHeap {
+	h, _ := build(nil, items, opts)
+	return h
+}
+
+// BuildContext is like Build but gives up when ctx is cancelled or its
+// deadline passes, returning ctx.Err(). Nothing is left behind, since items
+// are not copied.
+func BuildContext(ctx context.Context, items []heap.Item, opts ...Option) (*LeftistHeap, error) {
+	h, ok := build(ctx.Done(), items, opts)
+	if !ok {
+		return nil, ctx.Err()
+	}
+	return h, nil
+}
+
+// checkInterval is the number of merges between two checks for cancellation.
+const checkInterval = 1024
+
+// build implements Build, returning false if done is closed before it is
+// finished.
+func build(done <-chan struct{}, items []heap.Item, opts []Option) (*LeftistHeap, bool) {
+	h := New(opts...)
+	if len(items) == 0 {
+		return h, true
+	}
+
+	queue := make([]*Node, len(items))
+	for i, item := range items {
+		queue[i] = h.newNode(item)
+	}
+	merges := 0
+	for n := len(queue); n > 1; n = (n + 1) / 2 {
+		for i := 0; i < n/2; i++ {
+			if merges++; merges%checkInterval == 0 && cancelled(done) {
+				return nil, false
+			}
+			queue[i] = h.mergeNodes(queue[2*i], queue[2*i+1])
+		}
+		if n%2 == 1 {
+			queue[n/2] = queue[n-1]
+		}
+	}
+	h.root = queue[0]
+
+	return h, true
+}
+
+func cancelled(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
 
 // Insert adds an item into the heap.
 // The complexity is O(log n) amortized.
 func (h *LeftistHeap) Insert(item heap.Item) heap.Item {
-	h.root = mergeNodes(&Node{
-		item: item,
-	}, h.root)
+	h.root = h.mergeNodes(h.newNode(item), h.root)
 
 	return item
 }
@@ -74,9 +192,12 @@ func (h *LeftistHeap) Insert(item heap.Item) heap.Item {
 // DeleteMin deletes the minimum value and returns it.
 // The complexity is O(log n) amortized.
 func (h *LeftistHeap) DeleteMin() heap.Item {
+	if h.root == nil {
+		return nil
+	}
 	item := h.root.item
 
-	h.root = mergeNodes(h.root.left, h.root.right)
+	h.root = h.mergeNodes(h.root.left, h.root.right)
 
 	return item
 }
@@ -94,3 +215,132 @@ func (h *LeftistHeap) FindMin() heap.Item {
 func (h *LeftistHeap) Clear() {
 	h.Init()
 }
+
+// Compare implements the Item interface by comparing the minimum of the heap,
+// so a LeftistHeap can be stored in another heap. Empty heaps sort last.
+// A nested heap must be taken out of the outer heap while its minimum
+// changes and put back afterwards, otherwise the outer heap order breaks.
+func (h *LeftistHeap) Compare(than heap.Item) int {
+	return heap.CompareMin(h, than)
+}
+
+// Rank returns the length of the right spine of the heap, counted in edges.
+// For a rank-biased heap this is the null path length (s-value) of the
+// root; a weight-biased heap keeps subtree weights instead, but its right
+// spine is O(log n) long too. An empty heap has rank -1.
+// The complexity is O(log n).
+func (h *LeftistHeap) Rank() int {
+	rank := -1
+	for n := h.root; n != nil; n = n.right {
+		rank++
+	}
+	return rank
+}
+
+// Depth returns the number of nodes on the longest path from the root to a
+// leaf. An empty heap has depth 0.
+// The complexity is O(n).
+func (h *LeftistHeap) Depth() int {
+	return depth(h.root)
+}
+
+func depth(n *Node) int {
+	type entry struct {
+		n     *Node
+		depth int
+	}
+	max := 0
+	stack := []entry{{n, 1}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.n == nil {
+			continue
+		}
+		if e.depth > max {
+			max = e.depth
+		}
+		stack = append(stack, entry{e.n.left, e.depth + 1}, entry{e.n.right, e.depth + 1})
+	}
+	return max
+}
+
+// DetectDegenerate reports the size and maximum depth of the tree against
+// log2(n). Leftist trees are deliberately unbalanced to the left, so deep
+// left paths are expected; the report flags trees deeper than
+// heap.DegenerateFactor times a balanced tree.
+// The complexity is O(n).
+func (h *LeftistHeap) DetectDegenerate() heap.Health {
+	return heap.NewHealth(size(h.root), h.Depth())
+}
+
+func size(n *Node) int {
+	count := 0
+	stack := []*Node{n}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n != nil {
+			count++
+			stack = append(stack, n.left, n.right)
+		}
+	}
+	return count
+}
+
+// Validate checks the heap order, the leftist property and the cached
+// s-values (or weights) of every node, returning the first violation found.
+// The complexity is O(n).
+func (h *LeftistHeap) Validate() error {
+	_, err := h.validate(h.root)
+	return err
+}
+
+// validate returns the rank, or the weight when weight-biased, of n.
+// It checks the nodes in the same order as a recursive traversal would, but
+// keeps its own stack since leftist trees can be as deep as they are large.
+func (h *LeftistHeap) validate(n *Node) (int, error) {
+	type frame struct {
+		n        *Node
+		children bool // the ranks of both children are on ranks
+	}
+	stack := []frame{{n: n}}
+	var ranks []int
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := f.n
+		if n == nil {
+			if h.weightBiased {
+				ranks = append(ranks, 0)
+			} else {
+				ranks = append(ranks, -1)
+			}
+			continue
+		}
+		if !f.children {
+			for _, child := range []*Node{n.left, n.right} {
+				if child != nil && n.item.Compare(child.item) > 0 {
+					return 0, fmt.Errorf("leftist: heap order violated: %v is a parent of %v", n.item, child.item)
+				}
+			}
+			stack = append(stack, frame{n: n, children: true}, frame{n: n.right}, frame{n: n.left})
+			continue
+		}
+
+		l, r := ranks[len(ranks)-2], ranks[len(ranks)-1]
+		ranks = ranks[:len(ranks)-2]
+		if l < r {
+			return 0, fmt.Errorf("leftist: leftist property violated at %v: left %d < right %d", n.item, l, r)
+		}
+		want := r + 1
+		if h.weightBiased {
+			want = l + r + 1
+		}
+		if n.s != want {
+			return 0, fmt.Errorf("leftist: stale s-value at %v: %d, expected %d", n.item, n.s, want)
+		}
+		ranks = append(ranks, want)
+	}
+	return ranks[0], nil
+}
@@ -1,7 +1,13 @@
 package leftist
 
 import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/theodesp/go-heaps"
@@ -62,6 +68,345 @@ func TestLeftistHeap(t *testing.T) {
 	}
 }
 
+func TestWeightBiasedLeftistHeap(t *testing.T) {
+	heap := New(WithWeightBias())
+
+	numbers := rand.Perm(200)
+
+	for _, number := range numbers {
+		heap.Insert(Int(number))
+	}
+
+	if heap.root.s != len(numbers) {
+		t.Fatalf("expected root weight %d, got %d", len(numbers), heap.root.s)
+	}
+
+	sort.Ints(numbers)
+
+	for _, number := range numbers {
+		if Int(number) != heap.DeleteMin().(go_heaps.Integer) {
+			t.Fail()
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	if Build(nil).FindMin() != nil {
+		t.Fail()
+	}
+
+	for _, opts := range [][]Option{nil, {WithWeightBias()}} {
+		numbers := rand.Perm(1000)
+		items := make([]go_heaps.Item, len(numbers))
+		for i, number := range numbers {
+			items[i] = Int(number)
+		}
+
+		heap := Build(items, opts...)
+
+		sort.Ints(numbers)
+
+		for _, number := range numbers {
+			if Int(number) != heap.DeleteMin().(go_heaps.Integer) {
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithWeightBias()}} {
+		heap := New(opts...)
+		if heap.Validate() != nil || heap.Rank() != -1 || heap.Depth() != 0 {
+			t.Fail()
+		}
+
+		for _, number := range rand.Perm(500) {
+			heap.Insert(Int(number))
+			if err := heap.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := 0; i < 250; i++ {
+			heap.DeleteMin()
+			if err := heap.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// the right spine of a leftist tree holds at most log2(n+1) nodes
+		if rank := heap.Rank(); rank+1 > 8 {
+			t.Errorf("rank %d too large for 250 items", rank)
+		}
+		if heap.Depth() < heap.Rank()+1 {
+			t.Fail()
+		}
+		// Rank is the s-value of the root only when rank-biased, the
+		// weight-biased root holding the size of the heap
+		if s := heap.root.s; heap.weightBiased != (s != heap.Rank()) {
+			t.Errorf("rank %d with root s-value %d", heap.Rank(), s)
+		}
+	}
+
+	heap := New()
+	heap.Insert(Int(1))
+	heap.Insert(Int(2))
+	heap.root.item, heap.root.left.item = heap.root.left.item, heap.root.item
+	if heap.Validate() == nil {
+		t.Error("expected heap order violation")
+	}
+
+	heap = New()
+	heap.Insert(Int(1))
+	heap.Insert(Int(2))
+	heap.root.left, heap.root.right = heap.root.right, heap.root.left
+	if heap.Validate() == nil {
+		t.Error("expected leftist property violation")
+	}
+}
+
+func TestDetectDegenerate(t *testing.T) {
+	heap := New()
+	for _, number := range rand.Perm(1000) {
+		heap.Insert(Int(number))
+	}
+
+	health := heap.DetectDegenerate()
+	if health.Size != 1000 || health.MaxDepth != heap.Depth() {
+		t.Errorf("unexpected report %v", health)
+	}
+}
+
+func TestBuildContext(t *testing.T) {
+	items := randomItems(10000)
+	heap, err := BuildContext(context.Background(), items)
+	if err != nil || heap.Validate() != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for i := 0; i < len(items); i++ {
+		if heap.DeleteMin() != Int(i) {
+			t.Fatal("unexpected order")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	heap, err = BuildContext(ctx, items)
+	if heap != nil || err != context.Canceled {
+		t.Fatalf("expected the build to be cancelled, got %v", err)
+	}
+}
+
+func TestDumpLoadState(t *testing.T) {
+	parse := func(s string) (go_heaps.Item, error) {
+		i, err := strconv.Atoi(s)
+		return Int(i), err
+	}
+	for _, opts := range [][]Option{nil, {WithWeightBias()}} {
+		h := New(opts...)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 200; i++ {
+			h.Insert(Int(r.Intn(1000)))
+			if i%3 == 0 {
+				h.DeleteMin()
+			}
+		}
+		var dump bytes.Buffer
+		if err := h.DumpState(&dump); err != nil {
+			t.Fatal(err)
+		}
+		restored := New(opts...)
+		if err := restored.LoadState(bytes.NewReader(dump.Bytes()), parse); err != nil {
+			t.Fatal(err)
+		}
+
+		// the same operations leave both heaps in the same shape
+		for i := 0; i < 100; i++ {
+			v := Int(r.Intn(1000))
+			h.Insert(v)
+			restored.Insert(v)
+			if i%2 == 0 && h.DeleteMin() != restored.DeleteMin() {
+				t.Fatal("restored heap diverged")
+			}
+		}
+		var a, b bytes.Buffer
+		h.DumpState(&a)
+		restored.DumpState(&b)
+		if a.String() != b.String() {
+			t.Fatalf("restored heap has another shape:\n%s\n%s", a.String(), b.String())
+		}
+		if err := restored.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := New()
+	h.Insert(Int(1))
+	for _, dump := range []string{
+		"",
+		"pairing-heap v1\n",
+		"leftist-heap v1 weight\n",
+		"leftist-heap v1 rank\n0 - 0\n",
+		"leftist-heap v1 rank\n0 - 0 \"1\"\n0 - 0 \"2\"\n",
+		"leftist-heap v1 rank\n0 - 0 \"1\"\n2 L 0 \"2\"\n",
+		"leftist-heap v1 rank\n0 - 0 \"1\"\n1 L 0 \"2\"\n1 L 0 \"3\"\n",
+		"leftist-heap v1 rank\n0 - 0 \"1\"\n1 R 0 \"2\"\n1 L 0 \"3\"\n",
+		"leftist-heap v1 rank\n0 - x \"1\"\n",
+		"leftist-heap v1 rank\n0 - 0 \"x\"\n",
+	} {
+		if err := h.LoadState(strings.NewReader(dump), parse); err == nil {
+			t.Errorf("expected an error loading %q", dump)
+		}
+	}
+	if h.FindMin() != Int(1) {
+		t.Fatal("failed loads changed the heap")
+	}
+	if err := h.LoadState(strings.NewReader("leftist-heap v1 rank\n"), parse); err != nil || h.FindMin() != nil {
+		t.Fatal("expected an empty dump to clear the heap")
+	}
+
+	// lines are not limited in length
+	long := strings.Repeat("9", 100000)
+	if err := h.LoadState(strings.NewReader("leftist-heap v1 rank\n0 - 1 \""+long+"\""), func(s string) (go_heaps.Item, error) {
+		return go_heaps.String(s), nil
+	}); err != nil || h.FindMin() != go_heaps.String(long) {
+		t.Fatalf("failed to load a long line: %v", err)
+	}
+}
+
+// stressEnv enables the full size of TestStress, which needs several GB of
+// memory under the race detector.
+const stressEnv = "GO_HEAPS_STRESS"
+
+func TestStress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping stress test in short mode")
+	}
+	n := 100000
+	if os.Getenv(stressEnv) != "" {
+		n = 10000000
+	}
+
+	// decreasing inserts build a left path as long as the heap
+	for _, opts := range [][]Option{nil, {WithWeightBias()}} {
+		heap := New(opts...)
+		for i := n - 1; i >= 0; i-- {
+			heap.Insert(Int(i))
+		}
+		if err := heap.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if health := heap.DetectDegenerate(); health.Size != n {
+			t.Fatalf("unexpected report %v", health)
+		}
+		for i := 0; i < 1000; i++ {
+			if heap.DeleteMin() != Int(i) {
+				t.Fatal("unexpected order")
+			}
+		}
+		if err := heap.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const buildSize = 1000000
+
+func BenchmarkBuild(b *testing.B) {
+	items := randomItems(buildSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Build(items)
+	}
+}
+
+func BenchmarkBuildByInsert(b *testing.B) {
+	items := randomItems(buildSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap := New()
+		for _, item := range items {
+			heap.Insert(item)
+		}
+	}
+}
+
+const benchSize = 10000
+
+// decreasingItems returns n items in decreasing order, the worst case for a
+// right spine that is walked on every insert.
+func decreasingItems(n int) []go_heaps.Item {
+	items := make([]go_heaps.Item, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		items = append(items, Int(i))
+	}
+	return items
+}
+
+func randomItems(n int) []go_heaps.Item {
+	items := make([]go_heaps.Item, n)
+	for i, number := range rand.Perm(n) {
+		items[i] = Int(number)
+	}
+	return items
+}
+
+func BenchmarkInsert(b *testing.B) {
+	items := randomItems(b.N)
+	heap := New()
+	b.ResetTimer()
+	for _, item := range items {
+		heap.Insert(item)
+	}
+}
+
+func BenchmarkInsertDecreasing(b *testing.B) {
+	items := decreasingItems(b.N)
+	heap := New()
+	b.ResetTimer()
+	for _, item := range items {
+		heap.Insert(item)
+	}
+}
+
+func BenchmarkDeleteMin(b *testing.B) {
+	heap := Build(randomItems(b.N))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap.DeleteMin()
+	}
+}
+
+func BenchmarkMixed(b *testing.B) {
+	heap := Build(randomItems(benchSize))
+	next := benchSize
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heap.DeleteMin()
+		heap.Insert(Int(next - rand.Intn(benchSize)))
+		next++
+	}
+}
+
+func BenchmarkMerge(b *testing.B) {
+	// melds small heaps into a growing one, preparing them in batches
+	// outside of the timer
+	const batch, size = 1000, 16
+	heap := Build(randomItems(benchSize))
+	heaps := make([]*LeftistHeap, batch)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%batch == 0 {
+			b.StopTimer()
+			for j := range heaps {
+				heaps[j] = Build(randomItems(size))
+			}
+			b.StartTimer()
+		}
+		heap.root = heap.mergeNodes(heap.root, heaps[i%batch].root)
+	}
+}
+
 func Int(value int) go_heaps.Integer {
 	return go_heaps.Integer(value)
 }
@@ -0,0 +1,133 @@
+package leftist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/internal/lines"
+)
+
+const stateHeader = "leftist-heap v1"
+
+// DumpState writes the exact tree structure of h to w, so that a
+// checkpoint restored with LoadState behaves identically under the same
+// operations, for deterministic replay tests.
+//
+// The dump starts with a header line naming the bias of the heap, followed
+// by one line per node in pre-order, left before right. Each node line
+// holds the node depth, its side, L or R, or - for the root, its s-value
+// and its item formatted with fmt.Sprint and quoted. Depths are written as
+// numbers rather than indentation since leftist trees can be as deep as they
+// are large:
+//
+//	leftist-heap v1 rank
+//	0 - 1 "1"
+//	1 L 1 "3"
+//	2 L 0 "7"
+//	2 R 0 "9"
+//	1 R 0 "5"
+func (h *LeftistHeap) DumpState(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, stateHeader, h.bias())
+	type frame struct {
+		n     *Node
+		side  string
+		depth int
+	}
+	stack := []frame{{n: h.root, side: "-"}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if f.n == nil {
+			continue
+		}
+		fmt.Fprintf(bw, "%d %s %d %s\n", f.depth, f.side, f.n.s,
+			strconv.Quote(fmt.Sprint(f.n.item)))
+		stack = append(stack, frame{f.n.right, "R", f.depth + 1}, frame{f.n.left, "L", f.depth + 1})
+	}
+	return bw.Flush()
+}
+
+// LoadState replaces the contents of h with the tree read from r, as
+// written by DumpState from a heap with the same bias. parse converts the
+// text of each item back into an item. The tree and its s-values are
+// restored as is: the heap and leftist properties are not checked, so a
+// dump taken from a corrupted heap reproduces the corruption; Validate
+// tells them apart. Lines are not limited in length.
+// On error h is left unchanged.
+func (h *LeftistHeap) LoadState(r io.Reader, parse func(string) (heap.Item, error)) error {
+	br := bufio.NewReader(r)
+	header, err := lines.Read(br)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if !strings.HasPrefix(header, stateHeader+" ") {
+		return fmt.Errorf("leftist: missing %q header", stateHeader)
+	}
+	if bias := strings.TrimPrefix(header, stateHeader+" "); bias != h.bias() {
+		return fmt.Errorf("leftist: cannot load a %s-biased dump into a %s-biased heap", bias, h.bias())
+	}
+
+	var root *Node
+	// path holds the last node read at each depth
+	var path []*Node
+	for line := 2; ; line++ {
+		text, err := lines.Read(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		fields := strings.SplitN(text, " ", 4)
+		if len(fields) != 4 {
+			return fmt.Errorf("leftist: line %d: expected a depth, a side, an s-value and an item", line)
+		}
+		depth, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("leftist: line %d: %v", line, err)
+		}
+		s, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("leftist: line %d: %v", line, err)
+		}
+		text, err = strconv.Unquote(fields[3])
+		if err != nil {
+			return fmt.Errorf("leftist: line %d: %v", line, err)
+		}
+		item, err := parse(text)
+		if err != nil {
+			return fmt.Errorf("leftist: line %d: %v", line, err)
+		}
+
+		n := &Node{item: item, s: s}
+		side := fields[1]
+		switch {
+		case depth == 0 && side == "-" && root == nil:
+			root = n
+		case depth == 0 && side == "-":
+			return fmt.Errorf("leftist: line %d: more than one root", line)
+		case depth < 1 || depth > len(path) || side == "-":
+			return fmt.Errorf("leftist: line %d: node has no parent", line)
+		case side == "L" && path[depth-1].left == nil && path[depth-1].right == nil:
+			path[depth-1].left = n
+		case side == "R" && path[depth-1].right == nil:
+			path[depth-1].right = n
+		default:
+			return fmt.Errorf("leftist: line %d: unexpected %s child", line, side)
+		}
+		path = append(path[:depth], n)
+	}
+	h.root = root
+	return nil
+}
+
+func (h *LeftistHeap) bias() string {
+	if h.weightBiased {
+		return "weight"
+	}
+	return "rank"
+}
@@ -0,0 +1,47 @@
+package go_heaps
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Less wraps a value ordered by the google/btree convention, that is a
+// value with a method
+//
+//	Less(than T) bool
+//
+// reporting whether it sorts before than. T is usually the item interface of
+// the other package (btree.Item) and the value must be assignable to it.
+// Since T differs from package to package the method is looked up through
+// reflection once per wrapped value and called through reflection on every
+// comparison, so this is a convenience for values shared with such indexes
+// rather than a fast path.
+func Less(v interface{}) Item {
+	m := reflect.ValueOf(v).MethodByName("Less")
+	if !m.IsValid() {
+		panic(fmt.Sprintf("%T has no Less method", v))
+	}
+	t := m.Type()
+	if t.NumIn() != 1 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Bool {
+		panic(fmt.Sprintf("%T.Less is not a func(T) bool method", v))
+	}
+	return LessItem{Value: v, less: m}
+}
+
+// LessItem is an Item ordered by the Less method of Value. See Less.
+type LessItem struct {
+	Value interface{}
+	less  reflect.Value
+}
+
+// Compare implements the Item interface using two calls to Less at most.
+func (a LessItem) Compare(b Item) int {
+	other := b.(LessItem)
+	if a.less.Call([]reflect.Value{reflect.ValueOf(other.Value)})[0].Bool() {
+		return -1
+	}
+	if other.less.Call([]reflect.Value{reflect.ValueOf(a.Value)})[0].Bool() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package go_heaps
+
+// MinOf returns the smallest minimum among heaps together with the heap that
+// holds it, without removing it. Empty and nil heaps are skipped and ties go
+// to the heap listed first. It returns nil, nil when every heap is empty.
+// The complexity is O(k) FindMin calls for k heaps.
+func MinOf(heaps ...Interface) (Item, Interface) {
+	var min Item
+	var from Interface
+	for _, h := range heaps {
+		if h == nil {
+			continue
+		}
+		item := h.FindMin()
+		if item == nil {
+			continue
+		}
+		if min == nil || item.Compare(min) < 0 {
+			min, from = item, h
+		}
+	}
+	return min, from
+}
+
+// CompareMin compares the minimum of h with than, so that heaps can be used
+// as Items and nested in other heaps. When than is itself a heap its minimum
+// is used. Empty heaps compare greater than any item.
+func CompareMin(h Interface, than Item) int {
+	min := h.FindMin()
+	if other, ok := than.(Interface); ok {
+		than = other.FindMin()
+	}
+	switch {
+	case min == nil && than == nil:
+		return 0
+	case min == nil:
+		return 1
+	case than == nil:
+		return -1
+	}
+	return min.Compare(than)
+}
+
+// MeldAll combines heaps into one and returns it. Heaps implementing
+// MeldAll(...Interface) Interface, like the pairing heap, combine the others
+// in a single batch. Extended heaps are melded pairwise in rounds, and other
+// heaps are drained into the first one. Nil heaps are skipped and the heaps
+// melded in are left empty. It returns nil if every heap is nil.
+func MeldAll(heaps ...Interface) Interface {
+	var hs []Interface
+	for _, h := range heaps {
+		if h != nil {
+			hs = append(hs, h)
+		}
+	}
+	if len(hs) == 0 {
+		return nil
+	}
+
+	first := hs[0]
+	if m, ok := first.(interface {
+		MeldAll(heaps ...Interface) Interface
+	}); ok {
+		return m.MeldAll(hs[1:]...)
+	}
+	if _, ok := first.(Extended); ok {
+		for len(hs) > 1 {
+			n := 0
+			for i := 0; i+1 < len(hs); i += 2 {
+				hs[n] = hs[i].(Extended).Meld(hs[i+1])
+				n++
+			}
+			if len(hs)%2 == 1 {
+				hs[n] = hs[len(hs)-1]
+				n++
+			}
+			hs = hs[:n]
+		}
+		return hs[0]
+	}
+	for _, h := range hs[1:] {
+		for item := h.DeleteMin(); item != nil; item = h.DeleteMin() {
+			first.Insert(item)
+		}
+	}
+	return first
+}
@@ -0,0 +1,186 @@
+// Package multiplexer fans in several heaps into a single priority queue.
+//
+// A Multiplexer keeps an index heap of the minimum of every registered
+// source, so the globally smallest item is found in O(1) and popped in
+// O(log N) for N sources on top of the cost of the source DeleteMin.
+//
+// Structure is not thread safe.
+package multiplexer
+
+import (
+	"container/heap"
+
+	goheap "github.com/theodesp/go-heaps"
+)
+
+// Source is a heap registered with a Multiplexer. Its methods forward to the
+// underlying heap and keep the multiplexer index up to date. Changes made to
+// the underlying heap directly must be followed by a call to Fix.
+type Source struct {
+	h     goheap.Interface
+	mux   *Multiplexer
+	min   goheap.Item // cached minimum of h
+	id    uint64      // registration order, breaks ties between sources
+	index int         // position in the index heap, -1 when not indexed
+}
+
+// Source implements the Interface
+var _ goheap.Interface = (*Source)(nil)
+
+// Heap returns the underlying heap.
+func (s *Source) Heap() goheap.Interface {
+	return s.h
+}
+
+// Insert inserts item into the source and returns it.
+func (s *Source) Insert(item goheap.Item) goheap.Item {
+	s.h.Insert(item)
+	s.Fix()
+	return item
+}
+
+// DeleteMin removes and returns the smallest item of the source.
+func (s *Source) DeleteMin() goheap.Item {
+	item := s.h.DeleteMin()
+	s.Fix()
+	return item
+}
+
+// FindMin returns the smallest item of the source.
+func (s *Source) FindMin() goheap.Item {
+	return s.h.FindMin()
+}
+
+// Clear removes all items from the source.
+func (s *Source) Clear() {
+	s.h.Clear()
+	s.Fix()
+}
+
+// Fix re-establishes the position of the source in the multiplexer index
+// after its underlying heap was changed directly. For a pairing heap it can
+// be called from an OnMinChanged callback.
+// The complexity is O(log N).
+func (s *Source) Fix() {
+	if s.mux == nil {
+		return
+	}
+	s.mux.fix(s)
+}
+
+// Multiplexer tracks the minimums of a set of sources.
+// The zero value for Multiplexer is ready to use.
+type Multiplexer struct {
+	index  sources // non-empty sources ordered by their minimum
+	count  int
+	nextID uint64
+}
+
+// New returns an empty Multiplexer.
+func New() *Multiplexer {
+	return new(Multiplexer)
+}
+
+// Register adds h as a source of m and returns its handle.
+// The complexity is O(log N).
+func (m *Multiplexer) Register(h goheap.Interface) *Source {
+	s := &Source{h: h, mux: m, id: m.nextID, index: -1}
+	m.nextID++
+	m.count++
+	m.fix(s)
+	return s
+}
+
+// Unregister removes s from m. The underlying heap is left untouched.
+// The complexity is O(log N).
+func (m *Multiplexer) Unregister(s *Source) {
+	if s.mux != m {
+		return
+	}
+	if s.index >= 0 {
+		heap.Remove(&m.index, s.index)
+	}
+	s.mux = nil
+	m.count--
+}
+
+// Len returns the number of registered sources.
+func (m *Multiplexer) Len() int {
+	return m.count
+}
+
+// IsEmpty returns true if every source is empty.
+func (m *Multiplexer) IsEmpty() bool {
+	return len(m.index) == 0
+}
+
+// FindMin returns the smallest item across all sources together with the
+// source that holds it, or nil, nil if every source is empty. Ties go to the
+// source registered first.
+// The complexity is O(1).
+func (m *Multiplexer) FindMin() (goheap.Item, *Source) {
+	if m.IsEmpty() {
+		return nil, nil
+	}
+	s := m.index[0]
+	return s.min, s
+}
+
+// PopGlobalMin removes and returns the smallest item across all sources
+// together with the source it was taken from, or nil, nil if every source is
+// empty.
+// The complexity is O(log N) plus the DeleteMin of the source.
+func (m *Multiplexer) PopGlobalMin() (goheap.Item, *Source) {
+	if m.IsEmpty() {
+		return nil, nil
+	}
+	s := m.index[0]
+	return s.DeleteMin(), s
+}
+
+func (m *Multiplexer) fix(s *Source) {
+	s.min = s.h.FindMin()
+	switch {
+	case s.min == nil && s.index >= 0:
+		heap.Remove(&m.index, s.index)
+	case s.min == nil:
+	case s.index >= 0:
+		heap.Fix(&m.index, s.index)
+	default:
+		heap.Push(&m.index, s)
+	}
+}
+
+// sources implements container/heap.Interface over the source minimums.
+type sources []*Source
+
+func (ss sources) Len() int { return len(ss) }
+
+func (ss sources) Less(i, j int) bool {
+	cmp := ss[i].min.Compare(ss[j].min)
+	if cmp == 0 {
+		return ss[i].id < ss[j].id
+	}
+	return cmp < 0
+}
+
+func (ss sources) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+	ss[i].index = i
+	ss[j].index = j
+}
+
+func (ss *sources) Push(x interface{}) {
+	s := x.(*Source)
+	s.index = len(*ss)
+	*ss = append(*ss, s)
+}
+
+func (ss *sources) Pop() interface{} {
+	old := *ss
+	s := old[len(old)-1]
+	old[len(old)-1] = nil
+	s.index = -1
+	*ss = old[:len(old)-1]
+	return s
+}
@@ -0,0 +1,95 @@
+package multiplexer
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/leftist"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+func TestPopGlobalMin(t *testing.T) {
+	m := New()
+	if item, s := m.PopGlobalMin(); item != nil || s != nil {
+		t.Fatal("expected nothing from an empty multiplexer")
+	}
+
+	var srcs []*Source
+	var all []int
+	for i := 0; i < 5; i++ {
+		s := m.Register(pairing.New())
+		srcs = append(srcs, s)
+	}
+	for i := 0; i < 500; i++ {
+		v := rand.Intn(100)
+		srcs[rand.Intn(len(srcs))].Insert(heap.Integer(v))
+		all = append(all, v)
+	}
+	sort.Ints(all)
+
+	for _, v := range all {
+		min, _ := m.FindMin()
+		item, s := m.PopGlobalMin()
+		if item != heap.Integer(v) || min != item || s == nil {
+			t.Fatalf("expected %d, got %v", v, item)
+		}
+	}
+	if !m.IsEmpty() || m.Len() != 5 {
+		t.Fatal("expected five empty sources")
+	}
+}
+
+func TestTies(t *testing.T) {
+	m := New()
+	a := m.Register(pairing.New())
+	b := m.Register(leftist.New())
+	b.Insert(heap.Integer(1))
+	a.Insert(heap.Integer(1))
+
+	if _, s := m.PopGlobalMin(); s != a {
+		t.Fatal("expected the tie to go to the source registered first")
+	}
+	if _, s := m.PopGlobalMin(); s != b {
+		t.Fatal("expected the second source")
+	}
+}
+
+func TestFix(t *testing.T) {
+	m := New()
+	h := pairing.New()
+	h.Insert(heap.Integer(5))
+	s := m.Register(h)
+	other := m.Register(pairing.New())
+	other.Insert(heap.Integer(3))
+
+	h.Insert(heap.Integer(1))
+	s.Fix()
+	if min, from := m.FindMin(); min != heap.Integer(1) || from != s {
+		t.Fatalf("expected 1 after Fix, got %v", min)
+	}
+
+	// keep the index in sync through the pairing heap callback
+	var auto *Source
+	p := pairing.New(pairing.OnMinChanged(func(_, _ heap.Item) { auto.Fix() }))
+	auto = m.Register(p)
+	p.Insert(heap.Integer(0))
+	if _, from := m.FindMin(); from != auto {
+		t.Fatal("expected the callback to re-register the source")
+	}
+
+	m.Unregister(auto)
+	m.Unregister(s)
+	if m.Len() != 1 {
+		t.Fatalf("expected one source, got %d", m.Len())
+	}
+	if min, from := m.FindMin(); min != heap.Integer(3) || from != other {
+		t.Fatalf("expected 3 after Unregister, got %v", min)
+	}
+	s.Clear()
+	other.Clear()
+	if !m.IsEmpty() {
+		t.Fatal("expected an empty multiplexer")
+	}
+}
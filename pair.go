@@ -0,0 +1,22 @@
+package go_heaps
+
+// Pair is an Item that carries an arbitrary Value ordered by its Key.
+type Pair struct {
+	Key   Item
+	Value interface{}
+}
+
+// KV returns a Pair holding value ordered by key.
+func KV(key Item, value interface{}) Pair {
+	return Pair{Key: key, Value: value}
+}
+
+// Compare implements the Item interface by comparing keys only.
+// b may be another Pair or a bare key, so heaps that look items up by
+// comparison (Find, Delete, Adjust) can be queried by key alone.
+func (p Pair) Compare(b Item) int {
+	if other, ok := b.(Pair); ok {
+		return p.Key.Compare(other.Key)
+	}
+	return p.Key.Compare(b)
+}
@@ -0,0 +1,66 @@
+package pairing
+
+import (
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// clockBase is the origin of the insertion timestamps. Timestamps are kept
+// as durations since clockBase, which read the monotonic clock and compare
+// across heaps, so melded nodes keep their age.
+var clockBase = time.Now()
+
+// clock returns the current timestamp. It is never 0, which marks nodes
+// inserted without timestamps.
+func clock() int64 {
+	return int64(time.Since(clockBase)) + 1
+}
+
+// WithTimestamps records the time each item is inserted, so Age and
+// OldestAge can report how long items have been waiting. Items keep their
+// timestamp when adjusted or melded into another heap.
+func WithTimestamps() Option {
+	return func(p *PairHeap) {
+		p.timestamps = true
+	}
+}
+
+// Age returns how long ago the item that matches item was inserted. It
+// returns false if there is no such item or it was inserted without
+// WithTimestamps.
+// The complexity is O(n).
+func (p *PairHeap) Age(item heap.Item) (time.Duration, bool) {
+	p.consolidate()
+	if p.IsEmpty() {
+		return 0, false
+	}
+	n := p.root.findNode(item)
+	if n == nil || n.inserted == 0 {
+		return 0, false
+	}
+	return time.Duration(clock() - n.inserted), true
+}
+
+// OldestAge returns how long the oldest item of the heap has been waiting,
+// or 0 if the heap holds no timestamped item. The oldest item is not
+// necessarily the minimum, so this is the signal to watch when a queue
+// starves low priority items.
+// The complexity is O(n).
+func (p *PairHeap) OldestAge() time.Duration {
+	p.consolidate()
+	if p.IsEmpty() {
+		return 0
+	}
+	var oldest int64
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		if !n.dead && n.inserted != 0 && (oldest == 0 || n.inserted < oldest) {
+			oldest = n.inserted
+		}
+		return true
+	})
+	if oldest == 0 {
+		return 0
+	}
+	return time.Duration(clock() - oldest)
+}
@@ -0,0 +1,143 @@
+package pairing
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// PushMany inserts items into the heap in a single batch. Each item is
+// linked like Insert does, which leaves the tree in the shape the next
+// DeleteMin pairs up best, but the callbacks run once for the whole batch
+// rather than after every item. Items matching a paused segment are held
+// like Insert does.
+// The complexity is O(k) for k items.
+func (p *PairHeap) PushMany(items ...heap.Item) {
+	for _, item := range items {
+		p.mustCheckType(item)
+	}
+	before := p.minState()
+	pushed := 0
+	for _, item := range items {
+		if len(p.paused) > 0 && p.hold(item) {
+			continue
+		}
+		p.insert(item)
+		pushed++
+	}
+	if pushed > 0 {
+		p.notify(before)
+	}
+}
+
+// PopMany removes up to n items from the heap and returns them in ascending
+// order. Callbacks run once for the whole batch rather than after every
+// item.
+// The complexity is O(k log n) amortized for k items.
+func (p *PairHeap) PopMany(n int) []heap.Item {
+	before := p.minState()
+	p.consolidate()
+	if n > p.size {
+		n = p.size
+	}
+	if n <= 0 {
+		return nil
+	}
+	items := make([]heap.Item, 0, n)
+	for len(items) < n && !p.IsEmpty() {
+		items = append(items, p.root.item)
+		p.removeRoot()
+		p.settleRoot()
+	}
+	if len(items) > 0 {
+		p.mods++
+		p.notify(before)
+	}
+	return items
+}
+
+// ReprioritizeWhere replaces every item for which pred returns true with
+// f(item), for example to re-key the queued items of a tenant whose
+// priority class changed, and returns how many were replaced. The matching
+// nodes are found in one traversal and taken out of the tree together with
+// their children, whose subtrees stay ordered, and everything taken out is
+// paired up with the root once at the end instead of adjusting the items
+// one by one. Callbacks run once. The items of paused segments are not
+// visited, and neither pred nor f may modify the heap. All the new items are
+// computed and checked before the tree is changed, so if f panics, returns
+// nil, or returns an item rejected by WithItemType, the heap is left as it
+// was.
+// The complexity is O(n) for the traversal, plus O(k + c) amortized links
+// for k replaced items with c children between them.
+func (p *PairHeap) ReprioritizeWhere(pred func(item heap.Item) bool, f func(item heap.Item) heap.Item) int {
+	p.consolidate()
+	if p.IsEmpty() {
+		return 0
+	}
+	var matched []*node
+	mods := p.mods
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		if !n.dead && pred(n.item) {
+			matched = append(matched, n)
+		}
+		p.checkMods(mods)
+		return true
+	})
+	if len(matched) == 0 {
+		return 0
+	}
+	items := make([]heap.Item, len(matched))
+	for i, n := range matched {
+		item := f(n.item)
+		p.checkMods(mods)
+		if item == nil {
+			panic(fmt.Sprintf("pairing: ReprioritizeWhere replaced %v with nil", n.item))
+		}
+		p.mustCheckType(item)
+		items[i] = item
+	}
+
+	before := p.minState()
+	p.mods++
+	var detached []*node
+	minReplaced := false
+	// the nodes are visited parents first, so a matched node is either the
+	// root, or still linked to its parent, or was detached as a child of an
+	// earlier one and only needs its new item
+	for i, n := range matched {
+		switch {
+		case n == p.root:
+			p.root = nil
+			detached = append(detached, n)
+		case n.prev != nil:
+			n.cut()
+			detached = append(detached, n)
+		}
+		for c := n.child; c != nil; {
+			next := c.next
+			c.prev, c.next = nil, nil
+			detached = append(detached, c)
+			c = next
+		}
+		n.child = nil
+		n.item = items[i]
+		minReplaced = minReplaced || n == before.root
+	}
+	if p.root != nil {
+		detached = append(detached, p.root)
+	}
+	for i := 1; i < len(detached); i++ {
+		detached[i-1].next, detached[i].prev = detached[i], detached[i-1]
+	}
+	p.root = p.mergePairs(detached[0])
+	p.settleRoot()
+	if minReplaced && p.root == before.root {
+		// same node, but its item changed
+		if p.onMinChanged != nil {
+			p.onMinChanged(before.item, p.root.item)
+		}
+	} else {
+		p.notify(before)
+	}
+	return len(matched)
+}
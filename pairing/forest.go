@@ -0,0 +1,47 @@
+package pairing
+
+// WithLazyInsert makes Insert add items to a forest of single-node trees
+// instead of linking them under the root. The forest is paired up with the
+// configured strategy and melded with the root by the next operation that
+// needs the tree, such as FindMin or DeleteMin. Bursts of inserts followed
+// by a drain then skip the link into the root on every insert, at the cost
+// of a batch of links on the first read.
+func WithLazyInsert() Option {
+	return func(p *PairHeap) {
+		p.lazyInsert = true
+	}
+}
+
+// consolidate melds the forest of lazily inserted items into the tree.
+// The minimum is unchanged, so no callback is due.
+func (p *PairHeap) consolidate() {
+	if p.forest == nil {
+		return
+	}
+	var tree *node
+	if p.strategy == Auxiliary {
+		tree = p.multiPass(p.forest)
+	} else {
+		tree = p.mergePairs(p.forest)
+	}
+	p.root = p.merge(p.root, tree)
+	p.forest, p.forestMin = nil, nil
+}
+
+// buffered reports whether inserted items go to the forest rather than
+// being linked with the root.
+func (p *PairHeap) buffered() bool {
+	return p.lazyInsert || p.strategy == Auxiliary
+}
+
+// plant adds the detached tree rooted at n to the forest.
+func (p *PairHeap) plant(n *node) {
+	n.next = p.forest
+	if p.forest != nil {
+		p.forest.prev = n
+	}
+	p.forest = n
+	if p.forestMin == nil || p.less(n, p.forestMin) {
+		p.forestMin = n
+	}
+}
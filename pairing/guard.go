@@ -0,0 +1,74 @@
+package pairing
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// CompareError is the value Insert, PushMany, Adjust and Update panic with
+// when the Compare method of an item, or the order given to WithOrder,
+// panics. The operation is rolled back before: an inserted item is left out
+// of the heap and an adjusted item keeps its old value, so the heap can
+// still be used after recovering. The other operations only compare items
+// already in the heap, which are assumed to compare with each other without
+// panicking, and are not guarded.
+type CompareError struct {
+	A, B  heap.Item   // the items being compared
+	Value interface{} // the value Compare panicked with
+}
+
+func (e *CompareError) Error() string {
+	return fmt.Sprintf("pairing: comparing %v with %v panicked: %v", e.A, e.B, e.Value)
+}
+
+// guard makes the comparisons of an operation recover from a panic, so that
+// the links in progress are completed and the tree stays whole. The first
+// panic is recorded and returned by unguard.
+func (p *PairHeap) guard() {
+	p.guarded = true
+}
+
+func (p *PairHeap) unguard() *CompareError {
+	fault := p.fault
+	p.guarded, p.fault = false, nil
+	return fault
+}
+
+// guardedCompare is compare under guard. A comparison that panics puts a
+// first, which may break the order around the node of the operation, and
+// only there: that node is taken out again by quarantine.
+func (p *PairHeap) guardedCompare(a, b heap.Item) (cmp int) {
+	defer func() {
+		if r := recover(); r != nil {
+			if p.fault == nil {
+				p.fault = &CompareError{A: a, B: b, Value: r}
+			}
+			cmp = -1
+		}
+	}()
+	if p.order != nil {
+		return p.order(a, b)
+	}
+	return a.Compare(b)
+}
+
+// quarantine takes n out of the heap after a guarded operation on n failed,
+// leaving it detached and without children. The children are paired up and
+// melded with the root, which orders them again. It runs under guard, as
+// the comparisons with n made while consolidating may panic again.
+func (p *PairHeap) quarantine(n *node) {
+	p.consolidate()
+	var children *node
+	if n.child != nil {
+		children = p.mergePairs(n.child)
+		n.child = nil
+	}
+	if n == p.root {
+		p.root = children
+	} else {
+		n.cut()
+		p.root = p.merge(p.root, children)
+	}
+	p.settleRoot()
+}
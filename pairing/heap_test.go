@@ -0,0 +1,206 @@
+package pairing
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heaptest"
+)
+
+var orderCases = []struct {
+	name  string
+	input []int
+}{
+	{"empty", nil},
+	{"single", []int{7}},
+	{"increasing", []int{1, 2, 3, 4, 5, 6}},
+	{"decreasing", []int{6, 5, 4, 3, 2, 1}},
+	{"duplicates", []int{3, 1, 3, 2, 1, 3, 2}},
+	{"all equal", []int{4, 4, 4, 4, 4}},
+	{"negative", []int{0, -3, 5, -3, 2, -10}},
+}
+
+func TestDeleteMinOrder(t *testing.T) {
+	for _, tc := range orderCases {
+		for _, strategy := range []Strategy{TwoPass, MultiPass, Auxiliary} {
+			p := New(WithStrategy(strategy))
+			for _, v := range tc.input {
+				p.Insert(Int(v))
+			}
+			want := append([]int(nil), tc.input...)
+			sort.Ints(want)
+			for _, v := range want {
+				assert.Equal(t, Int(v), p.FindMin(), tc.name)
+				assert.Equal(t, Int(v), p.DeleteMin(), tc.name)
+			}
+			assert.True(t, p.IsEmpty(), tc.name)
+			assert.Nil(t, p.DeleteMin(), tc.name)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(p *PairHeap) heap.Item
+		ret  heap.Item
+		want []int
+	}{
+		{"delete removes one copy", func(p *PairHeap) heap.Item {
+			return p.Delete(Int(3))
+		}, Int(3), []int{1, 1, 2, 3, 3}},
+		{"delete missing", func(p *PairHeap) heap.Item {
+			return p.Delete(Int(9))
+		}, nil, []int{1, 1, 2, 3, 3, 3}},
+		{"adjust one copy down", func(p *PairHeap) heap.Item {
+			return p.Adjust(Int(3), Int(0))
+		}, Int(3), []int{0, 1, 1, 2, 3, 3}},
+		{"adjust the min up", func(p *PairHeap) heap.Item {
+			return p.Adjust(Int(1), Int(5))
+		}, Int(5), []int{1, 2, 3, 3, 3, 5}},
+		{"find", func(p *PairHeap) heap.Item {
+			return p.Find(Int(2))
+		}, Int(2), []int{1, 1, 2, 3, 3, 3}},
+	}
+	for _, tc := range tests {
+		p := New()
+		for _, v := range []int{3, 1, 3, 2, 1, 3} {
+			p.Insert(Int(v))
+		}
+		assert.Equal(t, tc.ret, tc.op(p), tc.name)
+		checkStructure(t, p)
+		for _, v := range tc.want {
+			assert.Equal(t, Int(v), p.DeleteMin(), tc.name)
+		}
+		assert.True(t, p.IsEmpty(), tc.name)
+	}
+}
+
+const benchSize = 10000
+
+func BenchmarkInsert(b *testing.B) {
+	items := perm(b.N)
+	p := New()
+	b.ResetTimer()
+	for _, item := range items {
+		p.Insert(item)
+	}
+}
+
+// BenchmarkBurst inserts bursts of items and then drains them, the workload
+// lazy insertion is meant for.
+func BenchmarkBurst(b *testing.B) {
+	items := perm(benchSize)
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Eager", nil},
+		{"LazyInsert", []Option{WithLazyInsert()}},
+		{"Auxiliary", []Option{WithStrategy(Auxiliary)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(bc.opts...)
+			for i := 0; i < b.N; i++ {
+				for _, item := range items {
+					p.Insert(item)
+				}
+				for !p.IsEmpty() {
+					p.DeleteMin()
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkInterleaved alternates inserts and deletions, where lazy
+// insertion consolidates a forest of one on every DeleteMin.
+func BenchmarkInterleaved(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Eager", nil},
+		{"LazyInsert", []Option{WithLazyInsert()}},
+		{"Auxiliary", []Option{WithStrategy(Auxiliary)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := New(bc.opts...)
+			for _, item := range perm(benchSize) {
+				p.Insert(item)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.Insert(Int(benchSize + i))
+				p.DeleteMin()
+			}
+		})
+	}
+}
+
+func BenchmarkDeleteMin(b *testing.B) {
+	p := New()
+	for _, item := range perm(b.N) {
+		p.Insert(item)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.DeleteMin()
+	}
+}
+
+func BenchmarkAdjust(b *testing.B) {
+	p := New()
+	for _, item := range perm(benchSize) {
+		p.Insert(item)
+	}
+	// keys stay distinct: each adjusted item moves into a fresh range
+	keys := rand.Perm(benchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % benchSize
+		old := keys[j]
+		keys[j] = old - benchSize
+		p.Adjust(Int(old), Int(keys[j]))
+	}
+}
+
+func BenchmarkDeleteInterior(b *testing.B) {
+	p := New()
+	for _, item := range perm(benchSize) {
+		p.Insert(item)
+	}
+	p.DeleteMin()
+	p.Insert(Int(0))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := Int(1 + rand.Intn(benchSize-1))
+		p.Delete(v)
+		p.Insert(v)
+	}
+}
+
+func BenchmarkRandomOps(b *testing.B) {
+	ops := heaptest.RandomOps(1, benchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := New()
+		for _, op := range ops {
+			op.Apply(p)
+		}
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	p := New()
+	for _, item := range perm(benchSize) {
+		p.Insert(item)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Find(Int(rand.Intn(benchSize)))
+	}
+}
@@ -0,0 +1,59 @@
+package pairing
+
+import (
+	"fmt"
+	"reflect"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// WithItemType restricts the items of the heap to typ, or to the types
+// implementing typ when it is an interface type. Insert, PushMany, Adjust
+// and Update panic with an *ItemTypeError on an item of another type before
+// changing the heap, instead of failing in a Compare call deep inside a
+// link; TryInsert returns the error instead.
+func WithItemType(typ reflect.Type) Option {
+	return func(p *PairHeap) {
+		p.itemType = typ
+	}
+}
+
+// ItemTypeError reports an item whose type does not match WithItemType.
+type ItemTypeError struct {
+	Item heap.Item
+	Want reflect.Type
+}
+
+func (e *ItemTypeError) Error() string {
+	return fmt.Sprintf("pairing: item %v of type %T, want %v", e.Item, e.Item, e.Want)
+}
+
+// TryInsert inserts item like Insert, but returns an *ItemTypeError rather
+// than panicking when the type of item does not match WithItemType.
+// The complexity is O(1).
+func (p *PairHeap) TryInsert(item heap.Item) error {
+	if err := p.checkType(item); err != nil {
+		return err
+	}
+	p.Insert(item)
+	return nil
+}
+
+// checkType returns an *ItemTypeError if item may not be added to the heap.
+func (p *PairHeap) checkType(item heap.Item) error {
+	if p.itemType == nil {
+		return nil
+	}
+	typ := reflect.TypeOf(item)
+	if typ == p.itemType || p.itemType.Kind() == reflect.Interface && typ != nil && typ.Implements(p.itemType) {
+		return nil
+	}
+	return &ItemTypeError{Item: item, Want: p.itemType}
+}
+
+// mustCheckType panics with the error of checkType, if any.
+func (p *PairHeap) mustCheckType(item heap.Item) {
+	if err := p.checkType(item); err != nil {
+		panic(err)
+	}
+}
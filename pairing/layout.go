@@ -0,0 +1,111 @@
+package pairing
+
+import (
+	"unsafe"
+)
+
+// Stats describes the memory layout of a PairHeap.
+type Stats struct {
+	// Len is the number of items, as returned by Len.
+	Len int
+	// Nodes is the number of nodes in the tree, including the ones of items
+	// deleted lazily and awaiting compaction.
+	Nodes int
+	// Deleted is the number of nodes awaiting compaction.
+	Deleted int
+	// Fragmentation is the fraction of nodes that, in depth-first order,
+	// are not stored right after the node visited before them. It is 0
+	// after a Rebuild and grows towards 1 as nodes are allocated and freed
+	// over the lifetime of the heap, scattering the tree across memory.
+	Fragmentation float64
+}
+
+// Stats returns the layout statistics of p.
+// The complexity is O(n).
+func (p *PairHeap) Stats() Stats {
+	p.consolidate()
+	s := Stats{Len: p.Len(), Nodes: p.size, Deleted: p.dead}
+	if p.root == nil || p.size < 2 {
+		return s
+	}
+	var prev uintptr
+	var scattered int
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		addr := uintptr(unsafe.Pointer(n))
+		if prev != 0 && addr != prev+unsafe.Sizeof(node{}) {
+			scattered++
+		}
+		prev = addr
+		return true
+	})
+	s.Fragmentation = float64(scattered) / float64(p.size-1)
+	return s
+}
+
+// Rebuild copies the tree into a single freshly allocated block of nodes,
+// laid out in depth-first order, and drops the items deleted lazily. The
+// shape of the tree is kept, so the cost of later operations is the same,
+// but traversals touch memory sequentially again. Long-lived heaps with a
+// high turnover benefit from an occasional Rebuild once Stats reports a
+// high fragmentation. With WithPool, the nodes of the block are not
+// recycled when their items are removed, so that the block is released once
+// all of them are gone.
+// The complexity is O(n).
+func (p *PairHeap) Rebuild() {
+	p.consolidate()
+	if p.root == nil {
+		return
+	}
+	p.compact()
+	p.mods++
+
+	block := make([]node, p.size)
+	var old []*node
+	// path holds the last node copied at each depth
+	var path []*node
+	i := 0
+	p.root.walkNodes(func(n, _ *node, depth int) bool {
+		c := &block[i]
+		i++
+		c.item, c.seq, c.tag, c.inserted = n.item, n.seq, n.tag, n.inserted
+		c.block = true
+		switch {
+		case depth == 0:
+		case depth == len(path):
+			parent := path[depth-1]
+			parent.child, c.prev = c, parent
+		default:
+			sibling := path[depth]
+			sibling.next, c.prev = c, sibling
+		}
+		path = append(path[:depth], c)
+		if p.pool {
+			old = append(old, n)
+		}
+		return true
+	})
+	p.root = &block[0]
+	for _, n := range old {
+		p.freeNode(n)
+	}
+}
+
+// Potential reports the quantities that drive the amortized cost of the
+// next DeleteMin: the number of trees, that is the root and the items
+// awaiting consolidation by WithLazyInsert, the degree of the root, which
+// is the number of sub-heaps DeleteMin pairs up, and the number of deleted
+// nodes awaiting compaction.
+// The complexity is O(d) for d trees and root children.
+func (p *PairHeap) Potential() map[string]int {
+	trees, degree := 0, 0
+	if p.root != nil {
+		trees++
+		for n := p.root.child; n != nil; n = n.next {
+			degree++
+		}
+	}
+	for n := p.forest; n != nil; n = n.next {
+		trees++
+	}
+	return map[string]int{"trees": trees, "root_degree": degree, "deleted": p.dead}
+}
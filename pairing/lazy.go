@@ -0,0 +1,99 @@
+package pairing
+
+import (
+	"fmt"
+)
+
+// WithLazyDelete makes Delete mark items as deleted instead of cutting them
+// out of the tree. Marked nodes are removed in bulk by a compaction, which
+// runs once they make up more than ratio of the nodes, or on Compact. This
+// amortizes the restructuring for workloads that cancel most of what they
+// schedule.
+//
+// Marked items are invisible to every operation. A marked node that becomes
+// the root is popped right away, so FindMin stays O(1). Walk, WalkSubtree,
+// Children and DetectDegenerate skip them, and DumpState, Split and Unmeld
+// compact first.
+// ratio must be in (0, 1].
+func WithLazyDelete(ratio float64) Option {
+	if !(ratio > 0 && ratio <= 1) {
+		panic(fmt.Sprintf("pairing: lazy delete ratio %v out of range (0, 1]", ratio))
+	}
+	return func(p *PairHeap) {
+		p.lazy = ratio
+	}
+}
+
+// Compact removes the items marked by a lazy Delete from the tree.
+// The complexity is O(n).
+func (p *PairHeap) Compact() {
+	p.compact()
+}
+
+// bury marks n, which is not the root, as deleted and compacts the heap if
+// the deleted nodes exceed the configured ratio.
+func (p *PairHeap) bury(n *node) {
+	n.dead = true
+	p.dead++
+	if float64(p.dead) > p.lazy*float64(p.size) {
+		p.compact()
+	}
+}
+
+// compact cuts the dead nodes out of the tree. Subtrees without dead nodes
+// are kept as they are, and the pieces left behind are paired up again.
+func (p *PairHeap) compact() {
+	p.consolidate()
+	if p.dead == 0 {
+		return
+	}
+	p.mods++
+
+	var first, last *node
+	keep := func(n *node) {
+		if first == nil {
+			first = n
+		} else {
+			last.next, n.prev = n, last
+		}
+		last = n
+	}
+
+	// pending holds detached subtrees; the root is never dead
+	pending := []*node{p.root}
+	for len(pending) > 0 {
+		top := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if top.dead {
+			for child := top.child; child != nil; {
+				next := child.next
+				child.prev, child.next = nil, nil
+				pending = append(pending, child)
+				child = next
+			}
+			p.dead--
+			p.size--
+			p.freeNode(top)
+			continue
+		}
+
+		keep(top)
+		// cut the dead nodes out of the subtree of top
+		stack := []*node{top}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for child := n.child; child != nil; {
+				next := child.next
+				if child.dead {
+					child.cut()
+					pending = append(pending, child)
+				} else {
+					stack = append(stack, child)
+				}
+				child = next
+			}
+		}
+	}
+	p.root = p.mergePairs(first)
+}
@@ -0,0 +1,136 @@
+package pairing
+
+import (
+	"sync"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Option configures a PairHeap created by New.
+type Option func(*PairHeap)
+
+// Strategy selects how the sub-heaps left behind by a removed root are
+// paired back into a single heap.
+type Strategy int
+
+const (
+	// TwoPass links the sub-heaps in pairs from left to right and then
+	// melds the pairs from right to left. This is the classic variant with
+	// O(log n) amortized DeleteMin.
+	TwoPass Strategy = iota
+	// MultiPass repeatedly links the first two sub-heaps of a queue and
+	// appends the result to its end until a single heap remains.
+	MultiPass
+	// Auxiliary is the auxiliary two-pass variant of Stasko and Vitter.
+	// Inserted items and the subtrees of decreased items are buffered in an
+	// auxiliary list, as with WithLazyInsert, instead of being linked with
+	// the root one at a time. The next operation that needs the tree pairs
+	// the list up with MultiPass and links the result with the root once,
+	// while DeleteMin pairs the children of the root with TwoPass. This
+	// speeds up insert-heavy workloads.
+	Auxiliary
+)
+
+// WithStrategy sets the pairing strategy used by DeleteMin and Delete.
+func WithStrategy(s Strategy) Option {
+	return func(p *PairHeap) {
+		p.strategy = s
+	}
+}
+
+// WithStable breaks ties between equal items by insertion order, so items
+// that compare equal are removed first-in first-out. Items melded in from
+// another heap keep the order they had there.
+func WithStable() Option {
+	return func(p *PairHeap) {
+		p.stable = true
+	}
+}
+
+// WithPool recycles the nodes of removed items through a shared pool,
+// reducing allocations for heaps with a high insert and delete rate.
+func WithPool() Option {
+	return func(p *PairHeap) {
+		p.pool = true
+	}
+}
+
+// OnMinChanged registers fn to be called whenever the minimum of the heap
+// changes, with the previous and the new minimum. old is nil when the heap
+// was empty and new is nil when it becomes empty. fn runs synchronously at
+// the end of the operation that changed the minimum and must not modify
+// the heap.
+func OnMinChanged(fn func(old, new heap.Item)) Option {
+	return func(p *PairHeap) {
+		p.onMinChanged = fn
+	}
+}
+
+// minState captures the current minimum for notify. Nodes are
+// identified by their sequence number too, since pooled nodes are reused.
+type minState struct {
+	root *node
+	seq  uint64
+	item heap.Item
+}
+
+func (p *PairHeap) minState() minState {
+	if p.onMinChanged == nil {
+		return minState{}
+	}
+	min := p.root
+	if p.forestMin != nil && (min == nil || p.less(p.forestMin, min)) {
+		min = p.forestMin
+	}
+	if min == nil {
+		return minState{}
+	}
+	return minState{root: min, seq: min.seq, item: min.item}
+}
+
+// notify runs the callbacks due at the end of an operation: the watermark
+// callbacks, and OnMinChanged if the minimum differs from the one captured
+// in before.
+func (p *PairHeap) notify(before minState) {
+	p.checkWatermarks()
+	if p.onMinChanged == nil {
+		return
+	}
+	after := p.minState()
+	if after.root == before.root && after.seq == before.seq {
+		return
+	}
+	p.onMinChanged(before.item, after.item)
+}
+
+var nodePool = sync.Pool{
+	New: func() interface{} { return new(node) },
+}
+
+// newNode returns a node holding item, taken from the pool if enabled.
+func (p *PairHeap) newNode(item heap.Item) *node {
+	var n *node
+	if p.pool {
+		n = nodePool.Get().(*node)
+	} else {
+		n = new(node)
+	}
+	n.item = item
+	p.seq++
+	n.seq = p.seq
+	if p.timestamps {
+		n.inserted = clock()
+	}
+	return n
+}
+
+// freeNode hands a detached node back to the pool if enabled. Nodes of a
+// Rebuild block are left to the garbage collector: pooling one would keep
+// its whole block reachable.
+func (p *PairHeap) freeNode(n *node) {
+	if !p.pool || n.block {
+		return
+	}
+	*n = node{}
+	nodePool.Put(n)
+}
@@ -0,0 +1,81 @@
+package pairing
+
+import (
+	heap "github.com/theodesp/go-heaps"
+)
+
+// WithOrder orders the heap by cmp instead of the Compare method of its
+// items, for example Descending to make it a max heap. cmp returns a
+// negative number when a comes first, zero when a and b tie and a positive
+// number otherwise. Items are still identified by Compare in Find, Delete,
+// Adjust and the other operations looking an item up.
+func WithOrder(cmp func(a, b heap.Item) int) Option {
+	return func(p *PairHeap) {
+		p.order = cmp
+	}
+}
+
+// Descending orders items from the greatest to the smallest by Compare.
+func Descending(a, b heap.Item) int {
+	return b.Compare(a)
+}
+
+// Reorder re-keys the heap under cmp, as if it had been created with
+// WithOrder(cmp), so that the same items can be served by another
+// criterion, such as by deadline and then by priority class. A nil cmp
+// restores the order of Compare. The items deleted lazily are dropped, and
+// the paused segments are reordered too. Heaps melded in afterwards must be
+// ordered by the same cmp.
+// The complexity is O(n).
+func (p *PairHeap) Reorder(cmp func(a, b heap.Item) int) {
+	for _, seg := range p.paused {
+		seg.items.Reorder(cmp)
+	}
+	p.consolidate()
+	p.order = cmp
+	if p.root == nil {
+		return
+	}
+
+	before := p.minState()
+	var live []*node
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		live = append(live, n)
+		return true
+	})
+	var first, last *node
+	for _, n := range live {
+		n.child, n.prev, n.next = nil, nil, nil
+		if n.dead {
+			p.freeNode(n)
+			continue
+		}
+		if first == nil {
+			first = n
+		} else {
+			last.next, n.prev = n, last
+		}
+		last = n
+	}
+	p.size, p.dead = p.size-p.dead, 0
+	p.mods++
+
+	// linking n single nodes with multiple passes takes n-1 comparisons and
+	// leaves a balanced tree for the next DeleteMin
+	p.root = nil
+	if first != nil {
+		p.root = p.multiPass(first)
+	}
+	p.notify(before)
+}
+
+// compare compares a and b by the order of the heap.
+func (p *PairHeap) compare(a, b heap.Item) int {
+	if p.guarded {
+		return p.guardedCompare(a, b)
+	}
+	if p.order != nil {
+		return p.order(a, b)
+	}
+	return a.Compare(b)
+}
@@ -6,113 +6,244 @@
 package pairing
 
 import (
+	"context"
 	heap "github.com/theodesp/go-heaps"
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
 )
 
 // PairHeap implements the Extended interface
 var _ heap.Extended = (*PairHeap)(nil)
 
+// PairHeap implements the Item interface
+var _ heap.Item = (*PairHeap)(nil)
+
 // PairHeap is an implementation of a Pairing Heap.
-// The zero value for PairHeap Root is an empty Heap.
+// The zero value for PairHeap is an empty Heap ready to use.
 type PairHeap struct {
-	root       *node
+	root *node
+
+	strategy Strategy
+	order    func(a, b heap.Item) int // nil for the order of Compare
+	itemType reflect.Type             // nil for items of any type
+	stable   bool
+	pool     bool
+	seq      uint64 // insertion counter used to break ties when stable
+	mods     uint64 // modification counter checked by iterations
+	size     int    // number of nodes, including the deleted ones
+	dead     int    // number of deleted nodes awaiting compaction
+	lazy     float64
+
+	timestamps   bool
+	lazyInsert   bool
+	forest       *node // items inserted lazily, linked through next
+	forestMin    *node // smallest node of forest
+	onMinChanged func(old, new heap.Item)
+	watermarks   watermarks
+	paused       []*segment // in order of Pause
+	guarded      bool          // comparisons recover from panics, see guard
+	fault        *CompareError // the first panic recovered while guarded
 }
 
-// node contains the current item and the list if the sub-heaps
+// node contains the current item and links to its sub-heaps. The children
+// of a node form a doubly linked list: child points to the leftmost child,
+// next to the right sibling and prev to the left sibling, or to the parent
+// for a leftmost child. This makes cutting a node out of the tree O(1).
 type node struct {
 	// for use by client; untouched by this library
 	item heap.Item
-	// List of children nodes all containing values less than the Top of the heap
-	children []*node
-	// A reference to the parent Heap Node
-	parent *node
+	// Leftmost child; all children contain values greater than the node
+	child *node
+	// Right sibling
+	next *node
+	// Left sibling, or the parent Heap Node for the leftmost child
+	prev *node
+	// Insertion sequence number
+	seq uint64
+	// Tag of the MeldTagged call that brought the node in, if any
+	tag interface{}
+	// Deleted, to be removed by the next compaction
+	dead bool
+	// Insertion timestamp, 0 unless the heap records timestamps
+	inserted int64
+	// Allocated by Rebuild as part of a block, never pooled
+	block bool
 }
 
-func (n *node) detach() []*node {
-	if n.parent == nil {
-		return nil // avoid detaching root
+// cut detaches n, together with its subtree, from its parent.
+// The complexity is O(1).
+func (n *node) cut() {
+	if n.prev == nil {
+		return // avoid detaching root
 	}
-	for _, node := range n.children {
-		node.parent = nil
+	if n.prev.child == n {
+		n.prev.child = n.next
+	} else {
+		n.prev.next = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
 	}
-	var idx int
-	for i, node := range n.parent.children {
-		if node == n {
-			idx = i
-			break
+	n.prev, n.next = nil, nil
+}
+
+// walkNodes visits the subtree rooted at n in depth-first order, calling fn
+// with each node, its parent (nil for n) and its depth relative to n.
+// It stops and returns false as soon as fn returns false.
+func (n *node) walkNodes(fn func(n, parent *node, depth int) bool) bool {
+	// path holds the nodes from n down to the current node
+	path := []*node{n}
+	for len(path) > 0 {
+		top := path[len(path)-1]
+		var parent *node
+		if len(path) > 1 {
+			parent = path[len(path)-2]
+		}
+		if !fn(top, parent, len(path)-1) {
+			return false
+		}
+		if top.child != nil {
+			path = append(path, top.child)
+			continue
+		}
+		for len(path) > 0 {
+			top = path[len(path)-1]
+			if top.next != nil && len(path) > 1 {
+				path[len(path)-1] = top.next
+				break
+			}
+			path = path[:len(path)-1]
 		}
 	}
-	n.parent.children = append(n.parent.children[:idx], n.parent.children[idx+1:]...)
-	n.parent = nil
-	return n.children
+	return true
 }
 
 func (n *node) iterItem(iter heap.ItemIterator) {
-	if !iter(n.item) {
-		return
-	}
-	n.iterChildren(n.children, iter)
+	n.walkNodes(func(n, _ *node, _ int) bool {
+		return n.dead || iter(n.item)
+	})
 }
 
-func (n *node) iterChildren(children []*node, iter heap.ItemIterator) {
-	if len(children) == 0 {
-		return
+// walkLive visits the subtree rooted at n like walkNodes, but skips the
+// nodes deleted lazily: their children are visited in their place, so the
+// parents and depths only count live nodes. Unlike a compaction, it leaves
+// the tree as it is.
+func (n *node) walkLive(fn func(n, parent *node, depth int) bool) bool {
+	type visit struct {
+		n, parent *node
+		depth     int
 	}
-	for _, node := range children {
-		if !iter(node.item) {
-			return
+	stack := []visit{{n, nil, 0}}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		// the siblings of n are not part of its subtree
+		if v.n != n && v.n.next != nil {
+			stack = append(stack, visit{v.n.next, v.parent, v.depth})
+		}
+		parent, depth := v.n, v.depth+1
+		if v.n.dead {
+			parent, depth = v.parent, v.depth
+		} else if !fn(v.n, v.parent, v.depth) {
+			return false
+		}
+		if v.n.child != nil {
+			stack = append(stack, visit{v.n.child, parent, depth})
 		}
-		n.iterChildren(node.children, iter)
 	}
+	return true
 }
 
-func (n *node) findNode(item heap.Item) *node {
-	if n.item.Compare(item) == 0 {
-		return n
-	} else {
-		return n.findInChildren(n.children, item)
-	}
+func (n *node) walk(fn WalkFunc) bool {
+	return n.walkLive(func(n, parent *node, depth int) bool {
+		var parentItem heap.Item
+		if parent != nil {
+			parentItem = parent.item
+		}
+		return fn(n.item, parentItem, depth)
+	})
 }
 
-func (n *node) findInChildren(children []*node, item heap.Item) *node {
-	if len(children) == 0 {
-		return nil
-	}
-	var node *node
-loop:
-	for _, child := range children {
-		node = child.findNode(item)
-		if node != nil {
-			break loop
+func (n *node) findNode(item heap.Item) *node {
+	var found *node
+	n.walkNodes(func(n, _ *node, _ int) bool {
+		if !n.dead && n.item.Compare(item) == 0 {
+			found = n
+			return false
 		}
-	}
-	return node
+		return true
+	})
+	return found
 }
 
 // Init initializes or clears the PairHeap
 func (p *PairHeap) Init() *PairHeap {
-	p.root = &node{}
+	p.root = nil
+	p.forest, p.forestMin = nil, nil
+	p.size, p.dead = 0, 0
+	p.paused = nil
+	p.mods++
 	return p
 }
 
-// New returns an initialized PairHeap.
-func New() *PairHeap { return new(PairHeap).Init() }
+// New returns an initialized PairHeap configured with opts.
+func New(opts ...Option) *PairHeap {
+	p := new(PairHeap)
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.watermarks.validate()
+	return p.Init()
+}
 
 // IsEmpty returns true if PairHeap p is empty.
 // The complexity is O(1).
 func (p *PairHeap) IsEmpty() bool {
-	return p.root.item == nil
+	return p.root == nil && p.forest == nil
 }
 
-// Resets the current PairHeap
+// Clear removes all items from the PairHeap, keeping the options it was
+// created with. The nodes are left to the garbage collector.
+// The complexity is O(1).
 func (p *PairHeap) Clear() {
+	before := p.minState()
 	p.Init()
+	p.notify(before)
+}
+
+// Reset removes all items from the PairHeap like Clear, and also hands the
+// nodes back to the pool of WithPool and restarts the insertion counter, so
+// a heap reused for the next batch behaves like one just created with the
+// same options. The callbacks run as for Clear.
+// The complexity is O(n) with WithPool, O(1) otherwise.
+func (p *PairHeap) Reset() {
+	before := p.minState()
+	if p.pool {
+		p.consolidate()
+		var nodes []*node
+		if p.root != nil {
+			p.root.walkNodes(func(n, _ *node, _ int) bool {
+				nodes = append(nodes, n)
+				return true
+			})
+		}
+		for _, n := range nodes {
+			p.freeNode(n)
+		}
+	}
+	p.Init()
+	p.seq = 0
+	p.notify(before)
 }
 
 // Find the smallest item in the priority queue.
-// The complexity is O(1).
+// The complexity is O(1) amortized. With WithLazyInsert or the Auxiliary
+// strategy it first links the k items inserted since the previous read,
+// which is O(k).
 func (p *PairHeap) FindMin() heap.Item {
+	p.consolidate()
 	if p.IsEmpty() {
 		return nil
 	}
@@ -122,10 +253,42 @@ func (p *PairHeap) FindMin() heap.Item {
 // Inserts the value to the PairHeap and returns the item
 // The complexity is O(1).
 func (p *PairHeap) Insert(item heap.Item) heap.Item {
-	p.root = merge(p.root, &node{item: item})
+	p.mustCheckType(item)
+	if len(p.paused) > 0 && p.hold(item) {
+		return item
+	}
+	before := p.minState()
+	p.insert(item)
+	p.notify(before)
 	return item
 }
 
+func (p *PairHeap) insert(item heap.Item) {
+	p.mods++
+	p.size++
+	n := p.newNode(item)
+	p.guard()
+	if !p.buffered() {
+		p.root = p.merge(p.root, n)
+	} else {
+		if p.forest == nil && p.root != nil {
+			// the first item of the forest is compared by nothing else
+			// before the forest is consolidated, which is not guarded
+			p.compare(item, p.root.item)
+		}
+		p.plant(n)
+	}
+	if p.fault != nil {
+		// leave the item out
+		p.quarantine(n)
+		p.size--
+		fault := p.unguard()
+		p.freeNode(n)
+		panic(fault)
+	}
+	p.unguard()
+}
+
 
 // toDelete details what item to remove in a node call.
 type toDelete int
@@ -138,71 +301,317 @@ const (
 // DeleteMin removes the top most value from the PairHeap and returns it
 // The complexity is O(log n) amortized.
 func (p *PairHeap) DeleteMin() heap.Item {
-	return p.deleteItem(nil, removeMin)
+	before := p.minState()
+	result := p.deleteItem(nil, removeMin)
+	p.notify(before)
+	return result
+}
+
+// Drain removes every item from the heap and returns them in ascending order.
+// The complexity is O(n log n) amortized.
+func (p *PairHeap) Drain() []heap.Item {
+	items, _ := p.DrainContext(context.Background())
+	return items
+}
+
+// DrainContext is like Drain but stops when ctx is cancelled or its deadline
+// passes, returning the items removed so far together with ctx.Err(). The
+// remaining items are left in the heap.
+func (p *PairHeap) DrainContext(ctx context.Context) ([]heap.Item, error) {
+	var items []heap.Item
+	done := ctx.Done()
+	for !p.IsEmpty() {
+		select {
+		case <-done:
+			return items, ctx.Err()
+		default:
+		}
+		items = append(items, p.DeleteMin())
+	}
+	return items, nil
 }
 
 // Deletes a node from the heap and returns the item
 // The complexity is O(log n) amortized.
 func (p *PairHeap) Delete(item heap.Item) heap.Item {
-	return p.deleteItem(item, removeItem)
+	before := p.minState()
+	result := p.deleteItem(item, removeItem)
+	p.notify(before)
+	return result
 }
 
 func (p *PairHeap) deleteItem(item heap.Item, typ toDelete) heap.Item {
-	var result node
+	p.consolidate()
+	if p.IsEmpty() {
+		return nil
+	}
 
-	if len(p.root.children) == 0 {
-		result = *p.root
-		p.root.item = nil
-	} else {
-		switch typ {
-		case removeMin:
-			result = *p.root
-			p.root = mergePairs(p.root, p.root.children)
-		case removeItem:
-			node := p.root.findNode(item)
-			if node == nil {
-				return nil
-			} else {
-				children := node.detach()
-				p.root.children = append(p.root.children, children...)
-				result = *node
-			}
-		default:
-			panic("invalid type")
+	var result heap.Item
+
+	p.mods++
+	switch typ {
+	case removeMin:
+		result = p.root.item
+		p.removeRoot()
+		p.settleRoot()
+	case removeItem:
+		node := p.root.findNode(item)
+		if node == nil {
+			return nil
+		} else if node == p.root {
+			return p.deleteItem(nil, removeMin)
+		} else if p.lazy > 0 {
+			result = node.item
+			p.bury(node)
+		} else {
+			result = node.item
+			p.remove(node)
 		}
+	default:
+		panic("invalid type")
+	}
+
+	return result
+}
+
+// removeRoot removes the root and pairs up its children.
+func (p *PairHeap) removeRoot() {
+	min := p.root
+	p.root = nil
+	if min.child != nil {
+		p.root = p.mergePairs(min.child)
+	}
+	p.size--
+	p.freeNode(min)
+}
+
+// settleRoot pops deleted nodes that surfaced at the root.
+func (p *PairHeap) settleRoot() {
+	for p.root != nil && p.root.dead {
+		p.dead--
+		p.removeRoot()
 	}
+}
 
-	return result.item
+// remove cuts a node other than the root out of the heap and melds its
+// children back in.
+func (p *PairHeap) remove(n *node) {
+	p.mods++
+	p.size--
+	n.cut()
+	if n.child != nil {
+		p.root = p.merge(p.root, p.mergePairs(n.child))
+	}
+	p.freeNode(n)
 }
 
 // Adjusts the value to the node item and returns it
+// The node keeps its place when possible: a decreased node is cut out with
+// its subtree and melded with the root, an increased node hands its children
+// back to the root and is melded in again on its own.
 // The complexity is O(n) amortized.
 func (p *PairHeap) Adjust(item, new heap.Item) heap.Item {
+	p.consolidate()
+	if p.IsEmpty() {
+		return nil
+	}
 	n := p.root.findNode(item)
 	if n == nil {
 		return nil
 	}
 
-	if n == p.root {
-		p.DeleteMin()
-		return p.Insert(new)
+	old := n.item
+	wasRoot := n == p.root
+	p.replace(n, new)
+	if wasRoot {
+		return new
+	}
+	return old
+}
+
+// Update replaces the item that matches item with mutate(item) and restores
+// the heap order, moving the node towards the root when the new item
+// compares less than the old one and away from it otherwise, so callers need
+// not pick between a decrease and an increase. It returns the new item, or
+// nil if no node matches item.
+// The complexity is O(n) to locate the node, then O(1) for a decrease and
+// O(log n) amortized for an increase.
+func (p *PairHeap) Update(item heap.Item, mutate func(heap.Item) heap.Item) heap.Item {
+	p.consolidate()
+	if p.IsEmpty() {
+		return nil
+	}
+	n := p.root.findNode(item)
+	if n == nil {
+		return nil
+	}
+
+	p.replace(n, mutate(n.item))
+	return n.item
+}
+
+// replace sets the item of n and restores the heap order.
+func (p *PairHeap) replace(n *node, item heap.Item) {
+	p.mustCheckType(item)
+	before := p.minState()
+	old := n.item
+	n.item = item
+	p.mods++
+	p.guard()
+	switch cmp := p.compare(item, old); {
+	case cmp < 0 && n != p.root:
+		// the subtree of n stays ordered, meld it with the root
+		n.cut()
+		if p.strategy == Auxiliary {
+			p.plant(n)
+		} else {
+			p.root = p.merge(p.root, n)
+		}
+	case cmp > 0 && n.child != nil:
+		// the children may now be smaller, pair them up and meld them back
+		if n == p.root {
+			p.root = p.mergePairs(n.child)
+		} else {
+			n.cut()
+			p.root = p.merge(p.root, p.mergePairs(n.child))
+		}
+		n.child = nil
+		p.root = p.merge(p.root, n)
+		p.settleRoot()
+	}
+	if p.fault != nil {
+		// put the old item back in place of the new one
+		p.quarantine(n)
+		fault := p.unguard()
+		n.item = old
+		p.root = p.merge(p.root, n)
+		panic(fault)
+	}
+	p.unguard()
+
+	if n == p.root && before.root == n {
+		// same node, but its item changed
+		if p.onMinChanged != nil {
+			p.onMinChanged(before.item, n.item)
+		}
 	} else {
-		children := n.detach()
-		p.Insert(new)
-		p.root.children = append(p.root.children, children...)
-		return n.item
+		p.notify(before)
+	}
+}
+
+// ExtractSubtree detaches the subtree rooted at the node that matches item
+// and returns it as a new, independent heap configured like p. Items melded
+// in from another heap stay together until a DeleteMin pairs them up, so
+// this can drop a group of related items at once. It returns nil if no node
+// matches item.
+// The complexity is O(n) to locate the node, the detachment is O(1).
+func (p *PairHeap) ExtractSubtree(item heap.Item) *PairHeap {
+	p.consolidate()
+	if p.IsEmpty() {
+		return nil
+	}
+	n := p.root.findNode(item)
+	if n == nil {
+		return nil
+	}
+
+	sub := p.spawn()
+	if n == p.root {
+		sub.root, sub.size, sub.dead = n, p.size, p.dead
+		p.Clear()
+		return sub
+	}
+	n.cut()
+	p.mods++
+	sub.root = n
+	n.walkNodes(func(n, _ *node, _ int) bool {
+		sub.size++
+		if n.dead {
+			sub.dead++
+		}
+		return true
+	})
+	p.size -= sub.size
+	p.dead -= sub.dead
+	p.checkWatermarks()
+	return sub
+}
+
+// Split partitions the items of p into a heap le holding the items that
+// compare less than or equal to pivot, in the order of the heap, and a heap
+// gt holding the rest. Both are configured like p, which is left empty.
+// Subtrees rooted above the pivot are moved to gt as a whole, so the
+// complexity is O(k) where k is the size of le plus the number of such
+// subtrees, plus O(n) when deleted items are awaiting compaction.
+func (p *PairHeap) Split(pivot heap.Item) (le, gt *PairHeap) {
+	le, gt = p.spawn(), p.spawn()
+	if p.IsEmpty() {
+		return le, gt
+	}
+	p.compact()
+
+	stack := []*node{p.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n.prev, n.next = nil, nil
+		if p.compare(n.item, pivot) > 0 {
+			gt.root = gt.merge(gt.root, n)
+			continue
+		}
+		for child := n.child; child != nil; child = child.next {
+			stack = append(stack, child)
+		}
+		n.child = nil
+		le.root = le.merge(le.root, n)
+		le.size++
 	}
+	gt.size = p.size - le.size
+
+	before := p.minState()
+	p.Init()
+	p.notify(before)
+	return le, gt
+}
+
+// spawn returns an empty heap configured like p.
+func (p *PairHeap) spawn() *PairHeap {
+	return &PairHeap{
+		strategy: p.strategy,
+		order:    p.order,
+		itemType: p.itemType,
+		stable:   p.stable,
+		pool:     p.pool,
+		seq:      p.seq,
+		lazy:     p.lazy,
+
+		timestamps: p.timestamps,
+		lazyInsert: p.lazyInsert,
+	}
+}
+
+// Compare implements the Item interface by comparing the minimum of the heap,
+// so a PairHeap can be stored in another heap, for example to order
+// per-user queues by their most urgent item. Empty heaps sort last.
+// A nested heap must be taken out of the outer heap while its minimum
+// changes and put back afterwards, otherwise the outer heap order breaks.
+func (p *PairHeap) Compare(than heap.Item) int {
+	return heap.CompareMin(p, than)
 }
 
 // Exhausting search of the element that matches item and returns it
+// The items of the heap are compared with item, rather than item with them,
+// like in Contains, Delete and Adjust, so a heap of heap.Pair can be
+// searched by key alone.
 // The complexity is O(n) amortized.
 func (p *PairHeap) Find(item heap.Item) heap.Item {
+	p.consolidate()
 	if p.IsEmpty() {
 		return nil
 	}
 	var found heap.Item
 	p.root.iterItem(func(i heap.Item) bool {
-		if item.Compare(i) == 0 {
+		if i.Compare(item) == 0 {
 			found = i
 			return false
 		} else {
@@ -213,17 +622,266 @@ func (p *PairHeap) Find(item heap.Item) heap.Item {
 }
 
 
+// Contains reports whether an item that compares equal to item is in the
+// heap. Unlike Find, it skips the subtrees whose root is greater than item,
+// unless the heap is ordered by WithOrder or Reorder.
+// The complexity is O(n) in the worst case.
+func (p *PairHeap) Contains(item heap.Item) bool {
+	p.consolidate()
+	if p.IsEmpty() {
+		return false
+	}
+	stack := []*node{p.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		cmp := n.item.Compare(item)
+		if cmp == 0 && !n.dead {
+			return true
+		}
+		if cmp <= 0 || p.order != nil {
+			for child := n.child; child != nil; child = child.next {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return false
+}
+
 // Do calls function cb on each element of the PairingHeap, in order of appearance.
-// The behavior of Do is undefined if cb changes *p.
+// Do panics if cb changes *p.
 func (p *PairHeap) Do(it heap.ItemIterator) {
+	p.consolidate()
 	if p.IsEmpty() {
 		return
 	}
-	p.root.iterItem(it)
+	mods := p.mods
+	p.root.iterItem(func(item heap.Item) bool {
+		next := it(item)
+		p.checkMods(mods)
+		return next
+	})
+}
+
+// checkMods panics if p was modified since its modification counter was mods.
+func (p *PairHeap) checkMods(mods uint64) {
+	if p.mods != mods {
+		panic("pairing: heap modified during iteration")
+	}
+}
+
+// DoSorted calls function cb on each element of the PairingHeap in ascending
+// order, without modifying the heap. Items that compare equal are visited in
+// an unspecified order unless the heap is stable.
+// The complexity is O(n log n), or O(k log k) when cb stops after k items.
+// DoSorted panics if cb changes *p.
+func (p *PairHeap) DoSorted(it heap.ItemIterator) {
+	mods := p.mods
+	p.ascend(func(n *node) bool {
+		next := it(n.item)
+		p.checkMods(mods)
+		return next
+	})
+}
+
+// Quantile returns the item of rank ceil(q*n) among the n items of the heap,
+// so 0 gives the minimum, 0.5 the median and 1 the maximum. The result is
+// exact: the items are counted, then visited in ascending order up to that
+// rank without modifying the heap. It returns nil if the heap is empty and
+// panics if q is not in [0, 1].
+// The complexity is O(n + k log k) for rank k.
+func (p *PairHeap) Quantile(q float64) heap.Item {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		panic(fmt.Sprintf("pairing: quantile %v out of range [0, 1]", q))
+	}
+	if p.IsEmpty() {
+		return nil
+	}
+	k := int(math.Ceil(q * float64(p.Len())))
+	if k < 1 {
+		k = 1
+	}
+	var found heap.Item
+	p.ascend(func(n *node) bool {
+		k--
+		found = n.item
+		return k > 0
+	})
+	return found
+}
+
+// Histogram counts the items of the heap per bucket in one traversal.
+// buckets holds bounds ascending in the order of the heap: counts[0] is the number of items less than
+// buckets[0], counts[i] the number of items in [buckets[i-1], buckets[i]) and
+// counts[len(buckets)] the number of items not less than the last bound.
+// Heap order is used to skip comparisons: the bucket of a child is searched
+// from the bucket of its parent only, and the subtrees falling in the last
+// bucket are counted without comparing their items.
+// The complexity is O(n log b) for b buckets.
+func (p *PairHeap) Histogram(buckets []heap.Item) []int {
+	counts := make([]int, len(buckets)+1)
+	p.consolidate()
+	if p.IsEmpty() {
+		return counts
+	}
+
+	type entry struct {
+		n   *node
+		low int // lowest possible bucket, the one of the parent
+	}
+	stack := []entry{{p.root, 0}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.low == len(buckets) {
+			// the whole subtree falls in the last bucket
+			e.n.walkNodes(func(n, _ *node, _ int) bool {
+				if !n.dead {
+					counts[e.low]++
+				}
+				return true
+			})
+			continue
+		}
+		b := e.low + sort.Search(len(buckets)-e.low, func(i int) bool {
+			return p.compare(e.n.item, buckets[e.low+i]) < 0
+		})
+		if !e.n.dead {
+			counts[b]++
+		}
+		for child := e.n.child; child != nil; child = child.next {
+			stack = append(stack, entry{child, b})
+		}
+	}
+	return counts
+}
+
+// Len returns the number of items in the heap.
+// The complexity is O(1).
+func (p *PairHeap) Len() int {
+	return p.size - p.dead
+}
+
+// WalkFunc is called for every node visited by Walk and WalkSubtree with the
+// node item, the item of its parent (nil for the starting node) and its depth
+// relative to the starting node. Returning false stops the walk.
+type WalkFunc func(item, parent heap.Item, depth int) bool
+
+// Walk visits the whole tree in depth-first order, exposing its structure.
+// The trees of the items inserted lazily and not linked yet are visited
+// after the tree of the root, each from depth 0; Unlinked counts them.
+// Items deleted lazily are skipped, their children taking their place.
+// Walk does not change the heap, and panics if fn does.
+// The complexity is O(n).
+func (p *PairHeap) Walk(fn WalkFunc) {
+	fn = p.checked(fn)
+	p.trees(func(t *node) bool {
+		return t.walk(fn)
+	})
+}
+
+// Unlinked returns the number of trees that Walk visits after the tree of
+// the root: the items inserted with WithLazyInsert or the Auxiliary
+// strategy that the next read links with the root.
+// The complexity is O(k) for k such trees.
+func (p *PairHeap) Unlinked() int {
+	k := 0
+	for n := p.forest; n != nil; n = n.next {
+		k++
+	}
+	return k
+}
+
+// trees calls fn with the root and then with every tree of the forest,
+// until fn returns false.
+func (p *PairHeap) trees(fn func(t *node) bool) {
+	if p.root != nil && !fn(p.root) {
+		return
+	}
+	for n := p.forest; n != nil; n = n.next {
+		if !fn(n) {
+			return
+		}
+	}
+}
+
+// lookup returns the live node that matches item in the tree of the root
+// or in the forest, without linking the forest, or nil if there is none.
+func (p *PairHeap) lookup(item heap.Item) *node {
+	var found *node
+	p.trees(func(t *node) bool {
+		found = t.findNode(item)
+		return found == nil
+	})
+	return found
+}
+
+// WalkSubtree visits, in depth-first order, the subtree rooted at the node
+// that matches item, like Walk. It returns false if no such node exists.
+// WalkSubtree does not change the heap, and panics if fn does.
+// The complexity is O(n) to locate the node.
+func (p *PairHeap) WalkSubtree(item heap.Item, fn WalkFunc) bool {
+	n := p.lookup(item)
+	if n == nil {
+		return false
+	}
+	n.walk(p.checked(fn))
+	return true
+}
+
+// checked wraps fn so that it panics if it changes *p.
+func (p *PairHeap) checked(fn WalkFunc) WalkFunc {
+	mods := p.mods
+	return func(item, parent heap.Item, depth int) bool {
+		next := fn(item, parent, depth)
+		p.checkMods(mods)
+		return next
+	}
+}
+
+// Children returns a copy of the items held by the direct children of the
+// node that matches item, as seen by Walk, or nil if no such node exists.
+// Children does not change the heap.
+// The complexity is O(n) to locate the node.
+func (p *PairHeap) Children(item heap.Item) []heap.Item {
+	n := p.lookup(item)
+	if n == nil {
+		return nil
+	}
+	children := []heap.Item{}
+	n.walkLive(func(c, _ *node, depth int) bool {
+		if depth == 1 {
+			children = append(children, c.item)
+		}
+		return true
+	})
+	return children
+}
+
+// DetectDegenerate reports the size and maximum depth of the trees seen by
+// Walk against log2(n). Long chains build up when items are inserted in
+// decreasing order and no DeleteMin has consolidated the tree yet.
+// DetectDegenerate does not change the heap, so the chains it reports are
+// still there afterwards.
+// The complexity is O(n).
+func (p *PairHeap) DetectDegenerate() heap.Health {
+	var size, maxDepth int
+	p.trees(func(t *node) bool {
+		return t.walkLive(func(_, _ *node, depth int) bool {
+			size++
+			if depth+1 > maxDepth {
+				maxDepth = depth + 1
+			}
+			return true
+		})
+	})
+	return heap.NewHealth(size, maxDepth)
 }
 
 // Return the heap formed by taking the union of the item disjoint
 // current heap and a that is of the same type
+// The paused segments of a move to p, and the items of a matching a
+// segment paused in p are held in it, as if they were inserted into p.
 func (p *PairHeap) Meld(a heap.Interface) heap.Interface {
 	if a == nil {
 		return p
@@ -231,21 +889,20 @@ func (p *PairHeap) Meld(a heap.Interface) heap.Interface {
 	switch a.(type) {
 	case *PairHeap:
 		h := a.(*PairHeap)
+		p.adopt(h)
+		p.withhold(h)
 		if h.IsEmpty() {
 			return p
 		}
-		if p.IsEmpty() {
-			p.root = h.root
-			h.Clear()
-			return p
-		}
-		if p.FindMin().Compare(h.FindMin()) > 0 {
-			h.root = merge(h.root, p.root)
-			p.root = h.root
-			h.Clear()
-		} else {
-			p.root = merge(p.root, h.root)
-		}
+		p.consolidate()
+		h.consolidate()
+		before := p.minState()
+		defer p.notify(before)
+		p.root = p.merge(p.root, h.root)
+		p.mods++
+		p.size += h.size
+		p.dead += h.dead
+		h.Clear()
 
 	default:
 		panic(fmt.Sprintf("unexpected type %T", a))
@@ -254,47 +911,144 @@ func (p *PairHeap) Meld(a heap.Interface) heap.Interface {
 	return p
 }
 
-func merge(a, b *node) *node {
-	if a.item == nil { // Case when root is empty
-		a = b
-		return a
+// MeldAll melds every heap of hs into p at once, pairing their roots with
+// the configured strategy instead of melding them one by one, and returns p.
+// The heaps of hs, which must be of the same type, are left empty, and
+// their paused segments are moved to p as by Meld.
+// The complexity is O(k) for k heaps.
+func (p *PairHeap) MeldAll(hs ...heap.Interface) heap.Interface {
+	var first, last *node
+	link := func(root *node) {
+		if first == nil {
+			first = root
+		} else {
+			last.next, root.prev = root, last
+		}
+		last = root
+	}
+	for _, a := range hs {
+		if _, ok := a.(*PairHeap); a != nil && !ok {
+			panic(fmt.Sprintf("unexpected type %T", a))
+		}
+	}
+	p.consolidate()
+	if p.root != nil {
+		link(p.root)
+	}
+	for _, a := range hs {
+		h, _ := a.(*PairHeap)
+		if h == nil || h == p {
+			continue
+		}
+		p.adopt(h)
+		p.withhold(h)
+		if !h.IsEmpty() {
+			h.consolidate()
+			link(h.root)
+			p.size += h.size
+			p.dead += h.dead
+			h.Clear()
+		}
+	}
+	if first == nil || first == p.root && first.next == nil {
+		return p
 	}
 
-	if a.item.Compare(b.item) < 0 {
-		// put 'second' as the first child of 'first' and update the parent
-		a.children = append([]*node{b}, a.children...)
-		b.parent = a
-		return a
-	} else {
-		// put 'first' as the first child of 'second' and update the parent
-		b.children = append([]*node{a}, b.children...)
-		a.parent = b
+	before := p.minState()
+	p.root = p.mergePairs(first)
+	p.mods++
+	p.notify(before)
+	return p
+}
+
+// less reports whether a should be the parent of b.
+func (p *PairHeap) less(a, b *node) bool {
+	cmp := p.compare(a.item, b.item)
+	if cmp == 0 && p.stable {
+		return a.seq < b.seq
+	}
+	return cmp < 0
+}
+
+// merge melds two heaps given by their roots and returns the new root.
+func (p *PairHeap) merge(a, b *node) *node {
+	if a == nil {
 		return b
 	}
+	if b == nil {
+		return a
+	}
+	if !p.less(a, b) {
+		a, b = b, a
+	}
+	// put 'b' as the leftmost child of 'a'
+	b.next = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	b.prev = a
+	return a
 }
 
-// Merges heaps together
-func mergePairs(root *node, heaps []*node) *node {
-	if len(heaps) == 1 {
-		root = heaps[0]
-		heaps[0].parent = nil
-		return root
+// mergePairs melds the sibling list starting at first into a single heap
+// according to the configured strategy and returns its root.
+func (p *PairHeap) mergePairs(first *node) *node {
+	if p.strategy == MultiPass {
+		return p.multiPass(first)
 	}
-	var merged *node
-	for { // iteratively merge heaps
-		if len(heaps) == 0 {
-			break
-		}
-		if merged == nil {
-			merged = merge(heaps[0], heaps[1])
-			heaps = heaps[2:]
+	first.prev = nil
+
+	// first pass: link pairs from left to right, stacking the results
+	var pairs *node
+	for first != nil {
+		a, b := first, first.next
+		if b == nil {
+			first = nil
 		} else {
-			merged = merge(merged, heaps[0])
-			heaps = heaps[1:]
+			first = b.next
+			a.next, b.prev, b.next = nil, nil, nil
+			a = p.merge(a, b)
 		}
+		a.prev = nil
+		a.next = pairs
+		pairs = a
 	}
-	root = merged
-	merged.parent = nil
+	// second pass: meld the pairs from right to left
+	merged := pairs
+	pairs = pairs.next
+	merged.next = nil
+	for pairs != nil {
+		next := pairs.next
+		pairs.next = nil
+		merged = p.merge(pairs, merged)
+		pairs = next
+	}
+	return merged
+}
 
-	return root
+// multiPass melds the sibling list starting at first into a single heap by
+// repeatedly linking the first two sub-heaps of a queue and appending the
+// result to its end, and returns its root.
+func (p *PairHeap) multiPass(first *node) *node {
+	first.prev = nil
+	// queue the sub-heaps through their next links
+	head, tail := first, first
+	for tail.next != nil {
+		tail = tail.next
+	}
+	for head != tail {
+		a, b := head, head.next
+		head = b.next
+		a.next, b.prev, b.next = nil, nil, nil
+		merged := p.merge(a, b)
+		merged.prev = nil
+		if head == nil {
+			return merged
+		}
+		tail.next = merged
+		tail = merged
+	}
+	head.prev = nil
+	return head
 }
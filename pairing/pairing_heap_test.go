@@ -1,10 +1,19 @@
 package pairing
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"github.com/stretchr/testify/suite"
 	"github.com/stretchr/testify/assert"
 	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/heaptest"
 	"fmt"
 	"math/rand"
 	"time"
@@ -181,6 +190,65 @@ func (suite *PairingHeapTestSuite) TestAdjust() {
 	testMinHeapInvariance(suite)
 }
 
+func TestAdjustDirections(t *testing.T) {
+	const state = `pairing-heap v2
+0 1 "0"
+1 2 "10"
+2 4 "12"
+3 5 "13"
+2 3 "11"
+1 6 "14"
+`
+	tests := []struct {
+		name      string
+		item, new int
+		want      []int
+	}{
+		{"decrease interior with subtree", 12, 5, []int{0, 5, 10, 11, 13, 14}},
+		{"decrease below the root", 12, -1, []int{-1, 0, 10, 11, 13, 14}},
+		{"increase interior past its children", 10, 20, []int{0, 11, 12, 13, 14, 20}},
+		{"increase the root", 0, 15, []int{10, 11, 12, 13, 14, 15}},
+		{"increase a leaf", 13, 16, []int{0, 10, 11, 12, 14, 16}},
+		{"same key", 11, 11, []int{0, 10, 11, 12, 13, 14}},
+	}
+	for _, tc := range tests {
+		p := New()
+		assert.NoError(t, p.LoadState(strings.NewReader(state), parseInt))
+		assert.NotNil(t, p.Adjust(Int(tc.item), Int(tc.new)), tc.name)
+		assert.Equal(t, len(tc.want), checkStructure(t, p), tc.name)
+		var got []int
+		for !p.IsEmpty() {
+			got = append(got, int(p.DeleteMin().(heap.Integer)))
+		}
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var p PairHeap
+	assert.True(t, p.IsEmpty())
+	assert.Nil(t, p.FindMin())
+	assert.Nil(t, p.DeleteMin())
+	assert.Nil(t, p.Delete(Int(1)))
+	assert.Nil(t, p.Adjust(Int(1), Int(2)))
+	assert.Nil(t, p.Find(Int(1)))
+	p.Do(func(heap.Item) bool { return true })
+
+	for _, v := range perm(20) {
+		p.Insert(v)
+	}
+	other := PairHeap{}
+	other.Insert(Int(-1))
+	p.Meld(&other)
+	p.Meld(&PairHeap{})
+	assert.True(t, other.IsEmpty())
+	assert.Equal(t, Int(-1), p.DeleteMin())
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, Int(i), p.DeleteMin())
+	}
+	assert.True(t, p.IsEmpty())
+}
+
 func (suite *PairingHeapTestSuite) TestDelete() {
 	for _, v := range rang(10) {
 		suite.heap.Insert(v)
@@ -192,6 +260,1533 @@ func (suite *PairingHeapTestSuite) TestDelete() {
 	assert.Nil(suite.T(), suite.heap.DeleteMin())
 }
 
+func (suite *PairingHeapTestSuite) TestDeleteReattachedChild() {
+	suite.heap.Insert(Int(0))
+	suite.heap.Insert(Int(1))
+	suite.heap.Insert(Int(2))
+	suite.heap.Delete(Int(0))
+	suite.heap.Insert(Int(-1))
+
+	// 2 was re-attached to the root when 1 was removed
+	assert.Equal(suite.T(), Int(1), suite.heap.Delete(Int(1)))
+	assert.Equal(suite.T(), Int(2), suite.heap.Delete(Int(2)))
+	assert.Nil(suite.T(), suite.heap.Find(Int(2)))
+	assert.Equal(suite.T(), Int(-1), suite.heap.DeleteMin())
+	assert.Nil(suite.T(), suite.heap.DeleteMin())
+}
+
+func (suite *PairingHeapTestSuite) TestWalk() {
+	for _, v := range perm(50) {
+		suite.heap.Insert(v)
+	}
+	suite.heap.DeleteMin()
+
+	var visited []heap.Item
+	suite.heap.Walk(func(item, parent heap.Item, depth int) bool {
+		if parent == nil {
+			assert.Equal(suite.T(), 0, depth)
+		} else {
+			assert.True(suite.T(), parent.Compare(item) <= 0)
+			assert.True(suite.T(), depth > 0)
+		}
+		visited = append(visited, item)
+		return true
+	})
+	assert.ElementsMatch(suite.T(), visited, rang(50)[1:])
+}
+
+func (suite *PairingHeapTestSuite) TestWalkSubtree() {
+	assert.False(suite.T(), suite.heap.WalkSubtree(Int(1), func(_, _ heap.Item, _ int) bool {
+		return true
+	}))
+
+	for _, v := range rang(10) {
+		suite.heap.Insert(v)
+	}
+	suite.heap.DeleteMin()
+
+	children := suite.heap.Children(suite.heap.FindMin())
+	assert.NotEmpty(suite.T(), children)
+	assert.Nil(suite.T(), suite.heap.Children(Int(100)))
+
+	for _, child := range children {
+		var size int
+		found := suite.heap.WalkSubtree(child, func(item, parent heap.Item, depth int) bool {
+			if depth == 0 {
+				assert.Equal(suite.T(), child, item)
+				assert.Nil(suite.T(), parent)
+			}
+			size++
+			return true
+		})
+		assert.True(suite.T(), found)
+		assert.True(suite.T(), size >= 1)
+	}
+
+	var count int
+	suite.heap.Walk(func(_, _ heap.Item, _ int) bool {
+		count++
+		return count < 3
+	})
+	assert.Equal(suite.T(), 3, count)
+}
+
+func TestStrategies(t *testing.T) {
+	for _, strategy := range []Strategy{TwoPass, MultiPass, Auxiliary} {
+		p := New(WithStrategy(strategy))
+		for _, v := range perm(200) {
+			p.Insert(v)
+		}
+		for i := 0; i < 50; i++ {
+			assert.Equal(t, Int(i), p.DeleteMin())
+		}
+		for _, v := range perm(50) {
+			p.Insert(v)
+		}
+		var got []heap.Item
+		for v := p.DeleteMin(); v != nil; v = p.DeleteMin() {
+			got = append(got, v)
+		}
+		assert.Len(t, got, 200)
+		for i := 1; i < len(got); i++ {
+			assert.True(t, got[i-1].Compare(got[i]) <= 0)
+		}
+	}
+}
+
+func TestStable(t *testing.T) {
+	p := New(WithStable())
+	for i := 0; i < 100; i++ {
+		p.Insert(heap.KV(Int(i%3), i))
+	}
+	last := map[heap.Item]int{}
+	for v := p.DeleteMin(); v != nil; v = p.DeleteMin() {
+		pair := v.(heap.Pair)
+		if prev, ok := last[pair.Key]; ok {
+			assert.True(t, prev < pair.Value.(int), "equal keys must come out in insertion order")
+		}
+		last[pair.Key] = pair.Value.(int)
+	}
+}
+
+func TestFindByKey(t *testing.T) {
+	p := New()
+	for i, v := range perm(10) {
+		p.Insert(heap.KV(v, i))
+	}
+	for _, v := range perm(10) {
+		found := p.Find(v)
+		if assert.IsType(t, heap.Pair{}, found) {
+			assert.Equal(t, v, found.(heap.Pair).Key)
+		}
+		assert.True(t, p.Contains(v))
+	}
+	assert.Nil(t, p.Find(Int(10)))
+
+	if deleted, ok := p.Delete(Int(3)).(heap.Pair); assert.True(t, ok) {
+		assert.Equal(t, Int(3), deleted.Key)
+	}
+	p.Adjust(Int(4), heap.KV(Int(-1), "adjusted"))
+	assert.Equal(t, "adjusted", p.FindMin().(heap.Pair).Value)
+	assert.Nil(t, p.Find(Int(3)))
+}
+
+func TestPool(t *testing.T) {
+	p := New(WithPool())
+	for round := 0; round < 3; round++ {
+		for _, v := range perm(100) {
+			p.Insert(v)
+		}
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, Int(i), p.DeleteMin())
+		}
+		assert.True(t, p.IsEmpty())
+	}
+}
+
+func TestReset(t *testing.T) {
+	var highs, lows int
+	p := New(WithPool(), WithStable(), WithOrder(Descending), WithLazyInsert(),
+		OnHighWatermark(50, func(int) { highs++ }), OnLowWatermark(10, func(int) { lows++ }))
+	for round := 0; round < 3; round++ {
+		for _, v := range perm(100) {
+			p.Insert(v)
+		}
+		p.Delete(Int(50))
+		p.Reset()
+		assert.True(t, p.IsEmpty())
+		assert.Equal(t, 0, p.Len())
+		assert.Equal(t, uint64(0), p.seq)
+	}
+	assert.Equal(t, 3, highs)
+	assert.Equal(t, 3, lows)
+
+	// the options are kept
+	for _, v := range perm(10) {
+		p.Insert(v)
+	}
+	for i := 9; i >= 0; i-- {
+		assert.Equal(t, Int(i), p.DeleteMin())
+	}
+}
+
+// bomb panics when compared.
+type bomb struct{}
+
+func (bomb) Compare(heap.Item) int { panic("boom") }
+
+func TestComparePanic(t *testing.T) {
+	recovered := func(f func()) (err *CompareError) {
+		defer func() {
+			err, _ = recover().(*CompareError)
+		}()
+		f()
+		return nil
+	}
+	for _, opts := range [][]Option{nil, {WithStrategy(MultiPass)}, {WithStrategy(Auxiliary)},
+		{WithLazyInsert()}, {WithPool(), WithLazyDelete(0.5)}} {
+		p := New(opts...)
+		for _, v := range perm(100) {
+			p.Insert(v)
+		}
+		p.DeleteMin()
+		p.Delete(Int(40))
+
+		err := recovered(func() { p.Insert(bomb{}) })
+		if assert.NotNil(t, err, fmt.Sprint(opts)) {
+			assert.NotNil(t, err.Value)
+			assert.Contains(t, err.Error(), "pairing: comparing")
+		}
+		assert.Equal(t, 98, p.Len())
+		checkStructure(t, p)
+
+		for _, v := range []int{1, 50, 99} {
+			err = recovered(func() { p.Adjust(Int(v), bomb{}) })
+			assert.NotNil(t, err, fmt.Sprint(opts, v))
+			assert.Equal(t, 98, p.Len())
+			checkStructure(t, p)
+		}
+		for i := 1; i < 100; i++ {
+			if i != 40 {
+				assert.Equal(t, Int(i), p.DeleteMin())
+			}
+		}
+		assert.True(t, p.IsEmpty())
+
+		// the heap is still usable
+		p.Insert(Int(3))
+		assert.Equal(t, Int(3), p.FindMin())
+	}
+}
+
+func TestWithItemType(t *testing.T) {
+	p := New(WithItemType(reflect.TypeOf(Int(0))))
+	for _, v := range perm(10) {
+		assert.NoError(t, p.TryInsert(v))
+	}
+
+	err := p.TryInsert(heap.String("a"))
+	if assert.IsType(t, &ItemTypeError{}, err) {
+		assert.Equal(t, heap.String("a"), err.(*ItemTypeError).Item)
+		assert.Equal(t, "pairing: item a of type go_heaps.String, want go_heaps.Integer", err.Error())
+	}
+	assert.Panics(t, func() { p.Insert(heap.String("a")) })
+	assert.Panics(t, func() { p.PushMany(Int(10), heap.String("a")) })
+	assert.Panics(t, func() { p.Adjust(Int(5), heap.String("a")) })
+	assert.Panics(t, func() { p.Update(Int(5), func(heap.Item) heap.Item { return nil }) })
+	assert.Equal(t, 10, p.Len())
+	checkStructure(t, p)
+	p.Adjust(Int(5), Int(-1))
+	assert.Equal(t, Int(-1), p.FindMin())
+
+	// an interface type admits the types implementing it
+	q := New(WithItemType(reflect.TypeOf((*heap.Item)(nil)).Elem()))
+	assert.NoError(t, q.TryInsert(Int(1)))
+	assert.Error(t, q.TryInsert(nil))
+}
+
+func TestOnMinChanged(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithPool()}} {
+		var changes [][2]heap.Item
+		p := New(append(opts, OnMinChanged(func(old, new heap.Item) {
+			changes = append(changes, [2]heap.Item{old, new})
+		}))...)
+
+		p.Insert(Int(5))
+		p.Insert(Int(7)) // no change
+		p.Insert(Int(3))
+		p.Delete(Int(7)) // no change
+		p.Adjust(Int(3), Int(4))
+		p.Adjust(Int(5), Int(2))
+		p.DeleteMin()
+
+		other := New()
+		other.Insert(Int(1))
+		p.Meld(other)
+		p.Meld(New()) // no change
+		p.Clear()
+		p.Clear() // no change
+
+		assert.Equal(t, [][2]heap.Item{
+			{nil, Int(5)},
+			{Int(5), Int(3)},
+			{Int(3), Int(4)},
+			{Int(4), Int(2)},
+			{Int(2), Int(4)},
+			{Int(4), Int(1)},
+			{Int(1), nil},
+		}, changes)
+	}
+}
+
+func (suite *PairingHeapTestSuite) TestExtractSubtree() {
+	assert.Nil(suite.T(), suite.heap.ExtractSubtree(Int(1)))
+
+	group := New()
+	for _, v := range []int{10, 12, 11} {
+		group.Insert(Int(v))
+	}
+	for _, v := range []int{4, 2, 6} {
+		suite.heap.Insert(Int(v))
+	}
+	suite.heap.Meld(group)
+
+	sub := suite.heap.ExtractSubtree(Int(10))
+	assert.NotNil(suite.T(), sub)
+	assert.Nil(suite.T(), suite.heap.ExtractSubtree(Int(42)))
+	assert.Nil(suite.T(), suite.heap.Find(Int(11)))
+
+	for _, v := range []int{10, 11, 12} {
+		assert.Equal(suite.T(), Int(v), sub.DeleteMin())
+	}
+	assert.True(suite.T(), sub.IsEmpty())
+	for _, v := range []int{2, 4, 6} {
+		assert.Equal(suite.T(), Int(v), suite.heap.DeleteMin())
+	}
+	assert.True(suite.T(), suite.heap.IsEmpty())
+
+	suite.heap.Insert(Int(1))
+	suite.heap.Insert(Int(3))
+	sub = suite.heap.ExtractSubtree(Int(1))
+	assert.True(suite.T(), suite.heap.IsEmpty())
+	assert.Equal(suite.T(), Int(1), sub.DeleteMin())
+	assert.Equal(suite.T(), Int(3), sub.DeleteMin())
+}
+
+func TestSplit(t *testing.T) {
+	for _, pivot := range []int{-1, 0, 42, 99, 150} {
+		p := New()
+		for _, v := range perm(100) {
+			p.Insert(v)
+		}
+		// shape the tree a little before splitting
+		p.DeleteMin()
+		p.Insert(Int(0))
+
+		le, gt := p.Split(Int(pivot))
+		assert.True(t, p.IsEmpty())
+		checkStructure(t, le)
+		checkStructure(t, gt)
+		for i := 0; i < 100; i++ {
+			if i <= pivot {
+				assert.Equal(t, Int(i), le.DeleteMin())
+			} else {
+				assert.Equal(t, Int(i), gt.DeleteMin())
+			}
+		}
+		assert.True(t, le.IsEmpty())
+		assert.True(t, gt.IsEmpty())
+	}
+
+	le, gt := New().Split(Int(0))
+	assert.True(t, le.IsEmpty())
+	assert.True(t, gt.IsEmpty())
+}
+
+func TestDoSorted(t *testing.T) {
+	p := New()
+	p.DoSorted(func(heap.Item) bool {
+		t.Fatal("visited an empty heap")
+		return false
+	})
+
+	for _, v := range perm(100) {
+		p.Insert(v)
+		p.Insert(v)
+	}
+	p.DeleteMin()
+
+	var got []heap.Item
+	p.DoSorted(func(item heap.Item) bool {
+		got = append(got, item)
+		return true
+	})
+	assert.Len(t, got, 199)
+	for i, item := range got {
+		assert.Equal(t, Int((i+1)/2), item)
+	}
+
+	count := 0
+	p.DoSorted(func(item heap.Item) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+	assert.Equal(t, Int(0), p.DeleteMin())
+}
+
+func TestModifiedDuringIteration(t *testing.T) {
+	p := New()
+	for _, v := range perm(10) {
+		p.Insert(v)
+	}
+
+	iterations := map[string]func(mutate func()){
+		"Do": func(mutate func()) {
+			p.Do(func(heap.Item) bool { mutate(); return true })
+		},
+		"DoSorted": func(mutate func()) {
+			p.DoSorted(func(heap.Item) bool { mutate(); return true })
+		},
+		"Walk": func(mutate func()) {
+			p.Walk(func(_, _ heap.Item, _ int) bool { mutate(); return true })
+		},
+		"WalkSubtree": func(mutate func()) {
+			p.WalkSubtree(p.FindMin(), func(_, _ heap.Item, _ int) bool { mutate(); return true })
+		},
+	}
+	for name, iterate := range iterations {
+		assert.Panics(t, func() {
+			iterate(func() { p.Insert(Int(100)) })
+		}, name)
+		assert.Panics(t, func() {
+			iterate(func() { p.DeleteMin() })
+		}, name)
+		assert.NotPanics(t, func() {
+			iterate(func() { p.Find(Int(3)) })
+		}, name)
+	}
+}
+
+func parseInt(s string) (heap.Item, error) {
+	v, err := strconv.Atoi(s)
+	return Int(v), err
+}
+
+func TestDumpState(t *testing.T) {
+	p := New(WithStable())
+	for _, v := range perm(50) {
+		p.Insert(v)
+	}
+	p.DeleteMin()
+	p.Delete(Int(25))
+
+	var dump bytes.Buffer
+	assert.NoError(t, p.DumpState(&dump))
+
+	q := New(WithStable())
+	q.Insert(Int(100))
+	assert.NoError(t, q.LoadState(bytes.NewReader(dump.Bytes()), parseInt))
+	checkStructure(t, q)
+
+	var again bytes.Buffer
+	assert.NoError(t, q.DumpState(&again))
+	assert.Equal(t, dump.String(), again.String())
+
+	// new items sort after the restored ones that compare equal
+	q.root.walkNodes(func(n, _ *node, _ int) bool {
+		assert.True(t, n.seq <= q.seq)
+		return true
+	})
+	for !p.IsEmpty() {
+		assert.Equal(t, p.DeleteMin(), q.DeleteMin())
+	}
+	assert.True(t, q.IsEmpty())
+
+	empty := New()
+	dump.Reset()
+	assert.NoError(t, empty.DumpState(&dump))
+	assert.Equal(t, "pairing-heap v2\n", dump.String())
+	assert.NoError(t, q.LoadState(&dump, parseInt))
+	assert.True(t, q.IsEmpty())
+}
+
+func TestDumpStateDeep(t *testing.T) {
+	// decreasing inserts link every node under the next: a chain
+	const n = 40000
+	p := New(WithStable())
+	for i := n; i > 0; i-- {
+		p.Insert(Int(i))
+	}
+	var dump bytes.Buffer
+	assert.NoError(t, p.DumpState(&dump))
+	assert.True(t, dump.Len() < 20*n, fmt.Sprint("dump of ", dump.Len(), " bytes"))
+
+	q := New(WithStable())
+	assert.NoError(t, q.LoadState(&dump, parseInt))
+	assert.Equal(t, n, checkStructure(t, q))
+	for i := 1; i <= n; i++ {
+		assert.Equal(t, Int(i), q.DeleteMin())
+	}
+
+	// lines are not limited in length
+	long := strings.Repeat("9", 100000)
+	assert.NoError(t, q.LoadState(strings.NewReader("pairing-heap v2\n0 1 \""+long+"\""), func(s string) (heap.Item, error) {
+		return heap.String(s), nil
+	}))
+	assert.Equal(t, heap.String(long), q.FindMin())
+}
+
+func TestReadState(t *testing.T) {
+	var nodes []StateNode
+	err := ReadState(strings.NewReader("pairing-heap v2\n0 3 \"1\"\n1 5 \"a b\"\n2 1 \"7\"\n1 2 \"2\"\n"), func(n StateNode) error {
+		nodes = append(nodes, n)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []StateNode{{0, 3, "1"}, {1, 5, "a b"}, {2, 1, "7"}, {1, 2, "2"}}, nodes)
+
+	err = ReadState(strings.NewReader("pairing-heap v2\n0 3 \"1\"\n"), func(StateNode) error {
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "pairing: line 2: stop")
+}
+
+func TestLoadStateErrors(t *testing.T) {
+	states := []string{
+		"",
+		"1 \"1\"\n",
+		"pairing-heap v1\n1 \"1\"\n",
+		"pairing-heap v2\n0 1 \"x\"\n",
+		"pairing-heap v2\n0 x \"1\"\n",
+		"pairing-heap v2\n1 1 \"1\"\n",
+		"pairing-heap v2\n0 1 \"1\"\n0 2 \"2\"\n",
+		"pairing-heap v2\n0 1 \"1\"\n2 2 \"2\"\n",
+		"pairing-heap v2\n0 1 \"1\"\n-1 2 \"2\"\n",
+		"pairing-heap v2\nx 1 \"1\"\n",
+		"pairing-heap v2\n1 \"1\"\n",
+		"pairing-heap v2\n0 1 1\n",
+	}
+	p := New()
+	p.Insert(Int(3))
+	for _, state := range states {
+		assert.Error(t, p.LoadState(strings.NewReader(state), parseInt), state)
+		assert.Equal(t, Int(3), p.FindMin())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var changes int
+	p := New(OnMinChanged(func(old, new heap.Item) { changes++ }))
+	assert.Nil(t, p.Update(Int(1), func(heap.Item) heap.Item { return Int(2) }))
+
+	want := map[int]int{}
+	for _, v := range perm(100) {
+		p.Insert(v)
+		want[int(v.(heap.Integer))]++
+	}
+	p.DeleteMin()
+	p.Insert(Int(0))
+	changes = 0
+
+	shift := func(by int) func(heap.Item) heap.Item {
+		return func(item heap.Item) heap.Item {
+			return item.(heap.Integer) + heap.Integer(by)
+		}
+	}
+	updates := []struct{ item, by int }{
+		{50, -60}, {0, 200}, {99, -49}, {-10, 0}, {10, 5}, {1, 1000},
+	}
+	for _, u := range updates {
+		assert.Equal(t, Int(u.item+u.by), p.Update(Int(u.item), shift(u.by)))
+		checkStructure(t, p)
+		want[u.item]--
+		want[u.item+u.by]++
+	}
+	assert.Nil(t, p.Update(Int(500), shift(1)))
+	// -10 became the min, then was replaced in place
+	assert.Equal(t, 2, changes)
+
+	var got []int
+	for !p.IsEmpty() {
+		got = append(got, int(p.DeleteMin().(heap.Integer)))
+	}
+	var expected []int
+	for v := -10; v <= 1001; v++ {
+		for i := 0; i < want[v]; i++ {
+			expected = append(expected, v)
+		}
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestContains(t *testing.T) {
+	p := New()
+	assert.False(t, p.Contains(Int(0)))
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	p.DeleteMin()
+	p.Delete(Int(40))
+	for i := -1; i <= 100; i++ {
+		assert.Equal(t, i > 0 && i < 100 && i != 40, p.Contains(Int(i)), fmt.Sprint(i))
+	}
+}
+
+func TestUnmeld(t *testing.T) {
+	p := New()
+	for _, v := range perm(50) {
+		p.Insert(v)
+	}
+	for _, base := range []int{100, 200} {
+		h := New()
+		for _, v := range rand.Perm(30) {
+			h.Insert(Int(base + v))
+		}
+		p.MeldTagged(h, base)
+		assert.True(t, h.IsEmpty())
+	}
+	for i := 0; i < 20; i++ {
+		p.DeleteMin()
+	}
+	// removed items are not reclaimed, reinserted ones are no longer tagged
+	p.Delete(Int(105))
+	p.Delete(Int(110))
+	p.Insert(Int(110))
+
+	assert.True(t, p.Unmeld(300).IsEmpty())
+	drain := func(h *PairHeap) (out []int) {
+		for !h.IsEmpty() {
+			out = append(out, int(h.DeleteMin().(heap.Integer)))
+		}
+		return
+	}
+	span := func(from, to int, skip ...int) (out []int) {
+	next:
+		for v := from; v < to; v++ {
+			for _, s := range skip {
+				if v == s {
+					continue next
+				}
+			}
+			out = append(out, v)
+		}
+		return
+	}
+
+	b := p.Unmeld(200)
+	checkStructure(t, p)
+	checkStructure(t, b)
+	a := p.Unmeld(100)
+	checkStructure(t, p)
+	assert.Equal(t, span(200, 230), drain(b))
+	assert.Equal(t, span(100, 130, 105, 110), drain(a))
+	assert.Equal(t, append(span(20, 50), 110), drain(p))
+}
+
+func TestDrainContext(t *testing.T) {
+	p := New()
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.DrainContext(ctx)
+	assert.True(t, p.IsEmpty())
+
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	var changes int
+	p.onMinChanged = func(_, _ heap.Item) {
+		if changes++; changes == 10 {
+			cancel()
+		}
+	}
+	items, err := p.DrainContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, rang(10), items)
+	assert.Equal(t, Int(10), p.FindMin())
+
+	p.onMinChanged = nil
+	assert.Equal(t, rang(100)[10:], p.Drain())
+	assert.True(t, p.IsEmpty())
+	assert.Nil(t, p.Drain())
+}
+
+func TestMeldAll(t *testing.T) {
+	for _, strategy := range []Strategy{TwoPass, MultiPass, Auxiliary} {
+		p := New(WithStrategy(strategy))
+		p.Insert(Int(50))
+		var hs []heap.Interface
+		for i := 0; i < 10; i++ {
+			h := New()
+			for j := 0; j < 10; j++ {
+				if v := 10*j + i; v != 50 {
+					h.Insert(Int(v))
+				}
+			}
+			hs = append(hs, h)
+		}
+		hs = append(hs, New(), nil, p)
+
+		assert.Equal(t, p, p.MeldAll(hs...))
+		assert.Equal(t, 100, checkStructure(t, p))
+		for _, h := range hs[:10] {
+			assert.True(t, h.(*PairHeap).IsEmpty())
+		}
+		assert.Equal(t, rang(100), p.Drain())
+	}
+
+	p := New()
+	assert.Panics(t, func() { p.MeldAll(New(), otherHeap{}) })
+}
+
+func TestPushPopMany(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithStrategy(TwoPass)},
+		{WithStrategy(MultiPass)},
+		{WithLazyInsert()},
+	} {
+		changes := 0
+		p := New(append(opts, OnMinChanged(func(old, new heap.Item) { changes++ }))...)
+		p.Insert(Int(50))
+		p.PushMany(perm(100)...)
+		p.PushMany()
+		assert.Equal(t, 2, changes)
+		assert.Equal(t, 101, p.Len())
+
+		assert.Equal(t, []heap.Item{Int(0), Int(1), Int(2)}, p.PopMany(3))
+		assert.Equal(t, 3, changes)
+		assert.Equal(t, 98, checkStructure(t, p))
+		assert.Empty(t, p.PopMany(0))
+		assert.Equal(t, 98, len(p.PopMany(1000)))
+		assert.True(t, p.IsEmpty())
+		assert.Empty(t, p.PopMany(1))
+		assert.Equal(t, 4, changes)
+	}
+
+	// paused items are held back
+	p := New()
+	p.Pause("odd", func(item heap.Item) bool { return item.(heap.Integer)%2 == 1 })
+	p.PushMany(Int(1), Int(2), Int(3), Int(4))
+	assert.Equal(t, []heap.Item{Int(2), Int(4)}, p.PopMany(10))
+	assert.Equal(t, 2, p.Resume("odd"))
+	assert.Equal(t, []heap.Item{Int(1), Int(3)}, p.PopMany(10))
+}
+
+func TestReprioritizeWhere(t *testing.T) {
+	configs := [][]Option{
+		{WithStrategy(TwoPass)},
+		{WithStrategy(MultiPass), WithLazyDelete(0.5)},
+		{WithLazyInsert(), WithStable()},
+		{WithStrategy(Auxiliary)},
+	}
+	for _, opts := range configs {
+		changes := 0
+		p := New(append(opts, OnMinChanged(func(old, new heap.Item) { changes++ }))...)
+		assert.Equal(t, 0, p.ReprioritizeWhere(func(heap.Item) bool { return true }, nil))
+		p.PushMany(perm(1000)...)
+		for i := 0; i < 10; i++ {
+			p.DeleteMin()
+		}
+		p.Delete(Int(500))
+		changes = 0
+
+		// the multiples of 3 move up by 1000, the multiples of 5 down
+		rekeyed := p.ReprioritizeWhere(func(item heap.Item) bool {
+			return item.(heap.Integer)%3 == 0 || item.(heap.Integer)%5 == 0
+		}, func(item heap.Item) heap.Item {
+			if item.(heap.Integer)%3 == 0 {
+				return item.(heap.Integer) + 1000
+			}
+			return item.(heap.Integer) - 1000
+		})
+		var want []int
+		for v := 10; v < 1000; v++ {
+			switch {
+			case v == 500:
+			case v%3 == 0:
+				want = append(want, v+1000)
+			case v%5 == 0:
+				want = append(want, v-1000)
+			default:
+				want = append(want, v)
+			}
+		}
+		sort.Ints(want)
+		assert.Equal(t, 1, changes)
+		assert.Equal(t, 989, p.Len())
+		assert.Equal(t, 989+p.dead, checkStructure(t, p))
+		n := 0
+		for v := 10; v < 1000; v++ {
+			if v != 500 && (v%3 == 0 || v%5 == 0) {
+				n++
+			}
+		}
+		assert.Equal(t, n, rekeyed)
+		for _, v := range want {
+			assert.Equal(t, Int(v), p.DeleteMin())
+		}
+		assert.True(t, p.IsEmpty())
+	}
+
+	p := New()
+	p.PushMany(Int(1), Int(2))
+	assert.Panics(t, func() {
+		p.ReprioritizeWhere(func(heap.Item) bool { p.Insert(Int(3)); return false }, nil)
+	})
+
+	// the heap is left as it was when a new item is not accepted
+	p = New(WithItemType(reflect.TypeOf(Int(0))))
+	p.PushMany(perm(10)...)
+	odd := func(item heap.Item) bool { return item.(heap.Integer)%2 == 1 }
+	for _, f := range []func(heap.Item) heap.Item{
+		func(item heap.Item) heap.Item {
+			if item == Int(7) {
+				panic("f")
+			}
+			return item.(heap.Integer) - 100
+		},
+		func(heap.Item) heap.Item { return nil },
+		func(heap.Item) heap.Item { return heap.String("x") },
+	} {
+		assert.Panics(t, func() { p.ReprioritizeWhere(odd, f) })
+		assert.Equal(t, 10, p.Len())
+		assert.Equal(t, 10, checkStructure(t, p))
+		assert.Equal(t, Int(0), p.FindMin())
+	}
+	assert.Equal(t, 5, p.ReprioritizeWhere(odd, func(item heap.Item) heap.Item { return item.(heap.Integer) - 100 }))
+	assert.Equal(t, Int(-99), p.FindMin())
+}
+
+// otherHeap is a heap of another type.
+type otherHeap struct{ heap.Interface }
+
+func TestQuantile(t *testing.T) {
+	p := New()
+	assert.Nil(t, p.Quantile(0.5))
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	p.Insert(Int(42)) // 101 items, 42 twice
+
+	tests := []struct {
+		q    float64
+		want int
+	}{
+		{0, 0}, {0.001, 0}, {0.01, 1}, {0.25, 25}, {0.42, 42}, {0.43, 42},
+		{0.5, 49}, {0.99, 98}, {1, 99},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, Int(tc.want), p.Quantile(tc.q), fmt.Sprint(tc.q))
+	}
+	assert.Equal(t, 101, checkStructure(t, p))
+	assert.Panics(t, func() { p.Quantile(-0.1) })
+	assert.Panics(t, func() { p.Quantile(1.5) })
+}
+
+func TestHistogram(t *testing.T) {
+	p := New()
+	buckets := []heap.Item{Int(10), Int(20), Int(50)}
+	assert.Equal(t, []int{0, 0, 0, 0}, p.Histogram(buckets))
+
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	p.DeleteMin()
+	p.Insert(Int(10))
+	p.Insert(Int(-5))
+
+	assert.Equal(t, []int{10, 11, 30, 50}, p.Histogram(buckets))
+	assert.Equal(t, []int{101}, p.Histogram(nil))
+	assert.Equal(t, []int{0, 101}, p.Histogram([]heap.Item{Int(-100)}))
+	assert.Equal(t, 101, checkStructure(t, p))
+}
+
+func TestLazyDelete(t *testing.T) {
+	assert.Panics(t, func() { WithLazyDelete(0) })
+	assert.Panics(t, func() { WithLazyDelete(1.5) })
+
+	for _, ratio := range []float64{0.25, 1} {
+		p := New(WithLazyDelete(ratio))
+		for _, v := range perm(200) {
+			p.Insert(v)
+		}
+		p.DeleteMin()
+		p.Insert(Int(0))
+
+		var want []heap.Item
+		for i := 0; i < 200; i++ {
+			if i%4 == 0 {
+				want = append(want, Int(i))
+				continue
+			}
+			assert.Equal(t, Int(i), p.Delete(Int(i)))
+			assert.Nil(t, p.Find(Int(i)))
+			assert.False(t, p.Contains(Int(i)))
+		}
+		assert.Equal(t, 50, p.Len())
+		assert.Nil(t, p.Delete(Int(1)))
+		if ratio == 1 {
+			assert.Equal(t, 150, p.dead)
+		} else {
+			assert.True(t, p.dead <= p.size/4)
+		}
+
+		// deleted items are invisible to the queries
+		var seen []heap.Item
+		p.DoSorted(func(item heap.Item) bool {
+			seen = append(seen, item)
+			return true
+		})
+		assert.Equal(t, want, seen)
+		count := 0
+		p.Do(func(heap.Item) bool { count++; return true })
+		assert.Equal(t, 50, count)
+		assert.Equal(t, Int(96), p.Quantile(0.5))
+		assert.Equal(t, []int{25, 25}, p.Histogram([]heap.Item{Int(100)}))
+
+		p.Compact()
+		assert.Equal(t, 0, p.dead)
+		assert.Equal(t, 50, checkStructure(t, p))
+		assert.Equal(t, want, p.Drain())
+	}
+}
+
+func TestLazyDeleteMin(t *testing.T) {
+	p := New(WithLazyDelete(1))
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	for i := 1; i < 100; i += 2 {
+		p.Delete(Int(i))
+	}
+	other := New()
+	other.Insert(Int(1000))
+	p.Meld(other)
+	assert.Equal(t, 51, p.Len())
+
+	// dead nodes surfacing at the root are popped on the way
+	for i := 0; i < 100; i += 2 {
+		assert.Equal(t, Int(i), p.FindMin())
+		assert.Equal(t, Int(i), p.DeleteMin())
+		assert.Equal(t, p.size, checkStructure(t, p))
+	}
+	assert.Equal(t, Int(1000), p.DeleteMin())
+	assert.True(t, p.IsEmpty())
+	assert.Equal(t, 0, p.Len())
+	assert.Equal(t, 0, p.dead)
+}
+
+func TestRebuild(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithPool()}, {WithLazyDelete(1)}} {
+		p := New(opts...)
+		p.Rebuild()
+		assert.Equal(t, Stats{}, p.Stats())
+
+		for _, v := range perm(1000) {
+			p.Insert(v)
+		}
+		for i := 0; i < 300; i++ {
+			p.DeleteMin()
+			p.Delete(Int(999 - i))
+			p.Insert(Int(999 - i))
+		}
+		assert.True(t, p.Stats().Fragmentation > 0.5, fmt.Sprint(p.Stats()))
+
+		var before bytes.Buffer
+		p.DumpState(&before)
+		p.Rebuild()
+		var after bytes.Buffer
+		p.DumpState(&after)
+		assert.Equal(t, before.String(), after.String())
+		assert.Equal(t, Stats{Len: 700, Nodes: 700}, p.Stats())
+		assert.Equal(t, 700, checkStructure(t, p))
+
+		for i := 300; i < 1000; i++ {
+			assert.Equal(t, Int(i), p.DeleteMin())
+		}
+		assert.True(t, p.IsEmpty())
+	}
+}
+
+func TestRebuildPool(t *testing.T) {
+	p := New(WithPool())
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	p.Rebuild()
+	released := make(chan struct{})
+	runtime.SetFinalizer(p.root, func(*node) { close(released) })
+
+	// removing the items of the block must not pool its nodes, which would
+	// keep the block reachable, while new nodes are still pooled
+	for i := 0; i < 100; i++ {
+		p.Insert(Int(100 + i))
+		assert.Equal(t, Int(i), p.DeleteMin())
+	}
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-released:
+			assert.Equal(t, 100, checkStructure(t, p))
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("the block of Rebuild was not released")
+}
+
+func TestTimestamps(t *testing.T) {
+	p := New()
+	p.Insert(Int(1))
+	_, ok := p.Age(Int(1))
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), p.OldestAge())
+
+	p = New(WithTimestamps(), WithLazyDelete(1))
+	assert.Equal(t, time.Duration(0), p.OldestAge())
+	p.Insert(Int(5))
+	p.Insert(Int(9))
+	time.Sleep(5 * time.Millisecond)
+	mid := time.Now()
+	p.Insert(Int(1))
+	p.Insert(Int(7))
+
+	old, ok := p.Age(Int(5))
+	assert.True(t, ok)
+	young, _ := p.Age(Int(1))
+	assert.True(t, old >= 5*time.Millisecond && young < old, fmt.Sprint(old, young))
+	_, ok = p.Age(Int(3))
+	assert.False(t, ok)
+
+	// the oldest item is not the minimum, and keeps its age when adjusted
+	// or melded
+	assert.True(t, p.OldestAge() >= old)
+	p.Adjust(Int(5), Int(0))
+	age, _ := p.Age(Int(0))
+	assert.True(t, age >= old)
+	q := New()
+	q.Meld(p)
+	assert.True(t, q.OldestAge() >= old)
+
+	q.DeleteMin()
+	q.Delete(Int(9))
+	assert.True(t, q.OldestAge() <= time.Since(mid), fmt.Sprint(q.OldestAge()))
+}
+
+func TestWatermarks(t *testing.T) {
+	var events []string
+	record := func(name string) func(int) {
+		return func(size int) { events = append(events, fmt.Sprint(name, size)) }
+	}
+	p := New(OnHighWatermark(5, record("high")), OnLowWatermark(2, record("low")))
+	for i := 0; i < 10; i++ {
+		p.Insert(Int(i))
+	}
+	for i := 0; i < 6; i++ {
+		p.DeleteMin()
+	}
+	p.Insert(Int(0))
+	for i := 0; i < 3; i++ {
+		p.Delete(Int(9 - i))
+	}
+	assert.Equal(t, []string{"high5", "low2"}, events)
+
+	// bulk operations report a single crossing
+	events = nil
+	q := New()
+	for i := 0; i < 8; i++ {
+		q.Insert(Int(i))
+	}
+	p.Meld(q)
+	le, _ := p.Split(Int(3))
+	p.Meld(le)
+	p.Clear()
+	assert.Equal(t, []string{"high10", "low0", "high5", "low0"}, events)
+
+	// with a single watermark the other threshold is next to it
+	events = nil
+	p = New(OnHighWatermark(3, record("high")))
+	for _, v := range []int{1, 2, 3, 4} {
+		p.Insert(Int(v))
+	}
+	p.DeleteMin()
+	p.DeleteMin()
+	p.Insert(Int(1))
+	assert.Equal(t, []string{"high3", "high3"}, events)
+
+	events = nil
+	p = New(OnLowWatermark(1, record("low")))
+	p.Insert(Int(1))
+	p.Insert(Int(2))
+	p.Drain()
+	assert.Equal(t, []string{"low1"}, events)
+
+	assert.Panics(t, func() {
+		New(OnHighWatermark(3, record("high")), OnLowWatermark(3, record("low")))
+	})
+}
+
+func TestAuxiliary(t *testing.T) {
+	p := New(WithStrategy(Auxiliary))
+	for _, v := range perm(20) {
+		p.Insert(v)
+	}
+	assert.Nil(t, p.root)
+	assert.Equal(t, 20, checkStructure(t, p))
+
+	// a read pairs the buffered items up into the tree
+	assert.Equal(t, Int(0), p.DeleteMin())
+	assert.Nil(t, p.forest)
+
+	// decreased subtrees are buffered too
+	p.Adjust(Int(15), Int(-1))
+	assert.NotNil(t, p.forest)
+	assert.Equal(t, Int(-1), p.forestMin.item)
+	assert.Equal(t, 19, checkStructure(t, p))
+	assert.Equal(t, Int(-1), p.FindMin())
+	assert.Nil(t, p.forest)
+
+	var got []heap.Item
+	for v := p.DeleteMin(); v != nil; v = p.DeleteMin() {
+		got = append(got, v)
+	}
+	assert.Equal(t, append([]heap.Item{Int(-1)}, append(rang(15)[1:], rang(20)[16:]...)...), got)
+}
+
+func TestDiagnosticsReadOnly(t *testing.T) {
+	// the diagnostics visit the forest without linking it
+	p := New(WithLazyInsert())
+	for _, v := range []int{5, 3, 8} {
+		p.Insert(Int(v))
+	}
+	assert.Equal(t, 3, p.Unlinked())
+	var roots []heap.Item
+	p.Walk(func(item, parent heap.Item, depth int) bool {
+		assert.Nil(t, parent)
+		assert.Equal(t, 0, depth)
+		roots = append(roots, item)
+		return true
+	})
+	assert.Len(t, roots, 3)
+	assert.Equal(t, heap.NewHealth(3, 1), p.DetectDegenerate())
+	assert.True(t, p.WalkSubtree(Int(8), func(_, _ heap.Item, _ int) bool { return true }))
+	assert.Equal(t, []heap.Item{}, p.Children(Int(3)))
+	assert.Equal(t, 3, p.Unlinked())
+	assert.Equal(t, Int(3), p.FindMin())
+	assert.Equal(t, 0, p.Unlinked())
+
+	// a chain survives DetectDegenerate, and the items deleted lazily are
+	// skipped in place
+	const n = 10
+	p = New(WithLazyDelete(1))
+	for i := n; i > 0; i-- {
+		p.Insert(Int(i))
+	}
+	p.Delete(Int(5))
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, heap.NewHealth(n-1, n-1), p.DetectDegenerate())
+	}
+	assert.Equal(t, 1, p.dead)
+	assert.Equal(t, []heap.Item{Int(6)}, p.Children(Int(4)))
+	p.Walk(func(item, parent heap.Item, depth int) bool {
+		if item == Int(6) {
+			assert.Equal(t, Int(4), parent)
+			assert.Equal(t, 4, depth)
+		}
+		return true
+	})
+	assert.Equal(t, 1, p.dead)
+}
+
+func TestLazyInsert(t *testing.T) {
+	var changes []heap.Item
+	p := New(WithLazyInsert(), OnMinChanged(func(_, new heap.Item) {
+		changes = append(changes, new)
+	}))
+	for _, v := range []int{5, 3, 8, 1, 9} {
+		p.Insert(Int(v))
+	}
+	assert.Nil(t, p.root)
+	assert.False(t, p.IsEmpty())
+	assert.Equal(t, 5, p.Len())
+	assert.Equal(t, 5, checkStructure(t, p))
+	assert.Equal(t, []heap.Item{Int(5), Int(3), Int(1)}, changes)
+
+	// reads consolidate the forest
+	assert.Equal(t, Int(1), p.FindMin())
+	assert.Nil(t, p.forest)
+	p.Insert(Int(0))
+	p.Insert(Int(4))
+	assert.True(t, p.Contains(Int(4)))
+	assert.Nil(t, p.forest)
+
+	// melds consolidate both sides
+	q := New(WithLazyInsert())
+	q.Insert(Int(2))
+	q.Insert(Int(7))
+	p.Insert(Int(6))
+	p.Meld(q)
+	assert.True(t, q.IsEmpty())
+	assert.Equal(t, 10, checkStructure(t, p))
+
+	p.Insert(Int(-1))
+	var want []heap.Item
+	for _, v := range []int{-1, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9} {
+		want = append(want, Int(v))
+	}
+	assert.Equal(t, want, p.Drain())
+	assert.Equal(t, []heap.Item{Int(5), Int(3), Int(1), Int(0), Int(-1), Int(0), Int(1), Int(2),
+		Int(3), Int(4), Int(5), Int(6), Int(7), Int(8), Int(9), nil}, changes)
+}
+
+func TestPause(t *testing.T) {
+	p := New(WithStable())
+	for _, v := range perm(20) {
+		p.Insert(v)
+	}
+	even := func(item heap.Item) bool { return item.(heap.Integer)%2 == 0 }
+	assert.Equal(t, 10, p.Pause("even", even))
+	assert.Equal(t, 10, p.Len())
+	assert.Equal(t, 10, p.Paused("even"))
+	assert.Equal(t, -1, p.Paused("odd"))
+	checkStructure(t, p)
+	assert.Panics(t, func() { p.Pause("even", even) })
+
+	// matching inserts are held while paused
+	p.Insert(Int(-2))
+	p.Insert(Int(-1))
+	assert.Equal(t, Int(-1), p.FindMin())
+	assert.Equal(t, 11, p.Paused("even"))
+	for _, v := range []int{-1, 1, 3, 5, 7} {
+		assert.Equal(t, Int(v), p.DeleteMin())
+	}
+
+	assert.Equal(t, 11, p.Resume("even"))
+	assert.Equal(t, -1, p.Resume("even"))
+	assert.Equal(t, Int(-2), p.FindMin())
+	assert.Equal(t, 17, checkStructure(t, p))
+
+	// tagged segments hold later melds with the same tag
+	q := New()
+	for _, v := range []int{100, 101} {
+		q.Insert(Int(v))
+	}
+	p.MeldTagged(q, "q")
+	assert.Equal(t, 2, p.PauseTagged("q"))
+	r := New()
+	r.Insert(Int(-10))
+	p.MeldTagged(r, "q")
+	assert.True(t, r.IsEmpty())
+	assert.Equal(t, Int(-2), p.FindMin())
+	assert.Equal(t, 3, p.Resume("q"))
+	assert.Equal(t, Int(-10), p.FindMin())
+	assert.Equal(t, 3, p.Unmeld("q").Len())
+	assert.Panics(t, func() { p.PauseTagged(nil) })
+
+	p.Pause("all", func(heap.Item) bool { return true })
+	p.Clear()
+	assert.Equal(t, -1, p.Paused("all"))
+}
+
+func TestMeldPaused(t *testing.T) {
+	odd := func(item heap.Item) bool { return item.(heap.Integer)%2 == 1 }
+
+	// the paused segments of the melded heap move along
+	a, b := New(), New()
+	for _, v := range perm(6) {
+		b.Insert(v)
+	}
+	assert.Equal(t, 3, b.Pause("odd", odd))
+	a.Meld(b)
+	assert.Equal(t, 3, a.Len())
+	assert.Equal(t, 3, a.Paused("odd"))
+	assert.Equal(t, -1, b.Paused("odd"))
+	assert.Equal(t, 3, a.Resume("odd"))
+	assert.Equal(t, 6, checkStructure(t, a))
+
+	// the melded items are held by the segments paused in p
+	assert.Equal(t, 3, a.Pause("odd", odd))
+	c := New()
+	for _, v := range []int{7, 1, 8} {
+		c.Insert(Int(v))
+	}
+	a.Meld(c)
+	assert.Equal(t, Int(0), a.FindMin())
+	assert.Equal(t, 4, a.Len())
+	assert.Equal(t, 5, a.Paused("odd"))
+	assert.Equal(t, 4, checkStructure(t, a))
+	for _, v := range []int{0, 2, 4, 8} {
+		assert.Equal(t, Int(v), a.DeleteMin())
+	}
+	assert.Nil(t, a.DeleteMin())
+	assert.Equal(t, 5, a.Resume("odd"))
+	assert.Equal(t, Int(1), a.FindMin())
+
+	// MeldAll and MeldTagged do the same
+	d, e := New(), New()
+	e.Insert(Int(3))
+	e.Insert(Int(10))
+	e.Pause("ten", func(item heap.Item) bool { return item == Int(10) })
+	d.Pause("odd", odd)
+	d.MeldAll(e)
+	assert.Equal(t, 0, d.Len())
+	assert.Equal(t, 1, d.Paused("odd"))
+	assert.Equal(t, 1, d.Paused("ten"))
+
+	f, g := New(), New()
+	g.Insert(Int(10))
+	g.Pause("ten", func(item heap.Item) bool { return item == Int(10) })
+	f.MeldTagged(g, "g")
+	assert.Equal(t, 1, f.Resume("ten"))
+	assert.Equal(t, 1, f.Unmeld("g").Len())
+}
+
+func TestView(t *testing.T) {
+	p := New()
+	for _, v := range perm(100) {
+		p.Insert(v)
+	}
+	p.DeleteMin()
+	p.Insert(Int(0))
+
+	even := p.View(func(item heap.Item) bool { return item.(heap.Integer)%2 == 0 })
+	odd := p.View(func(item heap.Item) bool { return item.(heap.Integer)%2 == 1 })
+	none := p.View(func(heap.Item) bool { return false })
+
+	assert.True(t, none.IsEmpty())
+	assert.Nil(t, none.DeleteMin())
+
+	count := 0
+	odd.Do(func(item heap.Item) bool {
+		assert.Equal(t, heap.Integer(1), item.(heap.Integer)%2)
+		count++
+		return true
+	})
+	assert.Equal(t, 50, count)
+
+	for i := 1; i < 100; i += 2 {
+		assert.Equal(t, Int(i), odd.FindMin())
+		assert.Equal(t, Int(i), odd.DeleteMin())
+		checkStructure(t, p)
+	}
+	assert.True(t, odd.IsEmpty())
+	assert.Equal(t, Int(0), p.FindMin())
+
+	for i := 0; i < 100; i += 2 {
+		assert.Equal(t, Int(i), even.DeleteMin())
+	}
+	assert.True(t, p.IsEmpty())
+}
+
+func (suite *PairingHeapTestSuite) TestDetectDegenerate() {
+	health := suite.heap.DetectDegenerate()
+	assert.Equal(suite.T(), 0, health.Size)
+	assert.False(suite.T(), health.Degenerate)
+
+	// decreasing inserts build a single chain
+	for _, v := range rangrev(100) {
+		suite.heap.Insert(v)
+	}
+	health = suite.heap.DetectDegenerate()
+	assert.Equal(suite.T(), 100, health.Size)
+	assert.Equal(suite.T(), 100, health.MaxDepth)
+	assert.True(suite.T(), health.Degenerate)
+
+	// increasing inserts build a star
+	suite.heap.Clear()
+	for _, v := range rang(100) {
+		suite.heap.Insert(v)
+	}
+	health = suite.heap.DetectDegenerate()
+	assert.Equal(suite.T(), 2, health.MaxDepth)
+	assert.False(suite.T(), health.Degenerate)
+}
+
+// checkStructure verifies the sibling links and the heap order of p.
+func TestReorder(t *testing.T) {
+	configs := [][]Option{
+		{WithStrategy(TwoPass)},
+		{WithStrategy(MultiPass), WithLazyDelete(0.5)},
+		{WithLazyInsert(), WithPool()},
+		{WithStrategy(Auxiliary)},
+	}
+	for _, opts := range configs {
+		p := New(opts...)
+		var mins []heap.Item
+		p.onMinChanged = func(old, new heap.Item) { mins = append(mins, new) }
+		for _, v := range perm(100) {
+			p.Insert(v)
+		}
+		for i := 0; i < 10; i++ {
+			p.DeleteMin()
+		}
+		p.Delete(Int(50))
+		p.Delete(Int(60))
+		p.Insert(Int(5))
+
+		// a max heap, then by distance to 50
+		p.Reorder(Descending)
+		assert.Equal(t, 89, checkStructure(t, p))
+		assert.Equal(t, 89, p.Len())
+		assert.Equal(t, Int(99), p.FindMin())
+		assert.Equal(t, Int(99), mins[len(mins)-1])
+		assert.True(t, p.Contains(Int(5)))
+		assert.False(t, p.Contains(Int(50)))
+		assert.Equal(t, Int(97), p.Adjust(Int(97), Int(100)))
+		assert.Equal(t, Int(100), p.DeleteMin())
+		assert.Equal(t, Int(99), p.DeleteMin())
+
+		distance := func(a, b heap.Item) int {
+			da, db := int(a.(heap.Integer))-50, int(b.(heap.Integer))-50
+			if da < 0 {
+				da = -da
+			}
+			if db < 0 {
+				db = -db
+			}
+			return da - db
+		}
+		p.Reorder(distance)
+		assert.Equal(t, 87, checkStructure(t, p))
+		for _, want := range []heap.Item{Int(49), Int(51), Int(48), Int(52)} {
+			if got := p.DeleteMin(); distance(got, want) != 0 {
+				t.Fatalf("expected an item as close to 50 as %v, got %v", want, got)
+			}
+		}
+
+		// back to the order of the items
+		p.Reorder(nil)
+		assert.Equal(t, 83, checkStructure(t, p))
+		assert.Equal(t, Int(5), p.DeleteMin())
+		assert.Equal(t, Int(10), p.DeleteMin())
+	}
+}
+
+func TestWithOrder(t *testing.T) {
+	newHeap := func() *PairHeap {
+		p := New(WithOrder(Descending), WithStable())
+		for _, v := range []int{3, 1, 4, 1, 5, 9, 2, 6} {
+			p.Insert(Int(v))
+		}
+		return p
+	}
+	le, gt := newHeap().Split(Int(5))
+	assert.Equal(t, []heap.Item{Int(9), Int(6), Int(5)}, le.Drain())
+	assert.Equal(t, []heap.Item{Int(4), Int(3), Int(2), Int(1), Int(1)}, gt.Drain())
+	assert.Equal(t, []int{2, 3, 3}, newHeap().Histogram([]heap.Item{Int(5), Int(2)}))
+
+	// paused items are reordered with the heap
+	p := newHeap()
+	p.Pause("small", func(item heap.Item) bool { return item.(heap.Integer) < 3 })
+	p.Reorder(nil)
+	p.Resume("small")
+	assert.Equal(t, []heap.Item{Int(1), Int(1), Int(2), Int(3), Int(4), Int(5), Int(6), Int(9)}, p.Drain())
+}
+
+func checkStructure(t *testing.T, p *PairHeap) int {
+	t.Helper()
+	size := 0
+	var prev *node
+	var trees []*node
+	for n := p.forest; n != nil; n = n.next {
+		assert.True(t, n.prev == prev, "broken forest link")
+		assert.False(t, p.less(n, p.forestMin), "forest minimum out of date")
+		if p.strategy != Auxiliary {
+			// only the subtrees of decreased items are buffered with n
+			assert.Nil(t, n.child)
+		}
+		trees = append(trees, n)
+		prev = n
+	}
+	if p.root != nil {
+		assert.Nil(t, p.root.prev)
+		assert.Nil(t, p.root.next)
+		trees = append(trees, p.root)
+	}
+	for _, tree := range trees {
+		size += checkTree(t, p, tree)
+	}
+	return size
+}
+
+// checkTree verifies the sibling links and the heap order of p in the tree
+// rooted at root and returns its number of nodes.
+func checkTree(t *testing.T, p *PairHeap, root *node) int {
+	t.Helper()
+	size := 0
+	root.walkNodes(func(n, _ *node, _ int) bool {
+		size++
+		prev := n
+		for child := n.child; child != nil; child = child.next {
+			assert.True(t, child.prev == prev, "broken prev link")
+			assert.True(t, p.compare(n.item, child.item) <= 0, "heap order violated")
+			prev = child
+		}
+		return true
+	})
+	return size
+}
+
+func TestRandomOperations(t *testing.T) {
+	configs := [][]Option{
+		{WithStrategy(TwoPass)},
+		{WithStrategy(MultiPass)},
+		{WithLazyDelete(0.5)},
+		{WithLazyInsert(), WithStable()},
+		{WithStrategy(Auxiliary)},
+	}
+	for _, opts := range configs {
+		p := New(opts...)
+		size := 0
+		for i, op := range heaptest.RandomOps(rand.Int63(), 2000) {
+			got := op.Apply(p)
+			if op.Want != nil {
+				assert.Equal(t, op.Want, got, fmt.Sprintf("operation %d: %v", i, op))
+			}
+			switch op.Kind {
+			case heaptest.Insert:
+				size++
+			case heaptest.DeleteMin, heaptest.Delete:
+				size--
+			}
+			assert.Equal(t, size+p.dead, checkStructure(t, p))
+			assert.Equal(t, size, p.Len())
+		}
+	}
+}
+
+// highDegree returns a heap whose root has n children.
+func highDegree(n int) *PairHeap {
+	p := New()
+	for i := 0; i < n; i++ {
+		p.Insert(Int(i))
+	}
+	return p
+}
+
+func BenchmarkDeleteHighDegree(b *testing.B) {
+	p := highDegree(100000)
+	next := 100000
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// the most recent child sits first in the root's child list
+		p.Delete(Int(next - 1))
+		p.Insert(Int(next - 1))
+	}
+}
+
+func BenchmarkAdjustHighDegree(b *testing.B) {
+	p := highDegree(100000)
+	next := 100000
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Adjust(Int(next-1), Int(next))
+		next++
+	}
+}
+
 func Int(value int) heap.Integer {
 	return heap.Integer(value)
 }
\ No newline at end of file
@@ -0,0 +1,171 @@
+package pairing
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// segment is a paused subset of the items of a PairHeap, held in a side
+// heap until it is resumed.
+type segment struct {
+	name   interface{}
+	pred   func(item heap.Item) bool // nil for a segment paused by tag
+	tagged bool
+	items  *PairHeap
+}
+
+// Pause moves the items of p for which pred returns true to a side heap
+// named name, so that DeleteMin, FindMin and the other operations of p skip
+// them until Resume(name) melds them back, for example to hold the work of
+// a downstream behind an open circuit breaker. While the segment is paused,
+// inserted items matching pred are held in it too; the first segment paused
+// takes an item matching several. It returns the number of items moved. It
+// panics if a segment named name is already paused. name must be
+// comparable.
+// The complexity is O(n + k log n) for k moved items.
+func (p *PairHeap) Pause(name interface{}, pred func(item heap.Item) bool) int {
+	seg := p.pause(name, pred, false)
+	if p.IsEmpty() {
+		return 0
+	}
+	p.compact()
+	var matched []*node
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		if pred(n.item) {
+			matched = append(matched, n)
+		}
+		return true
+	})
+	if len(matched) == 0 {
+		return 0
+	}
+	before := p.minState()
+	p.extract(matched, seg.items)
+	p.notify(before)
+	return len(matched)
+}
+
+// PauseTagged pauses the items melded in by MeldTagged with tag, like
+// Pause with a segment named tag. Heaps melded with MeldTagged and tag while
+// the segment is paused are held in it, and Resume tags the items again.
+// The complexity is O(n + k log n) for k moved items.
+func (p *PairHeap) PauseTagged(tag interface{}) int {
+	if tag == nil {
+		panic("pairing: cannot pause the nil tag")
+	}
+	seg := p.pause(tag, nil, true)
+	seg.items = p.Unmeld(tag)
+	return seg.items.Len()
+}
+
+// Resume melds the items of the paused segment named name back into p and
+// returns how many there were. It returns -1 if no such segment is paused.
+// The complexity is O(1), or O(k) for the k items of a segment paused by
+// tag.
+func (p *PairHeap) Resume(name interface{}) int {
+	for i, seg := range p.paused {
+		if seg.name != name {
+			continue
+		}
+		p.paused = append(p.paused[:i], p.paused[i+1:]...)
+		n := seg.items.Len()
+		if seg.tagged {
+			p.MeldTagged(seg.items, name)
+		} else {
+			p.Meld(seg.items)
+		}
+		return n
+	}
+	return -1
+}
+
+// Paused returns the number of items held by the paused segment named
+// name, or -1 if no such segment is paused.
+// The complexity is O(s) for s paused segments.
+func (p *PairHeap) Paused(name interface{}) int {
+	if seg := p.segment(name); seg != nil {
+		return seg.items.Len()
+	}
+	return -1
+}
+
+func (p *PairHeap) pause(name interface{}, pred func(item heap.Item) bool, tagged bool) *segment {
+	if p.segment(name) != nil {
+		panic(fmt.Sprintf("pairing: segment %v is already paused", name))
+	}
+	seg := &segment{name: name, pred: pred, tagged: tagged, items: p.spawn()}
+	p.paused = append(p.paused, seg)
+	return seg
+}
+
+func (p *PairHeap) segment(name interface{}) *segment {
+	for _, seg := range p.paused {
+		if seg.name == name {
+			return seg
+		}
+	}
+	return nil
+}
+
+// hold adds item to the first paused segment it matches and reports
+// whether there was one. The node is created by p, so that its sequence
+// number keeps its place among the items of p once resumed.
+func (p *PairHeap) hold(item heap.Item) bool {
+	for _, seg := range p.paused {
+		if seg.pred != nil && seg.pred(item) {
+			s := seg.items
+			s.root = s.merge(s.root, p.newNode(item))
+			s.size++
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the segment holds n while it is paused.
+func (s *segment) matches(n *node) bool {
+	if s.tagged {
+		return n.tag == s.name
+	}
+	return s.pred(n.item)
+}
+
+// adopt moves the paused segments of h, which is about to be melded into p,
+// to p. The items of a segment paused under a name p also pauses join the
+// segment of p.
+func (p *PairHeap) adopt(h *PairHeap) {
+	for _, seg := range h.paused {
+		if own := p.segment(seg.name); own != nil {
+			own.items.Meld(seg.items)
+		} else {
+			p.paused = append(p.paused, seg)
+		}
+	}
+	h.paused = nil
+}
+
+// withhold moves the items of h, which is about to be melded into p, to the
+// first paused segment of p they match, as Insert would.
+// The complexity is O(m + k log m) for the m items of h and k moved items.
+func (p *PairHeap) withhold(h *PairHeap) {
+	if len(p.paused) == 0 || h.IsEmpty() {
+		return
+	}
+	h.compact()
+	held := make([][]*node, len(p.paused))
+	h.root.walkNodes(func(n, _ *node, _ int) bool {
+		for i, seg := range p.paused {
+			if seg.matches(n) {
+				held[i] = append(held[i], n)
+				break
+			}
+		}
+		return true
+	})
+	for i, nodes := range held {
+		if len(nodes) > 0 {
+			h.extract(nodes, p.paused[i].items)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package pairing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/internal/lines"
+)
+
+// stateHeader starts a dump.
+const stateHeader = "pairing-heap v2"
+
+// DumpState writes the exact tree structure of p to w, so that it can be
+// attached to a bug report and reloaded with LoadState.
+//
+// The dump starts with a header line followed by one line per node in
+// depth-first order, children from left to right. Each node line holds the
+// node depth, its insertion sequence number and its item formatted with
+// fmt.Sprint and quoted. Depths are written as numbers rather than
+// indentation since a degenerate pairing heap can be as deep as it is large:
+//
+//	pairing-heap v2
+//	0 3 "1"
+//	1 5 "4"
+//	2 1 "7"
+//	1 2 "2"
+func (p *PairHeap) DumpState(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, stateHeader)
+	if !p.IsEmpty() {
+		p.compact()
+		p.root.walkNodes(func(n, _ *node, depth int) bool {
+			fmt.Fprintf(bw, "%d %d %s\n", depth, n.seq, strconv.Quote(fmt.Sprint(n.item)))
+			return true
+		})
+	}
+	return bw.Flush()
+}
+
+// LoadState replaces the contents of p with the tree read from r, as written
+// by DumpState. parse converts the text of each item back into an item.
+// The tree is restored as is: heap order is not checked, so a dump taken
+// from a corrupted heap reproduces the corruption.
+// On error p is left unchanged.
+func (p *PairHeap) LoadState(r io.Reader, parse func(string) (heap.Item, error)) error {
+	var root *node
+	var maxSeq uint64
+	var size int
+	// path holds the last node read at each depth
+	var path []*node
+	err := ReadState(r, func(s StateNode) error {
+		item, err := parse(s.Item)
+		if err != nil {
+			return err
+		}
+		n := &node{item: item, seq: s.Seq}
+		if s.Seq > maxSeq {
+			maxSeq = s.Seq
+		}
+		switch {
+		case s.Depth == 0:
+			root = n
+		case s.Depth == len(path):
+			parent := path[s.Depth-1]
+			parent.child, n.prev = n, parent
+		default:
+			sibling := path[s.Depth]
+			sibling.next, n.prev = n, sibling
+		}
+		path = append(path[:s.Depth], n)
+		size++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	before := p.minState()
+	p.Init()
+	p.root, p.size = root, size
+	if maxSeq > p.seq {
+		p.seq = maxSeq
+	}
+	p.notify(before)
+	return nil
+}
+
+// StateNode is a node of a dump written by DumpState.
+type StateNode struct {
+	Depth int    // 0 for the root
+	Seq   uint64 // the insertion sequence number
+	Item  string // the item formatted with fmt.Sprint
+}
+
+// ReadState reads a dump written by DumpState and calls fn with its nodes
+// in the order they were written, so that tools can rebuild the tree
+// without a pairing heap. The dump is checked to hold a single tree: every
+// node but the root is at most one level deeper than the node before it,
+// which makes it a child of the last node read one level up. Lines are not
+// limited in length. ReadState stops at the first error, of the dump or
+// returned by fn, and returns it.
+func ReadState(r io.Reader, fn func(StateNode) error) error {
+	br := bufio.NewReader(r)
+	header, err := lines.Read(br)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if header != stateHeader {
+		return fmt.Errorf("pairing: missing %q header", stateHeader)
+	}
+
+	// levels is one more than the depth of the last node read
+	levels := 0
+	for line := 2; ; line++ {
+		text, err := lines.Read(br)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		fields := strings.SplitN(text, " ", 3)
+		if len(fields) != 3 {
+			return fmt.Errorf("pairing: line %d: expected a depth, a sequence number and an item", line)
+		}
+		depth, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("pairing: line %d: %v", line, err)
+		}
+		seq, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("pairing: line %d: %v", line, err)
+		}
+		item, err := strconv.Unquote(fields[2])
+		if err != nil {
+			return fmt.Errorf("pairing: line %d: %v", line, err)
+		}
+		switch {
+		case depth == 0 && levels > 0:
+			return fmt.Errorf("pairing: line %d: more than one root", line)
+		case depth < 0 || depth > levels:
+			return fmt.Errorf("pairing: line %d: node has no parent", line)
+		}
+		if err := fn(StateNode{Depth: depth, Seq: seq, Item: item}); err != nil {
+			return fmt.Errorf("pairing: line %d: %v", line, err)
+		}
+		levels = depth + 1
+	}
+}
+
+// WalkState visits the nodes of p like Walk, as StateNodes. Unlike
+// DumpState, it leaves p as it is: the trees of the items inserted lazily
+// and not linked yet follow the tree of the root, each from depth 0, and
+// the items deleted lazily are skipped. WalkState panics if fn changes p.
+// The complexity is O(n).
+func (p *PairHeap) WalkState(fn func(StateNode) bool) {
+	mods := p.mods
+	p.trees(func(t *node) bool {
+		return t.walkLive(func(n, _ *node, depth int) bool {
+			next := fn(StateNode{Depth: depth, Seq: n.seq, Item: fmt.Sprint(n.item)})
+			p.checkMods(mods)
+			return next
+		})
+	})
+}
@@ -0,0 +1,90 @@
+package pairing
+
+// MeldTagged melds h into p like Meld, marking every item that comes from h
+// with tag so they can be taken back out with Unmeld. Items h got from an
+// earlier MeldTagged are re-tagged. tag must be comparable, and a nil tag
+// clears the marks. While tag is paused by PauseTagged, the items of h are
+// held in the paused segment instead. The paused segments of h move to p as
+// by Meld, and the items held by Pause are marked too.
+// The complexity is O(m) for the m items of h.
+func (p *PairHeap) MeldTagged(h *PairHeap, tag interface{}) *PairHeap {
+	if h == nil {
+		return p
+	}
+	for _, seg := range h.paused {
+		if seg.items.consolidate(); !seg.tagged && seg.items.root != nil {
+			seg.items.root.walkNodes(func(n, _ *node, _ int) bool {
+				n.tag = tag
+				return true
+			})
+		}
+	}
+	p.adopt(h)
+	if h.IsEmpty() {
+		return p
+	}
+	if seg := p.segment(tag); seg != nil && seg.tagged {
+		seg.items.Meld(h)
+		return p
+	}
+	h.consolidate()
+	h.root.walkNodes(func(n, _ *node, _ int) bool {
+		n.tag = tag
+		return true
+	})
+	p.Meld(h)
+	return p
+}
+
+// Unmeld removes every item of p that was melded in by MeldTagged with tag
+// and returns them in a new heap configured like p, with their marks
+// cleared. Items that were removed in the meantime are not returned.
+// The complexity is O(n + k log n) for k returned items.
+func (p *PairHeap) Unmeld(tag interface{}) *PairHeap {
+	out := p.spawn()
+	if p.IsEmpty() || tag == nil {
+		return out
+	}
+
+	p.compact()
+	var tagged []*node
+	p.root.walkNodes(func(n, _ *node, _ int) bool {
+		if n.tag == tag {
+			tagged = append(tagged, n)
+		}
+		return true
+	})
+	if len(tagged) == 0 {
+		return out
+	}
+
+	before := p.minState()
+	p.extract(tagged, out)
+	p.notify(before)
+	return out
+}
+
+// extract moves the live nodes of p in nodes, without their children, to
+// out and clears their marks.
+func (p *PairHeap) extract(nodes []*node, out *PairHeap) {
+	p.mods++
+	for _, n := range nodes {
+		// the children of n stay in p, including the ones of nodes that are
+		// still to be moved
+		if n == p.root {
+			p.root = nil
+			if n.child != nil {
+				p.root = p.mergePairs(n.child)
+			}
+		} else {
+			n.cut()
+			if n.child != nil {
+				p.root = p.merge(p.root, p.mergePairs(n.child))
+			}
+		}
+		n.child, n.tag = nil, nil
+		out.root = out.merge(out.root, n)
+	}
+	p.size -= len(nodes)
+	out.size += len(nodes)
+}
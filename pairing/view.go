@@ -0,0 +1,117 @@
+package pairing
+
+import (
+	"container/heap"
+
+	goheap "github.com/theodesp/go-heaps"
+)
+
+// View is a filtered sub-queue of a PairHeap exposing only the items that
+// match its predicate. Views hold no copy of the data: every operation looks
+// up the smallest matching item in the underlying heap, so any number of
+// views can share one heap and observe each other's changes.
+type View struct {
+	heap *PairHeap
+	pred func(item goheap.Item) bool
+}
+
+// View returns a view over the items of p for which pred returns true.
+func (p *PairHeap) View(pred func(item goheap.Item) bool) *View {
+	return &View{heap: p, pred: pred}
+}
+
+// IsEmpty returns true if no item of the heap matches the view.
+// The complexity is O(n) in the worst case.
+func (v *View) IsEmpty() bool {
+	return v.find() == nil
+}
+
+// FindMin returns the smallest item matching the view.
+// The complexity is O(k log k) where k is the number of smaller items that
+// do not match.
+func (v *View) FindMin() goheap.Item {
+	n := v.find()
+	if n == nil {
+		return nil
+	}
+	return n.item
+}
+
+// DeleteMin removes the smallest item matching the view from the underlying
+// heap and returns it.
+// The complexity is O(k log k) to locate it plus O(log n) amortized.
+func (v *View) DeleteMin() goheap.Item {
+	n := v.find()
+	if n == nil {
+		return nil
+	}
+	p := v.heap
+	item := n.item
+	before := p.minState()
+	if n == p.root {
+		p.deleteItem(nil, removeMin)
+	} else {
+		p.remove(n)
+	}
+	p.notify(before)
+	return item
+}
+
+// Do calls it on each item matching the view, in order of appearance.
+// Do panics if it changes the underlying heap.
+func (v *View) Do(it goheap.ItemIterator) {
+	v.heap.Do(func(item goheap.Item) bool {
+		if !v.pred(item) {
+			return true
+		}
+		return it(item)
+	})
+}
+
+func (v *View) find() *node {
+	var found *node
+	v.heap.ascend(func(n *node) bool {
+		if v.pred(n.item) {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// ascend visits the live nodes of p in increasing order until fn returns false.
+// It explores the tree best first, keeping the children of the visited nodes
+// as candidates, so stopping after k nodes costs O(k log k).
+func (p *PairHeap) ascend(fn func(n *node) bool) {
+	p.consolidate()
+	if p.IsEmpty() {
+		return
+	}
+	frontier := &candidates{less: p.less, nodes: []*node{p.root}}
+	for frontier.Len() > 0 {
+		n := heap.Pop(frontier).(*node)
+		if !n.dead && !fn(n) {
+			return
+		}
+		for child := n.child; child != nil; child = child.next {
+			heap.Push(frontier, child)
+		}
+	}
+}
+
+// candidates is a binary heap of nodes used by ascend.
+type candidates struct {
+	less  func(a, b *node) bool
+	nodes []*node
+}
+
+func (c *candidates) Len() int           { return len(c.nodes) }
+func (c *candidates) Less(i, j int) bool { return c.less(c.nodes[i], c.nodes[j]) }
+func (c *candidates) Swap(i, j int)      { c.nodes[i], c.nodes[j] = c.nodes[j], c.nodes[i] }
+func (c *candidates) Push(x interface{}) { c.nodes = append(c.nodes, x.(*node)) }
+func (c *candidates) Pop() interface{} {
+	n := c.nodes[len(c.nodes)-1]
+	c.nodes = c.nodes[:len(c.nodes)-1]
+	return n
+}
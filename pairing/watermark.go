@@ -0,0 +1,86 @@
+package pairing
+
+import (
+	"fmt"
+)
+
+// OnHighWatermark registers fn to be called with the number of items when
+// it rises to high or above, so producers can be throttled while the queue
+// is long. fn is not called again until the number of items falls back to
+// the low watermark, or below high when there is none.
+//
+// The watermarks are checked once at the end of every operation, so bulk
+// operations such as Meld, MeldAll, Split or LoadState report a crossing
+// once, however many items they move. fn runs synchronously and must not
+// modify the heap.
+func OnHighWatermark(high int, fn func(size int)) Option {
+	return func(p *PairHeap) {
+		p.watermarks.high, p.watermarks.onHigh = high, fn
+	}
+}
+
+// OnLowWatermark registers fn to be called with the number of items when it
+// falls to low or below after having risen to the high watermark, or above
+// low when there is none, so throttled producers can resume. The watermarks
+// are checked like for OnHighWatermark. low must be less than the high
+// watermark.
+func OnLowWatermark(low int, fn func(size int)) Option {
+	return func(p *PairHeap) {
+		p.watermarks.low, p.watermarks.onLow = low, fn
+	}
+}
+
+// watermarks tracks the number of items against the configured watermarks.
+// Between the two, the previous crossing decides whether the heap counts as
+// above them, so the callbacks alternate.
+type watermarks struct {
+	high, low     int
+	onHigh, onLow func(size int)
+	above         bool
+}
+
+func (w *watermarks) validate() {
+	if w.onHigh != nil && w.onLow != nil && w.low >= w.high {
+		panic(fmt.Sprintf("pairing: low watermark %d is not below the high watermark %d", w.low, w.high))
+	}
+}
+
+// rise returns the number of items at which the heap goes above the
+// watermarks.
+func (w *watermarks) rise() int {
+	if w.onHigh != nil {
+		return w.high
+	}
+	return w.low + 1
+}
+
+// fall returns the number of items at which the heap goes back below the
+// watermarks.
+func (w *watermarks) fall() int {
+	if w.onLow != nil {
+		return w.low
+	}
+	return w.high - 1
+}
+
+// checkWatermarks calls the watermark callback of a crossing since the last
+// check, if any.
+func (p *PairHeap) checkWatermarks() {
+	w := &p.watermarks
+	if w.onHigh == nil && w.onLow == nil {
+		return
+	}
+	size := p.Len()
+	switch {
+	case !w.above && size >= w.rise():
+		w.above = true
+		if w.onHigh != nil {
+			w.onHigh(size)
+		}
+	case w.above && size <= w.fall():
+		w.above = false
+		if w.onLow != nil {
+			w.onLow(size)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// Package quake implements a quake heap, Timothy Chan's simplification of
+// the Fibonacci heap.
+//
+// A quake heap is a forest of tournament trees. Every item is a leaf, and
+// every internal node is a copy of the smaller of its two children, so the
+// root of a tree represents its smallest item. Insert adds a single leaf,
+// and DecreaseKey cuts the subtree of the highest node representing an item
+// off its parent, both in O(1). DeleteMin removes the path of the minimum
+// from its root down to its leaf, links trees of equal height like a
+// binomial heap does, and then, if some height holds more than 3/4 of the
+// nodes of the height below, removes every node above it: the "quake" that
+// keeps the trees balanced. DeleteMin is O(log n) amortized.
+//
+// The operations match those of a Fibonacci heap with no marks, cascading
+// cuts or degree bookkeeping, which makes the quake heap a middle ground
+// between the pairing heap and the Fibonacci heap in this library.
+//
+// Structure is not thread safe.
+//
+// Reference: T. M. Chan, "Quake Heaps: A Simple Alternative to Fibonacci
+// Heaps", Space-Efficient Data Structures, Streams, and Algorithms, 2013.
+package quake
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// alphaNum/alphaDen is the ratio of the number of nodes of a height to the
+// number of nodes of the height below above which DeleteMin quakes.
+const alphaNum, alphaDen = 3, 4
+
+// Element is an item held by a QuakeHeap, returned by Push to decrease its
+// key or remove it later.
+type Element struct {
+	item heap.Item
+	// top is the highest node representing the element, nil once the
+	// element is removed
+	top *node
+}
+
+// Item returns the item of the element.
+func (e *Element) Item() heap.Item {
+	return e.item
+}
+
+// node is a node of a tournament tree. Its left child represents the same
+// element and its right child, if any, the element it won against.
+type node struct {
+	elem                *Element
+	left, right, parent *node
+	height              int
+}
+
+// QuakeHeap is a quake heap. The zero value is an empty heap.
+type QuakeHeap struct {
+	roots     []*node
+	min       *node // the root of the smallest item
+	counts    []int // number of nodes by height
+	size      int
+	degreeOne int // number of internal nodes with a single child
+}
+
+// QuakeHeap implements the Extended interface
+var _ heap.Extended = (*QuakeHeap)(nil)
+
+// Init initializes or clears the QuakeHeap. Elements returned by Push
+// before are no longer valid.
+func (h *QuakeHeap) Init() *QuakeHeap {
+	h.roots, h.min, h.counts = nil, nil, nil
+	h.size, h.degreeOne = 0, 0
+	return h
+}
+
+// New returns an initialized QuakeHeap.
+func New() *QuakeHeap { return new(QuakeHeap).Init() }
+
+// Len returns the number of items in the heap.
+func (h *QuakeHeap) Len() int {
+	return h.size
+}
+
+// Insert adds an item into the heap and returns it.
+// The complexity is O(1).
+func (h *QuakeHeap) Insert(item heap.Item) heap.Item {
+	h.Push(item)
+	return item
+}
+
+// Push adds an item into the heap and returns its element.
+// The complexity is O(1).
+func (h *QuakeHeap) Push(item heap.Item) *Element {
+	e := &Element{item: item}
+	n := &node{elem: e}
+	e.top = n
+	h.count(0, 1)
+	h.addRoot(n)
+	h.size++
+	return e
+}
+
+// FindMin returns the smallest item, or nil if the heap is empty.
+// The complexity is O(1).
+func (h *QuakeHeap) FindMin() heap.Item {
+	if h.min == nil {
+		return nil
+	}
+	return h.min.elem.item
+}
+
+// FindMinElement returns the element of the smallest item, or nil if the
+// heap is empty, to decrease or remove the minimum without looking it up.
+// The complexity is O(1).
+func (h *QuakeHeap) FindMinElement() *Element {
+	if h.min == nil {
+		return nil
+	}
+	return h.min.elem
+}
+
+// DeleteMin removes the smallest item and returns it, or nil if the heap is
+// empty.
+// The complexity is O(log n) amortized.
+func (h *QuakeHeap) DeleteMin() heap.Item {
+	if h.min == nil {
+		return nil
+	}
+	e := h.min.elem
+	h.remove(h.min)
+	return e.item
+}
+
+// DecreaseKey replaces the item of e, which must be in the heap, with item,
+// which must not compare greater. The nodes of e above the cut keep
+// representing it, so only the highest one moves.
+// The complexity is O(1).
+func (h *QuakeHeap) DecreaseKey(e *Element, item heap.Item) {
+	if e.top == nil {
+		panic("quake: element is not in the heap")
+	}
+	if item.Compare(e.item) > 0 {
+		panic("quake: DecreaseKey to a greater item")
+	}
+	e.item = item
+	if u := e.top; u.parent != nil {
+		h.cut(u)
+		h.addRoot(u)
+	} else if item.Compare(h.min.elem.item) < 0 {
+		h.min = u
+	}
+}
+
+// Remove removes e, which must be in the heap, and returns its item.
+// The complexity is O(log n) amortized.
+func (h *QuakeHeap) Remove(e *Element) heap.Item {
+	u := e.top
+	if u == nil {
+		panic("quake: element is not in the heap")
+	}
+	if u.parent != nil {
+		h.cut(u)
+		h.roots = append(h.roots, u)
+	}
+	h.remove(u)
+	return e.item
+}
+
+// Delete removes the item that compares equal to item and returns it, or
+// nil if there is none.
+// The complexity is O(n) to locate the item, then O(log n) amortized.
+func (h *QuakeHeap) Delete(item heap.Item) heap.Item {
+	e := h.find(item)
+	if e == nil {
+		return nil
+	}
+	return h.Remove(e)
+}
+
+// Adjust replaces the item that compares equal to old with new and returns
+// new, or nil if there is none. A decrease cuts the item in place, an
+// increase removes it and inserts new.
+// The complexity is O(n) to locate the item, then O(1) for a decrease and
+// O(log n) amortized for an increase.
+func (h *QuakeHeap) Adjust(old, new heap.Item) heap.Item {
+	e := h.find(old)
+	if e == nil {
+		return nil
+	}
+	if new.Compare(e.item) <= 0 {
+		h.DecreaseKey(e, new)
+	} else {
+		h.Remove(e)
+		h.Push(new)
+	}
+	return new
+}
+
+// Meld moves the items of a, which must be a *QuakeHeap, into h and
+// returns h. The elements of a stay valid in h.
+// The complexity is O(t) for t trees in a.
+func (h *QuakeHeap) Meld(a heap.Interface) heap.Interface {
+	if a == nil {
+		return h
+	}
+	o, ok := a.(*QuakeHeap)
+	if !ok {
+		panic(fmt.Sprintf("unexpected type %T", a))
+	}
+	if o == h {
+		return h
+	}
+	for _, r := range o.roots {
+		h.addRoot(r)
+	}
+	for height, n := range o.counts {
+		h.count(height, n)
+	}
+	h.size += o.size
+	h.degreeOne += o.degreeOne
+	o.Init()
+	return h
+}
+
+// Clear removes all items from the heap.
+func (h *QuakeHeap) Clear() {
+	h.Init()
+}
+
+// Do calls it for every item in the heap, in no particular order, until it
+// returns false.
+// The complexity is O(n).
+func (h *QuakeHeap) Do(it heap.ItemIterator) {
+	stack := append([]*node(nil), h.roots...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.height == 0 {
+			if !it(n.elem.item) {
+				return
+			}
+			continue
+		}
+		if n.right != nil {
+			stack = append(stack, n.right)
+		}
+		stack = append(stack, n.left)
+	}
+}
+
+// Potential reports the number of nodes, trees and internal nodes with a
+// single child, together with the potential nodes + trees + 2*degree_one
+// of the amortized analysis of quake heaps.
+// The complexity is O(h) for trees of height h.
+func (h *QuakeHeap) Potential() map[string]int {
+	nodes := 0
+	for _, n := range h.counts {
+		nodes += n
+	}
+	trees := len(h.roots)
+	return map[string]int{
+		"nodes":      nodes,
+		"trees":      trees,
+		"degree_one": h.degreeOne,
+		"potential":  nodes + trees + 2*h.degreeOne,
+	}
+}
+
+// count adds delta to the number of nodes of the given height.
+func (h *QuakeHeap) count(height, delta int) {
+	for len(h.counts) <= height {
+		h.counts = append(h.counts, 0)
+	}
+	h.counts[height] += delta
+}
+
+// addRoot adds n to the roots and updates the minimum.
+func (h *QuakeHeap) addRoot(n *node) {
+	n.parent = nil
+	h.roots = append(h.roots, n)
+	if h.min == nil || n.elem.item.Compare(h.min.elem.item) < 0 {
+		h.min = n
+	}
+}
+
+// cut detaches n from its parent, of which it is the right child since the
+// parent represents another element.
+func (h *QuakeHeap) cut(n *node) {
+	n.parent.right = nil
+	n.parent = nil
+	h.degreeOne++
+}
+
+// remove removes the root r together with the path of its element down to
+// its leaf, then links and quakes the remaining trees.
+func (h *QuakeHeap) remove(r *node) {
+	for i, n := range h.roots {
+		if n == r {
+			last := len(h.roots) - 1
+			h.roots[i] = h.roots[last]
+			h.roots[last] = nil
+			h.roots = h.roots[:last]
+			break
+		}
+	}
+	for u := r; u != nil; u = u.left {
+		h.count(u.height, -1)
+		if u.right != nil {
+			u.right.parent = nil
+			h.roots = append(h.roots, u.right)
+		} else if u.height > 0 {
+			h.degreeOne--
+		}
+	}
+	r.elem.top = nil
+	h.size--
+
+	h.link()
+	h.quake()
+	h.min = nil
+	for _, n := range h.roots {
+		if h.min == nil || n.elem.item.Compare(h.min.elem.item) < 0 {
+			h.min = n
+		}
+	}
+}
+
+// link links trees of equal height until every tree has a distinct height.
+func (h *QuakeHeap) link() {
+	var byHeight []*node
+	for _, n := range h.roots {
+		for {
+			for len(byHeight) <= n.height {
+				byHeight = append(byHeight, nil)
+			}
+			o := byHeight[n.height]
+			if o == nil {
+				byHeight[n.height] = n
+				break
+			}
+			byHeight[n.height] = nil
+			if o.elem.item.Compare(n.elem.item) < 0 {
+				n, o = o, n
+			}
+			p := &node{elem: n.elem, left: n, right: o, height: n.height + 1}
+			n.parent, o.parent = p, p
+			n.elem.top = p
+			h.count(p.height, 1)
+			n = p
+		}
+	}
+	h.roots = h.roots[:0]
+	for _, n := range byHeight {
+		if n != nil {
+			h.roots = append(h.roots, n)
+		}
+	}
+}
+
+// quake finds the lowest height i+1 holding more than alpha times the nodes
+// of height i and removes every node above height i.
+func (h *QuakeHeap) quake() {
+	for i := 0; i+1 < len(h.counts); i++ {
+		if alphaDen*h.counts[i+1] <= alphaNum*h.counts[i] {
+			continue
+		}
+		roots := h.roots
+		h.roots = nil
+		for _, r := range roots {
+			if r.height <= i {
+				h.roots = append(h.roots, r)
+				continue
+			}
+			stack := []*node{r}
+			for len(stack) > 0 {
+				n := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if n.height == i {
+					n.parent = nil
+					n.elem.top = n
+					h.roots = append(h.roots, n)
+					continue
+				}
+				if n.right != nil {
+					stack = append(stack, n.right)
+				} else {
+					h.degreeOne--
+				}
+				stack = append(stack, n.left)
+			}
+		}
+		h.counts = h.counts[:i+1]
+		return
+	}
+}
+
+// find returns the element of the item that compares equal to item, or nil.
+// Subtrees whose root compares greater than item are skipped, and the
+// search stops at the first node comparing equal, whatever its height.
+func (h *QuakeHeap) find(item heap.Item) *Element {
+	stack := append([]*node(nil), h.roots...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		c := n.elem.item.Compare(item)
+		if c == 0 {
+			return n.elem
+		}
+		if c > 0 || n.height == 0 {
+			continue
+		}
+		if n.right != nil {
+			stack = append(stack, n.right)
+		}
+		stack = append(stack, n.left)
+	}
+	return nil
+}
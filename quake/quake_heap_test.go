@@ -0,0 +1,237 @@
+package quake
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// check verifies the tournament trees of h: every node represents the
+// smaller of its children, its left child represents the same element, the
+// counts by height match, and every element points at its highest node.
+func check(t *testing.T, h *QuakeHeap) {
+	t.Helper()
+	counts := make([]int, len(h.counts))
+	leaves, degreeOne := 0, 0
+	for _, r := range h.roots {
+		if r.parent != nil {
+			t.Fatalf("root %v has a parent", r.elem.item)
+		}
+		if r.elem.item.Compare(h.min.elem.item) < 0 {
+			t.Fatalf("root %v is less than the minimum %v", r.elem.item, h.min.elem.item)
+		}
+		stack := []*node{r}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if n.height >= len(counts) {
+				t.Fatalf("node %v of height %d above the counts", n.elem.item, n.height)
+			}
+			counts[n.height]++
+			if n.parent == nil || n.parent.elem != n.elem {
+				if n.elem.top != n {
+					t.Fatalf("element %v does not point at its highest node", n.elem.item)
+				}
+			}
+			if n.height == 0 {
+				leaves++
+				if n.left != nil || n.right != nil {
+					t.Fatalf("leaf %v has children", n.elem.item)
+				}
+				continue
+			}
+			if n.left == nil || n.left.elem != n.elem || n.left.parent != n || n.left.height != n.height-1 {
+				t.Fatalf("node %v has an invalid left child", n.elem.item)
+			}
+			stack = append(stack, n.left)
+			if n.right == nil {
+				degreeOne++
+				continue
+			}
+			if n.right.parent != n || n.right.height != n.height-1 || n.right.elem.item.Compare(n.elem.item) < 0 {
+				t.Fatalf("node %v has an invalid right child", n.elem.item)
+			}
+			stack = append(stack, n.right)
+		}
+	}
+	for height := range counts {
+		if counts[height] != h.counts[height] {
+			t.Fatalf("expected %d nodes of height %d, counted %d", h.counts[height], height, counts[height])
+		}
+	}
+	if leaves != h.size || degreeOne != h.degreeOne {
+		t.Fatalf("expected %d leaves and %d nodes of degree one, got %d and %d", h.size, h.degreeOne, leaves, degreeOne)
+	}
+}
+
+func TestQuakeHeap(t *testing.T) {
+	h := New()
+	if h.FindMin() != nil || h.DeleteMin() != nil {
+		t.Fatal("expected nil from an empty heap")
+	}
+	if h.FindMinElement() != nil {
+		t.Fatal("expected no element from an empty heap")
+	}
+	for _, v := range rand.Perm(1000) {
+		h.Insert(heap.Integer(v))
+	}
+	for i := 0; i < 1000; i++ {
+		if h.FindMin() != heap.Integer(i) || h.FindMinElement().Item() != heap.Integer(i) {
+			t.Fatalf("expected min %d, got %v", i, h.FindMin())
+		}
+		if item := h.DeleteMin(); item != heap.Integer(i) {
+			t.Fatalf("expected %d, got %v", i, item)
+		}
+		if i%100 == 0 {
+			check(t, h)
+		}
+		// quakes keep the trees of logarithmic height
+		height := 0
+		for _, r := range h.roots {
+			if r.height > height {
+				height = r.height
+			}
+		}
+		if n := h.Len(); n > 0 && float64(height) > math.Log(float64(n))/math.Log(4.0/3)+1 {
+			t.Fatalf("trees of height %d for %d items", height, n)
+		}
+	}
+	if h.Len() != 0 || h.FindMin() != nil {
+		t.Fatal("expected an empty heap")
+	}
+}
+
+func TestDecreaseKey(t *testing.T) {
+	h := New()
+	model := map[*Element]int{}
+	var elems []*Element
+	for step := 0; step < 5000; step++ {
+		switch op := rand.Intn(10); {
+		case op < 4:
+			v := rand.Intn(10000)
+			e := h.Push(heap.Integer(v))
+			model[e] = v
+			elems = append(elems, e)
+		case op < 7 && len(elems) > 0:
+			e := elems[rand.Intn(len(elems))]
+			v := model[e] - rand.Intn(100)
+			h.DecreaseKey(e, heap.Integer(v))
+			model[e] = v
+		case op < 8 && len(elems) > 0:
+			i := rand.Intn(len(elems))
+			e := elems[i]
+			if item := h.Remove(e); item != heap.Integer(model[e]) {
+				t.Fatalf("Remove returned %v, expected %d", item, model[e])
+			}
+			delete(model, e)
+			elems[i] = elems[len(elems)-1]
+			elems = elems[:len(elems)-1]
+		case len(elems) > 0:
+			min := math.MaxInt64
+			for _, v := range model {
+				if v < min {
+					min = v
+				}
+			}
+			item := h.DeleteMin().(heap.Integer)
+			if int(item) != min {
+				t.Fatalf("DeleteMin returned %v, expected %d", item, min)
+			}
+			for i, e := range elems {
+				if e.top == nil {
+					delete(model, e)
+					elems[i] = elems[len(elems)-1]
+					elems = elems[:len(elems)-1]
+					break
+				}
+			}
+		}
+		if h.Len() != len(model) {
+			t.Fatalf("expected %d items, got %d", len(model), h.Len())
+		}
+		if step%50 == 0 && h.Len() > 0 {
+			check(t, h)
+		}
+	}
+
+	// the minimum can be decreased and removed through its element
+	if h.Len() > 0 {
+		min := h.FindMinElement()
+		h.DecreaseKey(min, heap.Integer(-1))
+		if h.FindMin() != heap.Integer(-1) || h.Remove(min) != heap.Integer(-1) {
+			t.Fatal("expected to decrease and remove the minimum")
+		}
+	}
+
+	e := h.Push(heap.Integer(1))
+	assertPanics(t, func() { h.DecreaseKey(e, heap.Integer(2)) })
+	h.Remove(e)
+	assertPanics(t, func() { h.Remove(e) })
+	assertPanics(t, func() { h.DecreaseKey(e, heap.Integer(0)) })
+}
+
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	f()
+}
+
+func TestExtended(t *testing.T) {
+	h := New()
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	for i := 0; i < 10; i++ {
+		h.DeleteMin()
+	}
+	if h.Delete(heap.Integer(5)) != nil || h.Adjust(heap.Integer(5), heap.Integer(1)) != nil {
+		t.Fatal("expected nil for a missing item")
+	}
+	if h.Delete(heap.Integer(50)) != heap.Integer(50) {
+		t.Fatal("expected Delete to return 50")
+	}
+	h.Adjust(heap.Integer(60), heap.Integer(-1))
+	h.Adjust(heap.Integer(10), heap.Integer(200))
+	check(t, h)
+
+	o := New()
+	o.Insert(heap.Integer(-5))
+	o.Insert(heap.Integer(300))
+	h.Meld(o)
+	if o.Len() != 0 || o.FindMin() != nil {
+		t.Fatal("expected the melded heap to be empty")
+	}
+	check(t, h)
+
+	var got []int
+	h.Do(func(item heap.Item) bool {
+		got = append(got, int(item.(heap.Integer)))
+		return true
+	})
+	sort.Ints(got)
+	var want []int
+	for v := 11; v < 100; v++ {
+		if v != 50 && v != 60 {
+			want = append(want, v)
+		}
+	}
+	want = append([]int{-5, -1}, append(want, 200, 300)...)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if item := h.DeleteMin(); item != heap.Integer(want[i]) {
+			t.Fatalf("expected %d, got %v", want[i], item)
+		}
+	}
+}
@@ -127,15 +127,16 @@ func (r *RPHeap) Meld(a heap.Interface) heap.Interface {
 	if r0.head.item == nil {
 		return r
 	}
+	var mergeRes *RPHeap
 	if compare(r.head.item, r0.head.item) < 0 {
-		mergeRes := merge(r, r0)
-		r0.Clear()
-		return mergeRes
+		mergeRes = merge(r, r0)
 	} else {
-		mergeRes := merge(r0, r)
-		r0.Clear()
-		return mergeRes
+		mergeRes = merge(r0, r)
 	}
+	r0.Clear()
+	// keep r usable, not just the returned heap
+	r.head, r.size = mergeRes.head, mergeRes.size
+	return r
 }
 
 // Size returns the size of the RPHeap
@@ -235,7 +236,7 @@ func (r *RPHeap) decrease(ptr *node, val heap.Item) {
 // Find the pointer to an item
 // Complexity: O(n)
 func (r *RPHeap) find(root *node, val heap.Item) *node {
-	if root == nil {
+	if root == nil || root.item == nil { // nil item is the empty head
 		return nil
 	} else if compare(root.item, val) == 0 {
 		return root
@@ -0,0 +1,104 @@
+// Package ratelimit gates removals from a heap behind a token bucket.
+//
+// Tokens are refilled continuously at a fixed rate up to a burst size, and
+// each removed item consumes one token. Inserts are never limited.
+//
+// Structure is not thread safe.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Heap wraps a heap so that DeleteMin only succeeds while tokens are
+// available.
+type Heap struct {
+	h heap.Interface
+
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// Heap implements the Interface interface
+var _ heap.Interface = (*Heap)(nil)
+
+// New wraps h with a token bucket refilled with rate tokens per second and
+// holding at most burst tokens. The bucket starts full. It panics unless
+// rate is positive and burst at least 1, as the bucket would otherwise stay
+// empty for good once drained.
+func New(h heap.Interface, rate float64, burst int) *Heap {
+	if !(rate > 0) || burst < 1 {
+		panic(fmt.Sprintf("ratelimit: invalid rate of %v per second with burst %d", rate, burst))
+	}
+	l := &Heap{
+		h:     h,
+		rate:  rate,
+		burst: float64(burst),
+		now:   time.Now,
+	}
+	l.tokens = l.burst
+	l.last = l.now()
+	return l
+}
+
+// Insert adds an item into the underlying heap.
+func (l *Heap) Insert(item heap.Item) heap.Item {
+	return l.h.Insert(item)
+}
+
+// FindMin returns the minimum item without consuming a token.
+func (l *Heap) FindMin() heap.Item {
+	return l.h.FindMin()
+}
+
+// Clear removes all items from the underlying heap. The bucket is unchanged.
+func (l *Heap) Clear() {
+	l.h.Clear()
+}
+
+// DeleteMin removes and returns the minimum item if a token is available,
+// and nil otherwise. Use Pop to tell an empty heap from a throttled one.
+func (l *Heap) DeleteMin() heap.Item {
+	item, _ := l.Pop()
+	return item
+}
+
+// Pop removes and returns the minimum item, consuming one token. It returns
+// false without consuming a token if the heap is empty or the bucket is.
+func (l *Heap) Pop() (heap.Item, bool) {
+	if l.h.FindMin() == nil {
+		return nil, false
+	}
+	l.refill()
+	if l.tokens < 1 {
+		return nil, false
+	}
+	l.tokens--
+	return l.h.DeleteMin(), true
+}
+
+// Delay returns how long to wait until the next token is available.
+func (l *Heap) Delay() time.Duration {
+	l.refill()
+	if l.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *Heap) refill() {
+	now := l.now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newLimited(rate float64, burst int) (*Heap, *clock) {
+	c := &clock{t: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(pairing.New(), rate, burst)
+	l.now = c.now
+	l.last = c.t
+	return l, c
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	l, c := newLimited(10, 3)
+
+	if _, ok := l.Pop(); ok {
+		t.Fatal("popped from an empty heap")
+	}
+
+	for i := 10; i > 0; i-- {
+		l.Insert(heap.Integer(i))
+	}
+
+	for want := 1; want <= 3; want++ {
+		item, ok := l.Pop()
+		if !ok || item != heap.Integer(want) {
+			t.Fatalf("expected %d, got %v", want, item)
+		}
+	}
+	if _, ok := l.Pop(); ok {
+		t.Fatal("burst exceeded")
+	}
+	if l.DeleteMin() != nil {
+		t.Fatal("DeleteMin bypassed the limiter")
+	}
+	if d := l.Delay(); d != 100*time.Millisecond {
+		t.Fatalf("expected 100ms delay, got %v", d)
+	}
+	if l.FindMin() != heap.Integer(4) {
+		t.Fail()
+	}
+
+	c.t = c.t.Add(100 * time.Millisecond)
+	if item, ok := l.Pop(); !ok || item != heap.Integer(4) {
+		t.Fatalf("expected 4 after refill, got %v", item)
+	}
+
+	// a long pause refills up to the burst only
+	c.t = c.t.Add(time.Hour)
+	popped := 0
+	for {
+		if _, ok := l.Pop(); !ok {
+			break
+		}
+		popped++
+	}
+	if popped != 3 {
+		t.Fatalf("expected 3 pops after refill, got %d", popped)
+	}
+}
+
+func TestInvalidBucket(t *testing.T) {
+	for _, c := range []struct {
+		rate  float64
+		burst int
+	}{{0, 1}, {-1, 1}, {1, 0}, {1, -1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected New(%v, %d) to panic", c.rate, c.burst)
+				}
+			}()
+			New(pairing.New(), c.rate, c.burst)
+		}()
+	}
+}
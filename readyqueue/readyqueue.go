@@ -0,0 +1,214 @@
+// Package readyqueue implements a topological priority queue, the core of
+// build schedulers and pipeline engines.
+//
+// Items are added with a priority and the keys of the items they depend
+// on, and each keeps a count of its unmet dependencies. Only ready items,
+// whose dependencies are all done, can be popped, in order of priority.
+// Popping an item does not complete it: it is running until MarkDone
+// reports it done, which releases the items that were waiting only for it
+// into the queue. Items can thus be handed to workers and completed in any
+// order.
+//
+// Ready items are kept in a pairing heap. Priorities are compared with
+// Compare, lower first, and ties are broken in order of addition. Done
+// items are remembered, so that items added later can depend on them.
+//
+// Structure is not thread safe.
+package readyqueue
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// State is the stage of an item.
+type State int
+
+const (
+	// Unknown is the state of keys that were never added.
+	Unknown State = iota
+	// Blocked items wait for some of their dependencies.
+	Blocked
+	// Ready items have all their dependencies done and can be popped.
+	Ready
+	// Running items were popped and are not done yet.
+	Running
+	// Done items were marked done.
+	Done
+)
+
+var stateNames = [...]string{
+	Unknown: "Unknown",
+	Blocked: "Blocked",
+	Ready:   "Ready",
+	Running: "Running",
+	Done:    "Done",
+}
+
+func (s State) String() string {
+	if s >= 0 && int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+type item struct {
+	key        interface{}
+	id         uint64 // breaks ties between items, in order of addition
+	priority   heap.Item
+	state      State
+	unmet      int     // dependencies not done yet
+	dependents []*item // items waiting for this one
+}
+
+// entry is the heap item of a ready item.
+type entry struct {
+	priority heap.Item
+	item     *item
+}
+
+func (e entry) Compare(than heap.Item) int {
+	o := than.(entry)
+	if c := e.priority.Compare(o.priority); c != 0 {
+		return c
+	}
+	switch {
+	case e.item.id < o.item.id:
+		return -1
+	case e.item.id > o.item.id:
+		return 1
+	}
+	return 0
+}
+
+// Queue is a topological priority queue.
+type Queue struct {
+	items   map[interface{}]*item
+	heap    *pairing.PairHeap
+	nextID  uint64
+	blocked int
+	running int
+}
+
+// New returns an empty Queue.
+func New() *Queue {
+	return &Queue{
+		items: make(map[interface{}]*item),
+		heap:  pairing.New(),
+	}
+}
+
+// Len returns the number of items that are not done.
+func (q *Queue) Len() int {
+	return q.blocked + q.heap.Len() + q.running
+}
+
+// Ready returns the number of items that can be popped.
+func (q *Queue) Ready() int {
+	return q.heap.Len()
+}
+
+// Blocked returns the number of items waiting for their dependencies.
+func (q *Queue) Blocked() int {
+	return q.blocked
+}
+
+// Running returns the number of items popped and not done yet.
+func (q *Queue) Running() int {
+	return q.running
+}
+
+// State returns the state of key.
+func (q *Queue) State(key interface{}) State {
+	if it, ok := q.items[key]; ok {
+		return it.state
+	}
+	return Unknown
+}
+
+// Add adds the item key with priority, depending on the items deps. Every
+// dependency must have been added before, which keeps the graph acyclic;
+// the ones already done are met. It panics if key was already added or a
+// dependency is unknown.
+// The complexity is O(d) for d dependencies.
+func (q *Queue) Add(key interface{}, priority heap.Item, deps ...interface{}) {
+	if _, ok := q.items[key]; ok {
+		panic(fmt.Sprintf("readyqueue: item %v was already added", key))
+	}
+	it := &item{key: key, id: q.nextID, priority: priority}
+	for _, key := range deps {
+		d, ok := q.items[key]
+		if !ok {
+			panic(fmt.Sprintf("readyqueue: unknown dependency %v of %v", key, it.key))
+		}
+		if d.state != Done {
+			d.dependents = append(d.dependents, it)
+			it.unmet++
+		}
+	}
+	q.nextID++
+	q.items[key] = it
+	if it.unmet > 0 {
+		it.state = Blocked
+		q.blocked++
+	} else {
+		q.push(it)
+	}
+}
+
+// Peek returns the ready item Pop would return and its priority without
+// removing it. ok is false if no item is ready.
+// The complexity is O(1).
+func (q *Queue) Peek() (key interface{}, priority heap.Item, ok bool) {
+	if q.heap.IsEmpty() {
+		return nil, nil, false
+	}
+	e := q.heap.FindMin().(entry)
+	return e.item.key, e.priority, true
+}
+
+// Pop removes the ready item with the lowest priority and returns it with
+// its priority. The item is running until it is marked done. ok is false if
+// no item is ready, either because the queue is empty or because every
+// remaining item waits for a running one.
+// The complexity is O(log n) amortized.
+func (q *Queue) Pop() (key interface{}, priority heap.Item, ok bool) {
+	if q.heap.IsEmpty() {
+		return nil, nil, false
+	}
+	e := q.heap.DeleteMin().(entry)
+	e.item.state = Running
+	q.running++
+	return e.item.key, e.priority, true
+}
+
+// MarkDone marks the running item key done and returns the keys of the
+// items it released into the queue, in order of addition. It panics if key
+// is not running.
+// The complexity is O(k) for k dependents.
+func (q *Queue) MarkDone(key interface{}) []interface{} {
+	it, ok := q.items[key]
+	if !ok || it.state != Running {
+		panic(fmt.Sprintf("readyqueue: MarkDone(%v) on a %v item", key, q.State(key)))
+	}
+	it.state = Done
+	q.running--
+	var released []interface{}
+	for _, d := range it.dependents {
+		d.unmet--
+		if d.unmet == 0 {
+			q.blocked--
+			q.push(d)
+			released = append(released, d.key)
+		}
+	}
+	it.dependents = nil
+	return released
+}
+
+func (q *Queue) push(it *item) {
+	it.state = Ready
+	q.heap.Insert(entry{priority: it.priority, item: it})
+}
@@ -0,0 +1,121 @@
+package readyqueue
+
+import (
+	"math/rand"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+func TestBuild(t *testing.T) {
+	q := New()
+	q.Add("fetch", heap.Integer(5))
+	q.Add("lint", heap.Integer(9))
+	q.Add("compile", heap.Integer(1), "fetch")
+	q.Add("test", heap.Integer(2), "compile")
+	q.Add("package", heap.Integer(0), "compile", "lint")
+	if q.Len() != 5 || q.Ready() != 2 || q.Blocked() != 3 {
+		t.Fatalf("unexpected counts %d/%d/%d", q.Len(), q.Ready(), q.Blocked())
+	}
+
+	key, _, _ := q.Pop()
+	if key != "fetch" || q.State("fetch") != Running {
+		t.Fatalf("expected fetch to run, got %v", key)
+	}
+	key, _, _ = q.Pop()
+	if key != "lint" {
+		t.Fatalf("expected lint to run, got %v", key)
+	}
+	if _, _, ok := q.Pop(); ok {
+		t.Fatal("expected nothing ready while fetch and lint run")
+	}
+
+	if released := q.MarkDone("lint"); len(released) != 0 {
+		t.Fatalf("expected nothing released by lint, got %v", released)
+	}
+	if released := q.MarkDone("fetch"); len(released) != 1 || released[0] != "compile" {
+		t.Fatalf("expected compile released, got %v", released)
+	}
+	q.Pop()
+	released := q.MarkDone("compile")
+	if len(released) != 2 || released[0] != "test" || released[1] != "package" {
+		t.Fatalf("expected test and package released, got %v", released)
+	}
+	if key, p, _ := q.Peek(); key != "package" || p != heap.Integer(0) {
+		t.Fatalf("expected package first, got %v at %v", key, p)
+	}
+
+	// done items satisfy later dependencies right away
+	q.Add("deploy", heap.Integer(-1), "lint")
+	if q.State("deploy") != Ready || q.State("lint") != Done || q.State("missing") != Unknown {
+		t.Fatalf("unexpected states %v, %v", q.State("deploy"), q.State("lint"))
+	}
+}
+
+func TestPanics(t *testing.T) {
+	q := New()
+	q.Add(1, heap.Integer(1))
+	q.Add(2, heap.Integer(1), 1)
+	for name, fn := range map[string]func(){
+		"duplicate":          func() { q.Add(1, heap.Integer(0)) },
+		"unknown dependency": func() { q.Add(3, heap.Integer(0), 4) },
+		"done while ready":   func() { q.MarkDone(1) },
+		"done while blocked": func() { q.MarkDone(2) },
+		"done when unknown":  func() { q.MarkDone(5) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestRandomDAG(t *testing.T) {
+	const n = 500
+	r := rand.New(rand.NewSource(1))
+	q := New()
+	deps := make([][]int, n)
+	for i := 0; i < n; i++ {
+		var keys []interface{}
+		for j := 0; j < i; j++ {
+			if r.Intn(i) < 3 {
+				deps[i] = append(deps[i], j)
+				keys = append(keys, j)
+			}
+		}
+		q.Add(i, heap.Integer(r.Intn(50)), keys...)
+	}
+
+	done := make([]bool, n)
+	var running []int
+	for count := 0; count < n; {
+		// start up to 4 items, then finish a random running one
+		for len(running) < 4 {
+			key, _, ok := q.Pop()
+			if !ok {
+				break
+			}
+			for _, d := range deps[key.(int)] {
+				if !done[d] {
+					t.Fatalf("%v popped before its dependency %d", key, d)
+				}
+			}
+			running = append(running, key.(int))
+		}
+		if len(running) == 0 {
+			t.Fatalf("no item ready with %d left", q.Len())
+		}
+		i := r.Intn(len(running))
+		done[running[i]] = true
+		q.MarkDone(running[i])
+		running = append(running[:i], running[i+1:]...)
+		count++
+	}
+	if q.Len() != 0 || q.Running() != 0 {
+		t.Fatalf("expected an empty queue, got %d items", q.Len())
+	}
+}
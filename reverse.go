@@ -0,0 +1,21 @@
+package go_heaps
+
+// Reversed wraps an Item and inverts its ordering, turning any min heap of
+// this collection into a max heap without a dedicated implementation.
+//
+// Heaps look items up by comparison, so Find, Delete and Adjust must be
+// called with reversed values as well, e.g. h.Delete(Reverse(Integer(4))).
+// Values returned by the heap are Reversed; the original is in the Item field.
+type Reversed struct {
+	Item
+}
+
+// Reverse returns item with its ordering inverted.
+func Reverse(item Item) Item {
+	return Reversed{Item: item}
+}
+
+// Compare implements the Item interface.
+func (r Reversed) Compare(b Item) int {
+	return b.(Reversed).Item.Compare(r.Item)
+}
@@ -0,0 +1,162 @@
+package runs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// magic starts every run written by WriteRun. It is followed by the
+// entries, each laid out as a flags byte, where bit 0 marks a tombstone,
+// the key length and the value length as uvarints, the key and the value.
+const magic = "GORUNS01"
+
+const flagTombstone = 1
+
+// WriteRun writes the entries of it to w as a run and returns their number.
+// The keys must be strictly ascending.
+func WriteRun(w io.Writer, it Iterator) (int, error) {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(magic)
+	var prev []byte
+	var buf [2 * binary.MaxVarintLen64]byte
+	n := 0
+	for it.Next() {
+		e := it.Entry()
+		if n > 0 && bytes.Compare(e.Key, prev) <= 0 {
+			return n, fmt.Errorf("runs: key %q does not follow %q", e.Key, prev)
+		}
+		prev = append(prev[:0], e.Key...)
+		var flags byte
+		if e.Tombstone {
+			flags |= flagTombstone
+		}
+		bw.WriteByte(flags)
+		l := binary.PutUvarint(buf[:], uint64(len(e.Key)))
+		l += binary.PutUvarint(buf[l:], uint64(len(e.Value)))
+		bw.Write(buf[:l])
+		bw.Write(e.Key)
+		if _, err := bw.Write(e.Value); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// Run is a run written by WriteRun, held in memory.
+type Run struct {
+	data  []byte
+	unmap func() error
+}
+
+// NewRun returns the run encoded in data, as written by WriteRun.
+func NewRun(data []byte) (*Run, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errors.New("runs: not a run")
+	}
+	return &Run{data: data}, nil
+}
+
+// OpenRun maps the run stored in the file at path into memory. Its entries
+// point into the mapping, so they must not be used after Close. Where mmap
+// is not available the file is read instead.
+func OpenRun(path string) (*Run, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < int64(len(magic)) {
+		return nil, fmt.Errorf("runs: %s is not a run", path)
+	}
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRun(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("runs: %s is not a run", path)
+	}
+	r.unmap = unmap
+	return r, nil
+}
+
+// Close releases the mapping of a run opened by OpenRun.
+func (r *Run) Close() error {
+	if r.unmap == nil {
+		return nil
+	}
+	err := r.unmap()
+	r.data, r.unmap = nil, nil
+	return err
+}
+
+// Iter returns an iterator over the entries of the run. It fails if the run
+// is corrupt or its keys are not strictly ascending.
+func (r *Run) Iter() Iterator {
+	return &runIterator{data: r.data, off: len(magic)}
+}
+
+type runIterator struct {
+	data  []byte
+	off   int
+	entry Entry
+	valid bool
+	err   error
+}
+
+func (it *runIterator) Next() bool {
+	if it.err != nil || it.off >= len(it.data) {
+		return false
+	}
+	start := it.off
+	flags := it.data[it.off]
+	it.off++
+	keyLen, n := binary.Uvarint(it.data[it.off:])
+	if n <= 0 {
+		return it.corrupt(start)
+	}
+	it.off += n
+	valueLen, n := binary.Uvarint(it.data[it.off:])
+	if n <= 0 {
+		return it.corrupt(start)
+	}
+	it.off += n
+	if flags&^flagTombstone != 0 || keyLen > uint64(len(it.data)-it.off) ||
+		valueLen > uint64(len(it.data)-it.off)-keyLen {
+		return it.corrupt(start)
+	}
+	key := it.data[it.off : it.off+int(keyLen)]
+	it.off += int(keyLen)
+	value := it.data[it.off : it.off+int(valueLen)]
+	it.off += int(valueLen)
+	if it.valid && bytes.Compare(key, it.entry.Key) <= 0 {
+		it.err = fmt.Errorf("runs: key %q does not follow %q", key, it.entry.Key)
+		return false
+	}
+	it.entry = Entry{Key: key, Value: value, Tombstone: flags&flagTombstone != 0}
+	it.valid = true
+	return true
+}
+
+func (it *runIterator) corrupt(off int) bool {
+	it.err = fmt.Errorf("runs: corrupt entry at offset %d", off)
+	return false
+}
+
+func (it *runIterator) Entry() Entry { return it.entry }
+
+func (it *runIterator) Err() error { return it.err }
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package runs
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the size bytes of f read only. The mapping outlives f.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,176 @@
+// Package runs merges sorted runs of key/value entries, as a building block
+// for the compaction of log-structured merge trees.
+//
+// A run is a sequence of entries in strictly ascending key order, such as
+// an SSTable. Merge combines runs into a single iterator by keeping the
+// current entry of every run in a pairing heap ordered by key, so the next
+// entry is found in O(log k) for k runs. Runs are listed from the newest to
+// the oldest: when several runs hold the same key, the entry of the newest
+// one wins and the others are skipped. Deletions are recorded as tombstone
+// entries, which Merge emits like the other entries so that they keep
+// shadowing older runs, or drops with DropTombstones when the merged runs
+// are the oldest ones, as in a compaction into the bottom level.
+//
+// WriteRun writes a run to a file, and OpenRun maps it back into memory to
+// iterate over it without copying the entries.
+//
+// Structure is not thread safe.
+package runs
+
+import (
+	"bytes"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+// Entry is a key/value entry of a run. A tombstone records the deletion of
+// its key and has no value.
+type Entry struct {
+	Key       []byte
+	Value     []byte
+	Tombstone bool
+}
+
+// Iterator iterates over the entries of a run in ascending key order, with
+// every key at most once. The slices of an entry must stay valid until
+// the next call to Next.
+type Iterator interface {
+	// Next advances to the next entry and reports whether there is one.
+	Next() bool
+	// Entry returns the current entry.
+	Entry() Entry
+	// Err returns the error that stopped the iteration, if any.
+	Err() error
+}
+
+// FromEntries returns an iterator over entries, which must be sorted by
+// key, for runs held in memory such as a memtable.
+func FromEntries(entries []Entry) Iterator {
+	return &sliceIterator{entries: entries, i: -1}
+}
+
+type sliceIterator struct {
+	entries []Entry
+	i       int
+}
+
+func (s *sliceIterator) Next() bool {
+	if s.i < len(s.entries) {
+		s.i++
+	}
+	return s.i < len(s.entries)
+}
+
+func (s *sliceIterator) Entry() Entry { return s.entries[s.i] }
+
+func (s *sliceIterator) Err() error { return nil }
+
+// cursor is the current entry of a run in the merge heap.
+type cursor struct {
+	it    Iterator
+	entry Entry
+	age   int // index of the run, lower is newer
+}
+
+// Compare orders cursors by key, then from the newest run to the oldest.
+func (c *cursor) Compare(than heap.Item) int {
+	o := than.(*cursor)
+	if d := bytes.Compare(c.entry.Key, o.entry.Key); d != 0 {
+		return d
+	}
+	return c.age - o.age
+}
+
+// Option configures a Merged iterator created by Merge.
+type Option func(*Merged)
+
+// DropTombstones makes the merged iterator skip the tombstones that win
+// over the older runs, so deleted keys disappear from its output. Use it
+// only when no run older than the merged ones remains, since the
+// tombstones would otherwise no longer hide its entries.
+func DropTombstones() Option {
+	return func(m *Merged) {
+		m.dropTombstones = true
+	}
+}
+
+// Merged iterates over the union of several runs in key order, resolving
+// duplicate keys in favour of the newest run.
+type Merged struct {
+	heap           *pairing.PairHeap
+	current        *cursor // the cursor of the current entry, advanced by Next
+	err            error
+	dropTombstones bool
+}
+
+// Merged implements the Iterator interface
+var _ Iterator = (*Merged)(nil)
+
+// Merge returns an iterator over the union of runs, listed from the newest
+// to the oldest. Each run must be sorted by key with no duplicates.
+func Merge(runs []Iterator, opts ...Option) *Merged {
+	m := &Merged{heap: pairing.New()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	for age, it := range runs {
+		m.advance(&cursor{it: it, age: age})
+	}
+	return m
+}
+
+// advance moves c to the next entry of its run and puts it back in the
+// heap, unless the run is over.
+func (m *Merged) advance(c *cursor) {
+	if c.it.Next() {
+		c.entry = c.it.Entry()
+		m.heap.Insert(c)
+	} else if err := c.it.Err(); err != nil && m.err == nil {
+		m.err = err
+	}
+}
+
+// Next advances to the next entry in key order and reports whether there
+// is one. It returns false once every run is over or one of them fails.
+// The complexity is O(d log k) for k runs, where d is the number of runs
+// holding the key of the entry plus the number of skipped tombstones.
+func (m *Merged) Next() bool {
+	if m.current != nil {
+		m.advance(m.current)
+		m.current = nil
+	}
+	for m.err == nil {
+		min := m.heap.DeleteMin()
+		if min == nil {
+			return false
+		}
+		c := min.(*cursor)
+		// skip the same key in older runs
+		for {
+			top, _ := m.heap.FindMin().(*cursor)
+			if top == nil || !bytes.Equal(top.entry.Key, c.entry.Key) {
+				break
+			}
+			m.heap.DeleteMin()
+			m.advance(top)
+		}
+		if c.entry.Tombstone && m.dropTombstones {
+			m.advance(c)
+			continue
+		}
+		m.current = c
+		return m.err == nil
+	}
+	return false
+}
+
+// Entry returns the current entry.
+func (m *Merged) Entry() Entry {
+	return m.current.entry
+}
+
+// Err returns the error of the first run that failed, if any.
+func (m *Merged) Err() error {
+	return m.err
+}
@@ -0,0 +1,152 @@
+package runs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// entries parses "key=value" and "key-" for a tombstone.
+func entries(specs ...string) []Entry {
+	var es []Entry
+	for _, s := range specs {
+		if strings.HasSuffix(s, "-") {
+			es = append(es, Entry{Key: []byte(s[:len(s)-1]), Tombstone: true})
+			continue
+		}
+		kv := strings.SplitN(s, "=", 2)
+		es = append(es, Entry{Key: []byte(kv[0]), Value: []byte(kv[1])})
+	}
+	return es
+}
+
+func collect(t *testing.T, it Iterator) []string {
+	t.Helper()
+	var got []string
+	for it.Next() {
+		e := it.Entry()
+		if e.Tombstone {
+			got = append(got, string(e.Key)+"-")
+		} else {
+			got = append(got, string(e.Key)+"="+string(e.Value))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestMerge(t *testing.T) {
+	newest := entries("b=3", "d-", "f=3")
+	middle := entries("a=2", "b=2", "c-", "e=2")
+	oldest := entries("a=1", "c=1", "d=1", "e=1", "g=1")
+	iters := func() []Iterator {
+		return []Iterator{FromEntries(newest), FromEntries(middle), FromEntries(oldest)}
+	}
+
+	got := fmt.Sprint(collect(t, Merge(iters())))
+	if want := "[a=2 b=3 c- d- e=2 f=3 g=1]"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	got = fmt.Sprint(collect(t, Merge(iters(), DropTombstones())))
+	if want := "[a=2 b=3 e=2 f=3 g=1]"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if Merge(nil).Next() {
+		t.Fatal("expected no entries from no runs")
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// compact two runs into a file
+	path := filepath.Join(dir, "run")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := WriteRun(f, Merge([]Iterator{
+		FromEntries(entries("a-", "c=new")),
+		FromEntries(entries("a=old", "b=old", "c=old", "d=")),
+	}))
+	f.Close()
+	if err != nil || n != 4 {
+		t.Fatalf("expected 4 entries written, got %d, %v", n, err)
+	}
+
+	r, err := OpenRun(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got := fmt.Sprint(collect(t, r.Iter()))
+	if want := "[a- b=old c=new d=]"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	// merge the mapped run with a newer one in memory
+	got = fmt.Sprint(collect(t, Merge([]Iterator{FromEntries(entries("b-")), r.Iter()}, DropTombstones())))
+	if want := "[c=new d=]"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	ioutil.WriteFile(path, []byte("GORUN"), 0644)
+	if _, err := OpenRun(path); err == nil {
+		t.Fatal("expected an error for a file too short")
+	}
+	ioutil.WriteFile(path, []byte("NOTARUN!"), 0644)
+	if _, err := OpenRun(path); err == nil {
+		t.Fatal("expected an error for a file without the header")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := WriteRun(&buf, FromEntries(entries("b=1", "a=1"))); err == nil {
+		t.Fatal("expected an error for unsorted keys")
+	}
+	if _, err := NewRun([]byte("garbage")); err == nil {
+		t.Fatal("expected an error for data without the header")
+	}
+
+	buf.Reset()
+	WriteRun(&buf, FromEntries(entries("a=1", "b=22")))
+	data := buf.Bytes()
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", data[:len(data)-1]},
+		{"bad flags", append(append([]byte{}, data[:len(magic)]...), 2, 1, 0, 'a')},
+		// the same entry twice
+		{"unsorted", append(append([]byte{}, data...), data[len(magic):len(magic)+5]...)},
+	} {
+		r, err := NewRun(c.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		it := r.Iter()
+		for it.Next() {
+		}
+		if it.Err() == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+		// the error stops a merge too
+		m := Merge([]Iterator{r.Iter(), FromEntries(entries("z=1"))})
+		for m.Next() {
+		}
+		if m.Err() == nil {
+			t.Errorf("%s: expected the merge to fail", c.name)
+		}
+	}
+}
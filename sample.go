@@ -0,0 +1,89 @@
+package go_heaps
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SampleWithoutReplacement returns k items of h picked at random without
+// replacement, each draw choosing among the items left with probability
+// proportional to weight(item). Items of zero, negative or NaN weight are
+// never picked, and fewer than k items are returned when fewer have a
+// positive weight. The items are returned in the order they were drawn.
+//
+// Every item gets the key log(u)/weight for u uniform in (0, 1], and the k
+// largest keys are kept in a bounded min heap (Efraimidis and Spirakis,
+// "Weighted random sampling with a reservoir", 2006). h is left unchanged:
+// heaps implementing Do(ItemIterator) are walked in place, and other heaps
+// are drained and refilled. It is meant for probabilistic load shedding,
+// picking the items to drop from a queue with Extended.Delete.
+// The complexity is O(n log k) for n items, plus a drain and a refill of h
+// when it does not implement Do.
+func SampleWithoutReplacement(h Interface, k int, weight func(Item) float64) []Item {
+	if k <= 0 {
+		return nil
+	}
+	keys := make([]Item, 0, k)
+	visit := func(item Item) bool {
+		w := weight(item)
+		if !(w > 0) {
+			return true
+		}
+		key := sampleKey{math.Log(1-rand.Float64()) / w, item}
+		switch {
+		case len(keys) < k:
+			keys = append(keys, key)
+			if len(keys) == k {
+				Heapify(keys)
+			}
+		case key.Compare(keys[0]) > 0:
+			keys[0] = key
+			siftDown(keys, 0)
+		}
+		return true
+	}
+
+	if d, ok := h.(interface {
+		Do(it ItemIterator)
+	}); ok {
+		d.Do(visit)
+	} else {
+		var items []Item
+		for item := h.DeleteMin(); item != nil; item = h.DeleteMin() {
+			items = append(items, item)
+		}
+		for _, item := range items {
+			visit(item)
+		}
+		PushMany(h, items...)
+	}
+
+	if len(keys) < k {
+		Heapify(keys)
+	}
+	// popping the smallest keys first fills the sample from its end
+	sample := make([]Item, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		sample[i] = keys[0].(sampleKey).item
+		keys[0] = keys[i]
+		keys = keys[:i]
+		siftDown(keys, 0)
+	}
+	return sample
+}
+
+// sampleKey is an item with its random sampling key.
+type sampleKey struct {
+	key  float64
+	item Item
+}
+
+func (s sampleKey) Compare(b Item) int {
+	switch o := b.(sampleKey); {
+	case s.key < o.key:
+		return -1
+	case s.key > o.key:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,22 @@
+// Package shm implements an experimental binary heap living in a
+// memory-mapped file, so that producer and consumer processes can share
+// one priority queue without a broker.
+//
+// The file starts with a header recording its layout, followed by a fixed
+// array of records laid out as an implicit binary heap. Each record holds
+// an int64 priority, lower first, and a payload of up to the payload size
+// given when the file was created:
+//
+//	header  magic "GOHEAPS1", capacity, payload size, length (uint64 each)
+//	record  priority (int64), payload length (uint32), payload
+//
+// All integers are little endian. Every operation takes an exclusive
+// flock(2) on the file for its duration, which is the whole locking
+// protocol: any process that opens the file with Open cooperates, and a
+// process that dies holding the lock releases it with its descriptors.
+// Consumers poll, as there is no cross-process notification.
+//
+// Items of equal priority are popped in no particular order. The heap is
+// only available on Unix systems supporting mmap and flock; elsewhere the
+// package is empty.
+package shm
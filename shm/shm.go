@@ -0,0 +1,300 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package shm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+const (
+	magic      = "GOHEAPS1"
+	headerSize = 32
+	// offsets in the header
+	capOffset     = 8
+	payloadOffset = 16
+	lenOffset     = 24
+	// record prefix: priority and payload length
+	prefixSize = 12
+)
+
+var (
+	// ErrFull is returned by Push when the heap holds as many records as
+	// its capacity.
+	ErrFull = errors.New("shm: heap is full")
+	// ErrClosed is returned by the operations of a closed Heap.
+	ErrClosed = errors.New("shm: heap is closed")
+)
+
+var order = binary.LittleEndian
+
+// Heap is a handle on a heap stored in a memory-mapped file. A Heap is
+// safe for use by multiple goroutines: flock excludes other handles but not
+// the holders of the same one, so operations also take a mutex.
+type Heap struct {
+	mu          sync.Mutex
+	closed      bool
+	f           *os.File
+	data        []byte
+	capacity    int
+	payloadSize int
+	recordSize  int
+	scratch     []byte // used to swap records, under the file lock
+}
+
+// Open maps the heap stored in the file at path, creating it with room for
+// capacity records of payloads up to payloadSize bytes if it does not
+// exist or is empty. An existing heap must have been created with the same
+// capacity and payload size.
+func Open(path string, capacity, payloadSize int) (*Heap, error) {
+	if capacity < 1 || payloadSize < 0 {
+		return nil, fmt.Errorf("shm: invalid capacity %d or payload size %d", capacity, payloadSize)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	h := &Heap{
+		f:           f,
+		capacity:    capacity,
+		payloadSize: payloadSize,
+		recordSize:  prefixSize + payloadSize,
+	}
+	h.scratch = make([]byte, h.recordSize)
+	if err := h.mmap(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// mmap initializes the file if it is empty, checks its header and maps it.
+func (h *Heap) mmap() error {
+	if err := h.lock(); err != nil {
+		return err
+	}
+	defer h.unlock()
+
+	size := int64(headerSize + h.capacity*h.recordSize)
+	info, err := h.f.Stat()
+	if err != nil {
+		return err
+	}
+	fresh := info.Size() == 0
+	if fresh {
+		if err := h.f.Truncate(size); err != nil {
+			return err
+		}
+	} else if info.Size() != size {
+		return fmt.Errorf("shm: %s has size %d, expected %d", h.f.Name(), info.Size(), size)
+	}
+	h.data, err = syscall.Mmap(int(h.f.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+
+	if fresh {
+		copy(h.data, magic)
+		order.PutUint64(h.data[capOffset:], uint64(h.capacity))
+		order.PutUint64(h.data[payloadOffset:], uint64(h.payloadSize))
+		return nil
+	}
+	if !bytes.Equal(h.data[:len(magic)], []byte(magic)) ||
+		order.Uint64(h.data[capOffset:]) != uint64(h.capacity) ||
+		order.Uint64(h.data[payloadOffset:]) != uint64(h.payloadSize) {
+		syscall.Munmap(h.data)
+		h.data = nil
+		return fmt.Errorf("shm: %s is not a heap of %d records of %d bytes", h.f.Name(), h.capacity, h.payloadSize)
+	}
+	return nil
+}
+
+// Close unmaps the heap and closes the file. The heap stays in the file.
+func (h *Heap) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	h.closed = true
+	err := syscall.Munmap(h.data)
+	h.data = nil
+	if cerr := h.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Cap returns the number of records the heap can hold.
+func (h *Heap) Cap() int {
+	return h.capacity
+}
+
+// Len returns the number of records in the heap.
+func (h *Heap) Len() (int, error) {
+	if err := h.lock(); err != nil {
+		return 0, err
+	}
+	defer h.unlock()
+	return h.len(), nil
+}
+
+// Push adds payload to the heap at priority. It returns ErrFull if the heap
+// is full.
+// The complexity is O(log n).
+func (h *Heap) Push(priority int64, payload []byte) error {
+	if len(payload) > h.payloadSize {
+		return fmt.Errorf("shm: payload of %d bytes exceeds %d", len(payload), h.payloadSize)
+	}
+	if err := h.lock(); err != nil {
+		return err
+	}
+	defer h.unlock()
+	n := h.len()
+	if n == h.capacity {
+		return ErrFull
+	}
+	r := h.record(n)
+	order.PutUint64(r, uint64(priority))
+	order.PutUint32(r[8:], uint32(len(payload)))
+	copy(r[prefixSize:], payload)
+	h.setLen(n + 1)
+	h.up(n)
+	return nil
+}
+
+// Peek returns the record with the lowest priority without removing it. ok
+// is false if the heap is empty.
+// The complexity is O(1).
+func (h *Heap) Peek() (priority int64, payload []byte, ok bool, err error) {
+	if err := h.lock(); err != nil {
+		return 0, nil, false, err
+	}
+	defer h.unlock()
+	if h.len() == 0 {
+		return 0, nil, false, nil
+	}
+	priority, payload = h.read(0)
+	return priority, payload, true, nil
+}
+
+// Pop removes and returns the record with the lowest priority. ok is false
+// if the heap is empty.
+// The complexity is O(log n).
+func (h *Heap) Pop() (priority int64, payload []byte, ok bool, err error) {
+	if err := h.lock(); err != nil {
+		return 0, nil, false, err
+	}
+	defer h.unlock()
+	n := h.len()
+	if n == 0 {
+		return 0, nil, false, nil
+	}
+	priority, payload = h.read(0)
+	h.swap(0, n-1)
+	h.setLen(n - 1)
+	h.down(0)
+	return priority, payload, true, nil
+}
+
+// Clear removes all records from the heap.
+func (h *Heap) Clear() error {
+	if err := h.lock(); err != nil {
+		return err
+	}
+	defer h.unlock()
+	h.setLen(0)
+	return nil
+}
+
+// lock takes the mutex of h and the lock of the file.
+func (h *Heap) lock() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrClosed
+	}
+	for {
+		err := syscall.Flock(int(h.f.Fd()), syscall.LOCK_EX)
+		if err == nil {
+			return nil
+		}
+		if err != syscall.EINTR {
+			h.mu.Unlock()
+			return err
+		}
+	}
+}
+
+func (h *Heap) unlock() {
+	syscall.Flock(int(h.f.Fd()), syscall.LOCK_UN)
+	h.mu.Unlock()
+}
+
+func (h *Heap) len() int {
+	return int(order.Uint64(h.data[lenOffset:]))
+}
+
+func (h *Heap) setLen(n int) {
+	order.PutUint64(h.data[lenOffset:], uint64(n))
+}
+
+func (h *Heap) record(i int) []byte {
+	off := headerSize + i*h.recordSize
+	return h.data[off : off+h.recordSize]
+}
+
+func (h *Heap) priority(i int) int64 {
+	return int64(order.Uint64(h.record(i)))
+}
+
+// read returns the priority of record i and a copy of its payload.
+func (h *Heap) read(i int) (int64, []byte) {
+	r := h.record(i)
+	n := order.Uint32(r[8:])
+	payload := make([]byte, n)
+	copy(payload, r[prefixSize:])
+	return int64(order.Uint64(r)), payload
+}
+
+func (h *Heap) swap(i, j int) {
+	a, b := h.record(i), h.record(j)
+	copy(h.scratch, a)
+	copy(a, b)
+	copy(b, h.scratch)
+}
+
+func (h *Heap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.priority(i) >= h.priority(parent) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *Heap) down(i int) {
+	n := h.len()
+	for {
+		child := 2*i + 1
+		if child >= n {
+			break
+		}
+		if right := child + 1; right < n && h.priority(right) < h.priority(child) {
+			child = right
+		}
+		if h.priority(child) >= h.priority(i) {
+			break
+		}
+		h.swap(i, child)
+		i = child
+	}
+}
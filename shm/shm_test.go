@@ -0,0 +1,142 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package shm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func tempHeap(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "shm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "heap"), func() { os.RemoveAll(dir) }
+}
+
+func TestProducerConsumer(t *testing.T) {
+	path, cleanup := tempHeap(t)
+	defer cleanup()
+
+	producer, err := Open(path, 100, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+	consumer, err := Open(path, 100, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range rand.Perm(100) {
+		if err := producer.Push(int64(p), []byte(fmt.Sprint("job", p))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := producer.Push(0, nil); err != ErrFull {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+	if n, _ := consumer.Len(); n != 100 {
+		t.Fatalf("expected 100 records, got %d", n)
+	}
+	if p, payload, ok, _ := consumer.Peek(); !ok || p != 0 || string(payload) != "job0" {
+		t.Fatalf("unexpected minimum %d %q", p, payload)
+	}
+	for want := int64(0); want < 50; want++ {
+		p, payload, ok, err := consumer.Pop()
+		if err != nil || !ok || p != want || string(payload) != fmt.Sprint("job", want) {
+			t.Fatalf("expected job%d, got %d %q %v", want, p, payload, err)
+		}
+	}
+
+	// the heap outlives its handles
+	if err := consumer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := consumer.Pop(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	reopened, err := Open(path, 100, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if p, _, _, _ := reopened.Pop(); p != 50 {
+		t.Fatalf("expected 50 after reopening, got %d", p)
+	}
+	reopened.Clear()
+	if _, _, ok, _ := producer.Pop(); ok {
+		t.Fatal("expected an empty heap")
+	}
+}
+
+func TestConcurrentHandles(t *testing.T) {
+	path, cleanup := tempHeap(t)
+	defer cleanup()
+
+	const workers, each = 4, 250
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		h, err := Open(path, workers*each, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer h.Close()
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < each; i++ {
+				if err := h.Push(int64(i*workers+w), nil); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	h, err := Open(path, workers*each, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	for want := int64(0); want < workers*each; want++ {
+		if p, _, ok, _ := h.Pop(); !ok || p != want {
+			t.Fatalf("expected %d, got %d", want, p)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	path, cleanup := tempHeap(t)
+	defer cleanup()
+
+	h, err := Open(path, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Push(1, []byte("too long")); err == nil {
+		t.Fatal("expected an error for a long payload")
+	}
+	h.Close()
+	if err := h.Close(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+
+	if _, err := Open(path, 11, 4); err == nil {
+		t.Fatal("expected an error for another capacity")
+	}
+	if _, err := Open(path, 8, 8); err == nil {
+		t.Fatal("expected an error for another layout of the same size")
+	}
+	if _, err := Open(path, 0, 4); err == nil {
+		t.Fatal("expected an error for no capacity")
+	}
+}
@@ -41,7 +41,7 @@ type SkewHeap struct {
 
 // Init initializes or clears the SkewHeap
 func (h *SkewHeap) Init() *SkewHeap {
-	h.root = &node{}
+	h.root = nil
 	return h
 }
 
@@ -60,6 +60,9 @@ func (h *SkewHeap) Insert(v heap.Item) heap.Item {
 // DeleteMin deletes the minimum value and returns it.
 func (h *SkewHeap) DeleteMin() heap.Item {
 	v := h.root
+	if v == nil {
+		return nil
+	}
 
 	h.root = merge(v.right, v.left)
 
@@ -68,6 +71,9 @@ func (h *SkewHeap) DeleteMin() heap.Item {
 
 // FindMin finds the minimum value.
 func (h *SkewHeap) FindMin() heap.Item {
+	if h.root == nil {
+		return nil
+	}
 	return h.root.item
 }
 
@@ -67,6 +67,21 @@ func TestSkewHeap(t *testing.T) {
 	}
 }
 
+func TestSkewHeapNew(t *testing.T) {
+	skew := New()
+
+	if skew.FindMin() != nil || skew.DeleteMin() != nil {
+		t.Fail()
+	}
+
+	skew.Insert(Int(2))
+	skew.Insert(Int(1))
+
+	if skew.DeleteMin() != Int(1) || skew.DeleteMin() != Int(2) {
+		t.Fail()
+	}
+}
+
 func Int(value int) heap.Integer {
 	return heap.Integer(value)
 }
@@ -0,0 +1,54 @@
+package go_heaps
+
+// IsHeap reports whether items are in binary min heap order, where the
+// children of the item at index i sit at indices 2*i+1 and 2*i+2. This is the
+// layout used by container/heap.
+// The complexity is O(n).
+func IsHeap(items []Item) bool {
+	for i := 1; i < len(items); i++ {
+		if items[(i-1)/2].Compare(items[i]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Heapify rearranges items in place into binary min heap order.
+// The complexity is O(n).
+func Heapify(items []Item) {
+	for i := len(items)/2 - 1; i >= 0; i-- {
+		siftDown(items, i)
+	}
+}
+
+func siftDown(items []Item, i int) {
+	n := len(items)
+	for {
+		min := i
+		if l := 2*i + 1; l < n && items[l].Compare(items[min]) < 0 {
+			min = l
+		}
+		if r := 2*i + 2; r < n && items[r].Compare(items[min]) < 0 {
+			min = r
+		}
+		if min == i {
+			return
+		}
+		items[i], items[min] = items[min], items[i]
+		i = min
+	}
+}
+
+// Sort sorts items in ascending order using h, which must be empty, by
+// inserting every item and extracting them back with DeleteMin. The order of
+// equal items follows the tie-breaking of h: a stable heap such as
+// pairing.New(pairing.WithStable()) gives a stable sort.
+// The complexity is that of n Insert and n DeleteMin calls on h.
+func Sort(items []Item, h Interface) {
+	for _, item := range items {
+		h.Insert(item)
+	}
+	for i := range items {
+		items[i] = h.DeleteMin()
+	}
+}
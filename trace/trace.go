@@ -0,0 +1,349 @@
+// Package trace records the operations performed on a heap and replays
+// them against another implementation.
+//
+// A Recorder wraps a heap and writes one line per operation to an
+// io.Writer. The lines are tab separated: a sequence number, the time since
+// the recorder was created in nanoseconds, the operation, its arguments and
+// its result. Items are formatted with fmt.Sprint and quoted, and a nil
+// result is written as -:
+//
+//	1	1200	Insert	"5"	"5"
+//	2	3400	Insert	"3"	"3"
+//	3	4100	DeleteMin	"3"
+//	4	5000	Adjust	"5"	"1"	"1"
+//
+// Read parses a trace back into events, and Replay applies them to any heap
+// and reports the first result that differs from the recorded one. This
+// reproduces behaviour seen in production against a debug build, or checks
+// one implementation against another.
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/internal/lines"
+)
+
+// Op is the kind of a recorded operation.
+type Op int
+
+const (
+	Insert Op = iota
+	DeleteMin
+	FindMin
+	Clear
+	Delete
+	Adjust
+)
+
+var opNames = [...]string{
+	Insert:    "Insert",
+	DeleteMin: "DeleteMin",
+	FindMin:   "FindMin",
+	Clear:     "Clear",
+	Delete:    "Delete",
+	Adjust:    "Adjust",
+}
+
+func (op Op) String() string {
+	if op >= 0 && int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return fmt.Sprintf("Op(%d)", int(op))
+}
+
+// args returns the number of item arguments taken by op.
+func (op Op) args() int {
+	switch op {
+	case Insert, Delete:
+		return 1
+	case Adjust:
+		return 2
+	}
+	return 0
+}
+
+// Event is a single recorded operation.
+type Event struct {
+	// Seq numbers the events of a trace from 1.
+	Seq uint64
+	// Time is the time since the recorder was created.
+	Time time.Duration
+	Op   Op
+	// Item is the item inserted, deleted or adjusted.
+	Item heap.Item
+	// New is the new value of an adjusted item.
+	New heap.Item
+	// Result is the item returned by the operation. It is nil for Clear.
+	Result heap.Item
+}
+
+func (e Event) String() string {
+	switch e.Op.args() {
+	case 1:
+		return fmt.Sprintf("#%d %v(%v) = %v", e.Seq, e.Op, e.Item, e.Result)
+	case 2:
+		return fmt.Sprintf("#%d %v(%v, %v) = %v", e.Seq, e.Op, e.Item, e.New, e.Result)
+	}
+	if e.Op == Clear {
+		return fmt.Sprintf("#%d %v()", e.Seq, e.Op)
+	}
+	return fmt.Sprintf("#%d %v() = %v", e.Seq, e.Op, e.Result)
+}
+
+// Apply performs the operation of e on h and returns its result. Delete and
+// Adjust require h to have Delete and Adjust methods, as heap.Extended and
+// Recorder do.
+func (e Event) Apply(h heap.Interface) heap.Item {
+	switch e.Op {
+	case Insert:
+		return h.Insert(e.Item)
+	case DeleteMin:
+		return h.DeleteMin()
+	case FindMin:
+		return h.FindMin()
+	case Clear:
+		h.Clear()
+		return nil
+	case Delete:
+		return extended(h, e).Delete(e.Item)
+	case Adjust:
+		return extended(h, e).Adjust(e.Item, e.New)
+	}
+	panic(fmt.Sprintf("trace: invalid operation %v", e.Op))
+}
+
+// modifier is the part of heap.Extended that Apply needs.
+type modifier interface {
+	Delete(item heap.Item) heap.Item
+	Adjust(old, new heap.Item) heap.Item
+}
+
+func extended(h heap.Interface, e Event) modifier {
+	x, ok := h.(modifier)
+	if !ok {
+		panic(fmt.Sprintf("trace: %v requires Delete and Adjust, got %T", e.Op, h))
+	}
+	return x
+}
+
+// Recorder forwards operations to a heap and writes each of them to a
+// trace. Structure is not thread safe.
+type Recorder struct {
+	h     heap.Interface
+	w     io.Writer
+	start time.Time
+	seq   uint64
+	err   error
+}
+
+// Recorder implements the Interface
+var _ heap.Interface = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder writing the operations on h to w. Each
+// operation is written with a single Write call, so w should be buffered
+// when tracing a hot path.
+func NewRecorder(h heap.Interface, w io.Writer) *Recorder {
+	return &Recorder{h: h, w: w, start: time.Now()}
+}
+
+// Heap returns the underlying heap.
+func (r *Recorder) Heap() heap.Interface {
+	return r.h
+}
+
+// Err returns the first error returned by the writer. Operations keep
+// being forwarded to the heap after an error, but are no longer recorded.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+// Insert inserts item into the heap and returns it.
+func (r *Recorder) Insert(item heap.Item) heap.Item {
+	return r.record(Event{Op: Insert, Item: item})
+}
+
+// DeleteMin removes and returns the smallest item of the heap.
+func (r *Recorder) DeleteMin() heap.Item {
+	return r.record(Event{Op: DeleteMin})
+}
+
+// FindMin returns the smallest item of the heap.
+func (r *Recorder) FindMin() heap.Item {
+	return r.record(Event{Op: FindMin})
+}
+
+// Clear removes all items from the heap.
+func (r *Recorder) Clear() {
+	r.record(Event{Op: Clear})
+}
+
+// Delete removes item from the heap and returns it. The heap must have a
+// Delete method, as heap.Extended does.
+func (r *Recorder) Delete(item heap.Item) heap.Item {
+	return r.record(Event{Op: Delete, Item: item})
+}
+
+// Adjust changes the value of item old to new and returns the result of
+// the heap's Adjust. The heap must have an Adjust method, as heap.Extended
+// does.
+func (r *Recorder) Adjust(old, new heap.Item) heap.Item {
+	return r.record(Event{Op: Adjust, Item: old, New: new})
+}
+
+func (r *Recorder) record(e Event) heap.Item {
+	e.Result = e.Apply(r.h)
+	if r.err != nil {
+		return e.Result
+	}
+	r.seq++
+	e.Seq = r.seq
+	e.Time = time.Since(r.start)
+	_, r.err = io.WriteString(r.w, format(e))
+	return e.Result
+}
+
+// format returns the trace line of e.
+func format(e Event) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d\t%d\t%v", e.Seq, int64(e.Time), e.Op)
+	items := []heap.Item{e.Item, e.New}[:e.Op.args()]
+	if e.Op != Clear {
+		items = append(items, e.Result)
+	}
+	for _, item := range items {
+		b.WriteByte('\t')
+		if item == nil {
+			b.WriteByte('-')
+		} else {
+			b.WriteString(strconv.Quote(fmt.Sprint(item)))
+		}
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Read parses the trace read from r. parse converts the text of each item
+// back into an item. Lines are not limited in length.
+func Read(r io.Reader, parse func(string) (heap.Item, error)) ([]Event, error) {
+	var events []Event
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := lines.Read(br)
+		if err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			continue
+		}
+		e, err := parseEvent(text, parse)
+		if err != nil {
+			return nil, fmt.Errorf("trace: line %d: %v", line, err)
+		}
+		events = append(events, e)
+	}
+}
+
+func parseEvent(text string, parse func(string) (heap.Item, error)) (Event, error) {
+	var e Event
+	fields := strings.Split(text, "\t")
+	if len(fields) < 3 {
+		return e, fmt.Errorf("expected a sequence number, a time and an operation")
+	}
+	seq, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return e, err
+	}
+	nanos, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return e, err
+	}
+	e.Seq, e.Time = seq, time.Duration(nanos)
+
+	e.Op = -1
+	for op, name := range opNames {
+		if fields[2] == name {
+			e.Op = Op(op)
+		}
+	}
+	if e.Op < 0 {
+		return e, fmt.Errorf("unknown operation %q", fields[2])
+	}
+
+	want := e.Op.args()
+	if e.Op != Clear {
+		want++
+	}
+	if len(fields)-3 != want {
+		return e, fmt.Errorf("%v expects %d items, got %d", e.Op, want, len(fields)-3)
+	}
+	items := make([]heap.Item, want)
+	for i, field := range fields[3:] {
+		if field == "-" {
+			continue
+		}
+		text, err := strconv.Unquote(field)
+		if err != nil {
+			return e, err
+		}
+		if items[i], err = parse(text); err != nil {
+			return e, err
+		}
+	}
+	switch e.Op.args() {
+	case 2:
+		e.New = items[1]
+		fallthrough
+	case 1:
+		e.Item = items[0]
+	}
+	if e.Op != Clear {
+		e.Result = items[want-1]
+	}
+	return e, nil
+}
+
+// Mismatch is returned by Replay when a heap returns a different result
+// than the one recorded.
+type Mismatch struct {
+	Event Event
+	// Got is the result returned by the replayed heap.
+	Got heap.Item
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("trace: %v: got %v", m.Event, m.Got)
+}
+
+// Replay applies events to h in order and returns a *Mismatch for the first
+// operation whose result differs from the recorded one. Results are
+// compared with Compare, so equal items with different payloads match.
+// The results of Adjust are not compared, as implementations differ in
+// which item they return.
+func Replay(h heap.Interface, events []Event) error {
+	for _, e := range events {
+		got := e.Apply(h)
+		if e.Op != Adjust && !Same(got, e.Result) {
+			return &Mismatch{Event: e, Got: got}
+		}
+	}
+	return nil
+}
+
+// Same reports whether a and b are both nil or compare equal, which is how
+// Replay matches results.
+func Same(a, b heap.Item) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Compare(b) == 0
+}
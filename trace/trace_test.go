@@ -0,0 +1,188 @@
+package trace
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+	rpheap "github.com/theodesp/go-heaps/rank_pairing"
+)
+
+func parseInt(s string) (heap.Item, error) {
+	i, err := strconv.Atoi(s)
+	return heap.Integer(i), err
+}
+
+// record returns the trace of 500 random operations, each followed by
+// FindMin, and of a final Clear and DeleteMin. Delete and Adjust target
+// items held by the heap.
+func record(t *testing.T) []byte {
+	var buf bytes.Buffer
+	r := NewRecorder(pairing.New(), &buf)
+	rnd := rand.New(rand.NewSource(1))
+	var held []heap.Item
+	take := func(i int) heap.Item {
+		item := held[i]
+		held = append(held[:i], held[i+1:]...)
+		return item
+	}
+	for i := 0; i < 500; i++ {
+		switch op := rnd.Intn(4); {
+		case op == 0 || len(held) == 0:
+			held = append(held, r.Insert(heap.Integer(rnd.Intn(500))))
+		case op == 1:
+			min := r.DeleteMin()
+			for j := range held {
+				if held[j].Compare(min) == 0 {
+					take(j)
+					break
+				}
+			}
+		case op == 2:
+			r.Delete(take(rnd.Intn(len(held))))
+		default:
+			item, new := take(rnd.Intn(len(held))), heap.Integer(rnd.Intn(500))
+			r.Adjust(item, new)
+			held = append(held, new)
+		}
+		r.FindMin()
+	}
+	r.Clear()
+	r.DeleteMin()
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+	return buf.Bytes()
+}
+
+func TestRecordReplay(t *testing.T) {
+	events, err := Read(bytes.NewReader(record(t)), parseInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1002 {
+		t.Fatalf("expected 1002 events, got %d", len(events))
+	}
+	for i, e := range events {
+		if e.Seq != uint64(i+1) {
+			t.Fatalf("event %d has sequence number %d", i, e.Seq)
+		}
+		if i > 0 && e.Time < events[i-1].Time {
+			t.Fatalf("event %v recorded before the previous one", e)
+		}
+	}
+	last := events[len(events)-1]
+	if last.Op != DeleteMin || last.Result != nil || events[len(events)-2].Op != Clear {
+		t.Fatalf("unexpected trailing events %v, %v", events[len(events)-2], last)
+	}
+
+	for _, h := range []heap.Interface{
+		pairing.New(pairing.WithStrategy(pairing.MultiPass)),
+		pairing.New(pairing.WithLazyDelete(0.5)),
+		rpheap.New(),
+	} {
+		if err := Replay(h, events); err != nil {
+			t.Errorf("%T: %v", h, err)
+		}
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	events := []Event{
+		{Seq: 1, Op: Insert, Item: heap.Integer(3), Result: heap.Integer(3)},
+		{Seq: 2, Op: Insert, Item: heap.Integer(1), Result: heap.Integer(1)},
+		{Seq: 3, Op: DeleteMin, Result: heap.Integer(3)},
+	}
+	err := Replay(pairing.New(), events)
+	m, ok := err.(*Mismatch)
+	if !ok {
+		t.Fatalf("expected a mismatch, got %v", err)
+	}
+	if m.Event.Seq != 3 || m.Got != heap.Integer(1) {
+		t.Fatalf("unexpected mismatch %v", m)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(pairing.New(), &buf)
+	r.Insert(heap.String("a b"))
+	r.Adjust(heap.String("a b"), heap.String("a\tc"))
+	r.DeleteMin()
+	r.FindMin()
+	r.Clear()
+
+	var ops []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		ops = append(ops, fields[2])
+	}
+	want := []string{
+		`Insert	"a b"	"a b"`,
+		`Adjust	"a b"	"a\tc"	"a\tc"`,
+		`DeleteMin	"a\tc"`,
+		`FindMin	-`,
+		`Clear`,
+	}
+	if strings.Join(ops, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected trace:\n%s", buf.String())
+	}
+
+	events, err := Read(&buf, func(s string) (heap.Item, error) { return heap.String(s), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[1].New != heap.String("a\tc") {
+		t.Fatalf("unexpected event %v", events[1])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, text := range []string{
+		"1\t0",
+		"x\t0\tFindMin\t-",
+		"1\tx\tFindMin\t-",
+		"1\t0\tPush\t\"1\"",
+		"1\t0\tInsert\t\"1\"",
+		"1\t0\tClear\t-",
+		"1\t0\tDeleteMin\t1",
+		"1\t0\tDeleteMin\t\"x\"",
+	} {
+		if _, err := Read(strings.NewReader(text), parseInt); err == nil {
+			t.Errorf("expected an error for %q", text)
+		} else if !strings.HasPrefix(err.Error(), "trace: line 1: ") {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestReadLongLine(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	events, err := Read(strings.NewReader("1\t0\tInsert\t\""+long+"\"\t\""+long+"\""), func(s string) (heap.Item, error) {
+		return heap.String(s), nil
+	})
+	if err != nil || len(events) != 1 || events[0].Item != heap.String(long) {
+		t.Fatalf("failed to read a long line: %v", err)
+	}
+}
+
+func TestApplyRecorder(t *testing.T) {
+	// a Recorder takes Delete and Adjust events without implementing Meld
+	var buf bytes.Buffer
+	r := NewRecorder(pairing.New(), &buf)
+	for _, e := range []Event{
+		{Op: Insert, Item: heap.Integer(1)},
+		{Op: Insert, Item: heap.Integer(2)},
+		{Op: Adjust, Item: heap.Integer(2), New: heap.Integer(0)},
+		{Op: Delete, Item: heap.Integer(1)},
+	} {
+		e.Apply(r)
+	}
+	if r.FindMin() != heap.Integer(0) || strings.Count(buf.String(), "\n") != 5 {
+		t.Fatalf("unexpected trace\n%s", buf.String())
+	}
+}
@@ -0,0 +1,411 @@
+// Package violation implements a violation heap, Amr Elmasry's relaxed
+// variant of the Fibonacci heap.
+//
+// A violation heap is a forest of heap-ordered trees whose nodes keep their
+// children in a list. The first two children of a node are its active
+// children and its rank is derived from theirs only, so the trees may
+// violate the shape a Fibonacci heap enforces with marks and cascading cuts
+// while keeping logarithmic ranks. Insert adds a single node, and
+// DecreaseKey cuts the node off its parent, putting its active child of
+// larger rank in its place, both in O(1) amortized. DeleteMin joins trees of
+// equal rank three at a time, so at most two trees of each rank are left,
+// in O(log n) amortized.
+//
+// Structure is not thread safe.
+//
+// Reference: A. Elmasry, "The Violation Heap: A Relaxed Fibonacci-Like
+// Heap", COCOON 2010.
+package violation
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// Element is an item held by a ViolationHeap, returned by Push to decrease
+// its key or remove it later. It is a node of the heap.
+type Element struct {
+	item                      heap.Item
+	parent, child, prev, next *Element
+	// rank is derived from the ranks of the first two children, -1 once
+	// the element is removed
+	rank int
+}
+
+// Item returns the item of the element.
+func (e *Element) Item() heap.Item {
+	return e.item
+}
+
+// active reports whether e is one of the first two children of its parent.
+func (e *Element) active() bool {
+	p := e.parent
+	return p != nil && (p.child == e || p.child.next == e)
+}
+
+// computeRank returns the rank of e from its active children: half the sum
+// of their ranks rounded up, plus one, counting a missing child as -1.
+func (e *Element) computeRank() int {
+	r1, r2 := -1, -1
+	if c := e.child; c != nil {
+		r1 = c.rank
+		if c.next != nil {
+			r2 = c.next.rank
+		}
+	}
+	return (r1+r2+1)>>1 + 1
+}
+
+// ViolationHeap is a violation heap. The zero value is an empty heap.
+type ViolationHeap struct {
+	roots []*Element
+	min   *Element
+	size  int
+}
+
+// ViolationHeap implements the Extended interface
+var _ heap.Extended = (*ViolationHeap)(nil)
+
+// Init initializes or clears the ViolationHeap. Elements returned by Push
+// before are no longer valid.
+func (h *ViolationHeap) Init() *ViolationHeap {
+	h.roots, h.min, h.size = nil, nil, 0
+	return h
+}
+
+// New returns an initialized ViolationHeap.
+func New() *ViolationHeap { return new(ViolationHeap).Init() }
+
+// Len returns the number of items in the heap.
+func (h *ViolationHeap) Len() int {
+	return h.size
+}
+
+// Insert adds an item into the heap and returns it.
+// The complexity is O(1).
+func (h *ViolationHeap) Insert(item heap.Item) heap.Item {
+	h.Push(item)
+	return item
+}
+
+// Push adds an item into the heap and returns its element.
+// The complexity is O(1).
+func (h *ViolationHeap) Push(item heap.Item) *Element {
+	e := &Element{item: item}
+	h.addRoot(e)
+	h.size++
+	return e
+}
+
+// FindMin returns the smallest item, or nil if the heap is empty.
+// The complexity is O(1).
+func (h *ViolationHeap) FindMin() heap.Item {
+	if h.min == nil {
+		return nil
+	}
+	return h.min.item
+}
+
+// FindMinElement returns the element of the smallest item, or nil if the
+// heap is empty, to decrease or remove the minimum without looking it up.
+// The complexity is O(1).
+func (h *ViolationHeap) FindMinElement() *Element {
+	if h.min == nil {
+		return nil
+	}
+	return h.min
+}
+
+// DeleteMin removes the smallest item and returns it, or nil if the heap is
+// empty.
+// The complexity is O(log n) amortized.
+func (h *ViolationHeap) DeleteMin() heap.Item {
+	if h.min == nil {
+		return nil
+	}
+	e := h.min
+	h.remove(e)
+	return e.item
+}
+
+// DecreaseKey replaces the item of e, which must be in the heap, with item,
+// which must not compare greater. If e then compares less than its parent
+// it is cut off with its subtree and its active child of larger rank takes
+// its place.
+// The complexity is O(1) amortized.
+func (h *ViolationHeap) DecreaseKey(e *Element, item heap.Item) {
+	if e.rank < 0 {
+		panic("violation: element is not in the heap")
+	}
+	if item.Compare(e.item) > 0 {
+		panic("violation: DecreaseKey to a greater item")
+	}
+	e.item = item
+	switch {
+	case e.parent == nil:
+		if item.Compare(h.min.item) < 0 {
+			h.min = e
+		}
+	case item.Compare(e.parent.item) < 0:
+		h.cut(e)
+		h.addRoot(e)
+	}
+}
+
+// Remove removes e, which must be in the heap, and returns its item.
+// The complexity is O(log n) amortized.
+func (h *ViolationHeap) Remove(e *Element) heap.Item {
+	if e.rank < 0 {
+		panic("violation: element is not in the heap")
+	}
+	if e.parent != nil {
+		h.cut(e)
+		h.roots = append(h.roots, e)
+	}
+	h.remove(e)
+	return e.item
+}
+
+// Delete removes the item that compares equal to item and returns it, or
+// nil if there is none.
+// The complexity is O(n) to locate the item, then O(log n) amortized.
+func (h *ViolationHeap) Delete(item heap.Item) heap.Item {
+	e := h.find(item)
+	if e == nil {
+		return nil
+	}
+	return h.Remove(e)
+}
+
+// Adjust replaces the item that compares equal to old with new and returns
+// new, or nil if there is none. A decrease cuts the item in place, an
+// increase removes it and inserts new.
+// The complexity is O(n) to locate the item, then O(1) amortized for a
+// decrease and O(log n) amortized for an increase.
+func (h *ViolationHeap) Adjust(old, new heap.Item) heap.Item {
+	e := h.find(old)
+	if e == nil {
+		return nil
+	}
+	if new.Compare(e.item) <= 0 {
+		h.DecreaseKey(e, new)
+	} else {
+		h.Remove(e)
+		h.Push(new)
+	}
+	return new
+}
+
+// Meld moves the items of a, which must be a *ViolationHeap, into h and
+// returns h. The elements of a stay valid in h.
+// The complexity is O(t) for t trees in a.
+func (h *ViolationHeap) Meld(a heap.Interface) heap.Interface {
+	if a == nil {
+		return h
+	}
+	o, ok := a.(*ViolationHeap)
+	if !ok {
+		panic(fmt.Sprintf("unexpected type %T", a))
+	}
+	if o == h {
+		return h
+	}
+	for _, r := range o.roots {
+		h.addRoot(r)
+	}
+	h.size += o.size
+	o.Init()
+	return h
+}
+
+// Clear removes all items from the heap.
+func (h *ViolationHeap) Clear() {
+	h.Init()
+}
+
+// Do calls it for every item in the heap, in no particular order, until it
+// returns false.
+// The complexity is O(n).
+func (h *ViolationHeap) Do(it heap.ItemIterator) {
+	stack := append([]*Element(nil), h.roots...)
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !it(e.item) {
+			return
+		}
+		for c := e.child; c != nil; c = c.next {
+			stack = append(stack, c)
+		}
+	}
+}
+
+// addRoot adds e to the roots and updates the minimum.
+func (h *ViolationHeap) addRoot(e *Element) {
+	e.parent, e.prev, e.next = nil, nil, nil
+	h.roots = append(h.roots, e)
+	if h.min == nil || e.item.Compare(h.min.item) < 0 {
+		h.min = e
+	}
+}
+
+// cut detaches e from its parent. The active child of e of larger rank, if
+// any, takes the place of e among the children of the parent, and the
+// ranks of the ancestors are updated for as long as they change and are
+// active.
+func (h *ViolationHeap) cut(e *Element) {
+	p := e.parent
+	var r *Element
+	if c := e.child; c != nil {
+		r = c
+		if c.next != nil && c.next.rank > c.rank {
+			r = c.next
+		}
+		unlink(r)
+		e.rank = e.computeRank()
+	}
+
+	if r != nil {
+		// r takes the place of e
+		r.parent, r.prev, r.next = p, e.prev, e.next
+		if e.prev != nil {
+			e.prev.next = r
+		} else {
+			p.child = r
+		}
+		if e.next != nil {
+			e.next.prev = r
+		}
+		e.parent, e.prev, e.next = nil, nil, nil
+	} else {
+		unlink(e)
+	}
+
+	for y := p; y != nil; y = y.parent {
+		rank := y.computeRank()
+		if rank == y.rank {
+			break
+		}
+		y.rank = rank
+		if !y.active() {
+			break
+		}
+	}
+}
+
+// unlink removes e from the children of its parent.
+func unlink(e *Element) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		e.parent.child = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	}
+	e.parent, e.prev, e.next = nil, nil, nil
+}
+
+// remove removes the root e, makes its children roots and joins the trees.
+func (h *ViolationHeap) remove(e *Element) {
+	for i, r := range h.roots {
+		if r == e {
+			last := len(h.roots) - 1
+			h.roots[i] = h.roots[last]
+			h.roots[last] = nil
+			h.roots = h.roots[:last]
+			break
+		}
+	}
+	for c := e.child; c != nil; {
+		next := c.next
+		c.parent, c.prev, c.next = nil, nil, nil
+		h.roots = append(h.roots, c)
+		c = next
+	}
+	e.child = nil
+	e.rank = -1
+	h.size--
+
+	h.join()
+	h.min = nil
+	for _, r := range h.roots {
+		if h.min == nil || r.item.Compare(h.min.item) < 0 {
+			h.min = r
+		}
+	}
+}
+
+// join joins trees of equal rank three at a time until at most two trees of
+// every rank are left.
+func (h *ViolationHeap) join() {
+	var byRank [][2]*Element
+	for _, e := range h.roots {
+		for {
+			for len(byRank) <= e.rank {
+				byRank = append(byRank, [2]*Element{})
+			}
+			b := &byRank[e.rank]
+			if b[0] == nil {
+				b[0] = e
+				break
+			}
+			if b[1] == nil {
+				b[1] = e
+				break
+			}
+			e = join3(b[0], b[1], e)
+			b[0], b[1] = nil, nil
+		}
+	}
+	h.roots = h.roots[:0]
+	for _, b := range byRank {
+		for _, e := range b {
+			if e != nil {
+				h.roots = append(h.roots, e)
+			}
+		}
+	}
+}
+
+// join3 links three trees of equal rank: the root of the smallest item gets
+// the other two as its active children and its rank grows by one.
+func join3(a, b, c *Element) *Element {
+	if b.item.Compare(a.item) < 0 {
+		a, b = b, a
+	}
+	if c.item.Compare(a.item) < 0 {
+		a, c = c, a
+	}
+	for _, e := range []*Element{c, b} {
+		e.parent, e.prev, e.next = a, nil, a.child
+		if a.child != nil {
+			a.child.prev = e
+		}
+		a.child = e
+	}
+	a.rank = a.computeRank()
+	return a
+}
+
+// find returns the element of the item that compares equal to item, or nil.
+// Subtrees whose root compares greater than item are skipped.
+func (h *ViolationHeap) find(item heap.Item) *Element {
+	stack := append([]*Element(nil), h.roots...)
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		c := e.item.Compare(item)
+		if c == 0 {
+			return e
+		}
+		if c > 0 {
+			continue
+		}
+		for ch := e.child; ch != nil; ch = ch.next {
+			stack = append(stack, ch)
+		}
+	}
+	return nil
+}
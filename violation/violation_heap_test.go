@@ -0,0 +1,214 @@
+package violation
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	heap "github.com/theodesp/go-heaps"
+)
+
+// check verifies the trees of h: heap order, sibling and parent links, the
+// rank of every node derived from its active children, and the size.
+func check(t *testing.T, h *ViolationHeap) {
+	t.Helper()
+	size := 0
+	for _, r := range h.roots {
+		if r.parent != nil || r.prev != nil || r.next != nil {
+			t.Fatalf("root %v is linked", r.item)
+		}
+		if r.item.Compare(h.min.item) < 0 {
+			t.Fatalf("root %v is less than the minimum %v", r.item, h.min.item)
+		}
+		stack := []*Element{r}
+		for len(stack) > 0 {
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			if rank := e.computeRank(); e.rank != rank {
+				t.Fatalf("node %v has rank %d, expected %d", e.item, e.rank, rank)
+			}
+			var prev *Element
+			for c := e.child; c != nil; c = c.next {
+				if c.parent != e || c.prev != prev {
+					t.Fatalf("child %v of %v is badly linked", c.item, e.item)
+				}
+				if c.item.Compare(e.item) < 0 {
+					t.Fatalf("child %v is less than its parent %v", c.item, e.item)
+				}
+				stack = append(stack, c)
+				prev = c
+			}
+		}
+	}
+	if size != h.size {
+		t.Fatalf("expected %d nodes, counted %d", h.size, size)
+	}
+}
+
+func TestViolationHeap(t *testing.T) {
+	h := New()
+	if h.FindMin() != nil || h.DeleteMin() != nil {
+		t.Fatal("expected nil from an empty heap")
+	}
+	if h.FindMinElement() != nil {
+		t.Fatal("expected no element from an empty heap")
+	}
+	for _, v := range rand.Perm(1000) {
+		h.Insert(heap.Integer(v))
+	}
+	for i := 0; i < 1000; i++ {
+		if h.FindMin() != heap.Integer(i) || h.FindMinElement().Item() != heap.Integer(i) {
+			t.Fatalf("expected min %d, got %v", i, h.FindMin())
+		}
+		if item := h.DeleteMin(); item != heap.Integer(i) {
+			t.Fatalf("expected %d, got %v", i, item)
+		}
+		if i%100 == 0 {
+			check(t, h)
+		}
+		// joins leave at most two trees of every rank
+		ranks := map[int]int{}
+		for _, r := range h.roots {
+			if ranks[r.rank]++; ranks[r.rank] > 2 {
+				t.Fatalf("more than two trees of rank %d", r.rank)
+			}
+		}
+	}
+	if h.Len() != 0 || h.FindMin() != nil {
+		t.Fatal("expected an empty heap")
+	}
+}
+
+func TestDecreaseKey(t *testing.T) {
+	h := New()
+	model := map[*Element]int{}
+	var elems []*Element
+	for step := 0; step < 5000; step++ {
+		switch op := rand.Intn(10); {
+		case op < 4:
+			v := rand.Intn(10000)
+			e := h.Push(heap.Integer(v))
+			model[e] = v
+			elems = append(elems, e)
+		case op < 7 && len(elems) > 0:
+			e := elems[rand.Intn(len(elems))]
+			v := model[e] - rand.Intn(100)
+			h.DecreaseKey(e, heap.Integer(v))
+			model[e] = v
+		case op < 8 && len(elems) > 0:
+			i := rand.Intn(len(elems))
+			e := elems[i]
+			if item := h.Remove(e); item != heap.Integer(model[e]) {
+				t.Fatalf("Remove returned %v, expected %d", item, model[e])
+			}
+			delete(model, e)
+			elems[i] = elems[len(elems)-1]
+			elems = elems[:len(elems)-1]
+		case len(elems) > 0:
+			min := math.MaxInt64
+			for _, v := range model {
+				if v < min {
+					min = v
+				}
+			}
+			item := h.DeleteMin().(heap.Integer)
+			if int(item) != min {
+				t.Fatalf("DeleteMin returned %v, expected %d", item, min)
+			}
+			for i, e := range elems {
+				if e.rank < 0 {
+					delete(model, e)
+					elems[i] = elems[len(elems)-1]
+					elems = elems[:len(elems)-1]
+					break
+				}
+			}
+		}
+		if h.Len() != len(model) {
+			t.Fatalf("expected %d items, got %d", len(model), h.Len())
+		}
+		if step%50 == 0 && h.Len() > 0 {
+			check(t, h)
+		}
+	}
+
+	// the minimum can be decreased and removed through its element
+	if h.Len() > 0 {
+		min := h.FindMinElement()
+		h.DecreaseKey(min, heap.Integer(-1))
+		if h.FindMin() != heap.Integer(-1) || h.Remove(min) != heap.Integer(-1) {
+			t.Fatal("expected to decrease and remove the minimum")
+		}
+	}
+
+	e := h.Push(heap.Integer(1))
+	assertPanics(t, func() { h.DecreaseKey(e, heap.Integer(2)) })
+	h.Remove(e)
+	assertPanics(t, func() { h.Remove(e) })
+	assertPanics(t, func() { h.DecreaseKey(e, heap.Integer(0)) })
+}
+
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	f()
+}
+
+func TestExtended(t *testing.T) {
+	h := New()
+	for _, v := range rand.Perm(100) {
+		h.Insert(heap.Integer(v))
+	}
+	for i := 0; i < 10; i++ {
+		h.DeleteMin()
+	}
+	if h.Delete(heap.Integer(5)) != nil || h.Adjust(heap.Integer(5), heap.Integer(1)) != nil {
+		t.Fatal("expected nil for a missing item")
+	}
+	if h.Delete(heap.Integer(50)) != heap.Integer(50) {
+		t.Fatal("expected Delete to return 50")
+	}
+	h.Adjust(heap.Integer(60), heap.Integer(-1))
+	h.Adjust(heap.Integer(10), heap.Integer(200))
+	check(t, h)
+
+	o := New()
+	o.Insert(heap.Integer(-5))
+	o.Insert(heap.Integer(300))
+	h.Meld(o)
+	if o.Len() != 0 || o.FindMin() != nil {
+		t.Fatal("expected the melded heap to be empty")
+	}
+	check(t, h)
+
+	var got []int
+	h.Do(func(item heap.Item) bool {
+		got = append(got, int(item.(heap.Integer)))
+		return true
+	})
+	sort.Ints(got)
+	var want []int
+	for v := 11; v < 100; v++ {
+		if v != 50 && v != 60 {
+			want = append(want, v)
+		}
+	}
+	want = append([]int{-5, -1}, append(want, 200, 300)...)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		if item := h.DeleteMin(); item != heap.Integer(want[i]) {
+			t.Fatalf("expected %d, got %v", want[i], item)
+		}
+	}
+}
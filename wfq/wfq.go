@@ -0,0 +1,152 @@
+// Package wfq implements a weighted fair queuing scheduler.
+//
+// Items are enqueued on flows with a size, and dequeued so that every
+// backlogged flow receives a share of the throughput proportional to its
+// weight. Each item is stamped with a virtual finish time,
+//
+//	finish = max(V, finish of the previous item of the flow) + size/weight
+//
+// where the virtual time V is the finish time of the last dequeued item
+// (self-clocked fair queuing). A pairing heap holds one entry per backlogged
+// flow, keyed on the finish time of its head item; after a dequeue the entry
+// of the flow is adjusted to its next item rather than removed and
+// reinserted. A flow is forgotten once it is idle, that is its queue is
+// empty and the finish time of its last item is not ahead of V, since it
+// then competes like a new flow; flows given a weight other than 1 are kept
+// until their weight is set back to 1.
+//
+// Structure is not thread safe.
+package wfq
+
+import (
+	"fmt"
+
+	heap "github.com/theodesp/go-heaps"
+	"github.com/theodesp/go-heaps/pairing"
+)
+
+type packet struct {
+	item   interface{}
+	finish float64
+}
+
+type flow struct {
+	key        interface{}
+	id         uint64 // breaks ties between flows, in order of creation
+	weight     float64
+	lastFinish float64
+	queue      []packet
+}
+
+// entry is the heap item of a backlogged flow.
+type entry struct {
+	finish float64
+	flow   *flow
+}
+
+func (e entry) Compare(than heap.Item) int {
+	o := than.(entry)
+	switch {
+	case e.finish < o.finish:
+		return -1
+	case e.finish > o.finish:
+		return 1
+	case e.flow.id < o.flow.id:
+		return -1
+	case e.flow.id > o.flow.id:
+		return 1
+	}
+	return 0
+}
+
+// Scheduler is a weighted fair queue.
+type Scheduler struct {
+	flows  map[interface{}]*flow
+	heap   *pairing.PairHeap
+	vtime  float64
+	nextID uint64
+	len    int
+}
+
+// New returns an empty Scheduler.
+func New() *Scheduler {
+	return &Scheduler{
+		flows: make(map[interface{}]*flow),
+		heap:  pairing.New(),
+	}
+}
+
+// SetWeight sets the weight of flow, 1 by default. The new weight applies to
+// the items enqueued afterwards. It panics if weight is not positive.
+func (s *Scheduler) SetWeight(key interface{}, weight float64) {
+	if !(weight > 0) {
+		panic(fmt.Sprintf("wfq: invalid weight %v", weight))
+	}
+	f := s.flow(key)
+	f.weight = weight
+	s.release(f)
+}
+
+// Enqueue appends item of the given size to flow.
+// The complexity is O(1) amortized.
+func (s *Scheduler) Enqueue(key interface{}, item interface{}, size float64) {
+	f := s.flow(key)
+	start := f.lastFinish
+	if s.vtime > start {
+		start = s.vtime
+	}
+	f.lastFinish = start + size/f.weight
+	f.queue = append(f.queue, packet{item: item, finish: f.lastFinish})
+	if len(f.queue) == 1 {
+		s.heap.Insert(entry{finish: f.lastFinish, flow: f})
+	}
+	s.len++
+}
+
+// Dequeue removes and returns the item with the smallest virtual finish time
+// together with its flow. ok is false if the scheduler is empty.
+// The complexity is O(log n) amortized for n backlogged flows.
+func (s *Scheduler) Dequeue() (key interface{}, item interface{}, ok bool) {
+	if s.heap.IsEmpty() {
+		return nil, nil, false
+	}
+	head := s.heap.FindMin().(entry)
+	f := head.flow
+	p := f.queue[0]
+	f.queue[0] = packet{}
+	f.queue = f.queue[1:]
+	s.vtime = p.finish
+	s.len--
+
+	if len(f.queue) > 0 {
+		s.heap.Adjust(head, entry{finish: f.queue[0].finish, flow: f})
+	} else {
+		s.heap.DeleteMin()
+		f.queue = nil
+		s.release(f)
+	}
+	return f.key, p.item, true
+}
+
+// Len returns the number of queued items.
+func (s *Scheduler) Len() int {
+	return s.len
+}
+
+func (s *Scheduler) flow(key interface{}) *flow {
+	f, ok := s.flows[key]
+	if !ok {
+		f = &flow{key: key, id: s.nextID, weight: 1}
+		s.nextID++
+		s.flows[key] = f
+	}
+	return f
+}
+
+// release forgets f if it is idle with the default weight, so that short
+// lived flows do not accumulate.
+func (s *Scheduler) release(f *flow) {
+	if f.weight == 1 && len(f.queue) == 0 && f.lastFinish <= s.vtime {
+		delete(s.flows, f.key)
+	}
+}
@@ -0,0 +1,119 @@
+package wfq
+
+import (
+	"testing"
+)
+
+func TestWeightedShares(t *testing.T) {
+	s := New()
+	if _, _, ok := s.Dequeue(); ok {
+		t.Fatal("expected nothing from an empty scheduler")
+	}
+
+	s.SetWeight("a", 2)
+	for i := 0; i < 100; i++ {
+		s.Enqueue("a", i, 100)
+		s.Enqueue("b", i, 100)
+	}
+	if s.Len() != 200 {
+		t.Fatalf("expected 200 items, got %d", s.Len())
+	}
+
+	served := map[interface{}]int{}
+	next := map[interface{}]int{}
+	for i := 0; i < 60; i++ {
+		flow, item, ok := s.Dequeue()
+		if !ok {
+			t.Fatal("unexpected empty scheduler")
+		}
+		if item != next[flow] {
+			t.Fatalf("flow %v: expected item %d, got %v", flow, next[flow], item)
+		}
+		next[flow]++
+		served[flow]++
+	}
+	if served["a"] != 40 || served["b"] != 20 {
+		t.Fatalf("expected a 2:1 share, got %v", served)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	s := New()
+	// a sends large items, b small ones: equal weights share bytes, not items
+	for i := 0; i < 10; i++ {
+		s.Enqueue("a", i, 1000)
+	}
+	for i := 0; i < 100; i++ {
+		s.Enqueue("b", i, 100)
+	}
+	bytes := map[interface{}]float64{}
+	for i := 0; i < 44; i++ {
+		flow, _, _ := s.Dequeue()
+		if flow == "a" {
+			bytes[flow] += 1000
+		} else {
+			bytes[flow] += 100
+		}
+	}
+	if bytes["a"] != 4000 || bytes["b"] != 4000 {
+		t.Fatalf("expected equal byte shares, got %v", bytes)
+	}
+}
+
+func TestIdleFlowGetsNoCredit(t *testing.T) {
+	s := New()
+	for i := 0; i < 50; i++ {
+		s.Enqueue("busy", i, 1)
+	}
+	for i := 0; i < 40; i++ {
+		s.Dequeue()
+	}
+	// the late flow competes from the current virtual time on
+	for i := 0; i < 10; i++ {
+		s.Enqueue("late", i, 1)
+	}
+	served := map[interface{}]int{}
+	for i := 0; i < 10; i++ {
+		flow, _, _ := s.Dequeue()
+		served[flow]++
+	}
+	if served["busy"] != 5 || served["late"] != 5 {
+		t.Fatalf("expected an even split, got %v", served)
+	}
+	for s.Len() > 0 {
+		s.Dequeue()
+	}
+	if _, _, ok := s.Dequeue(); ok {
+		t.Fatal("expected an empty scheduler")
+	}
+}
+
+func TestIdleFlowsReleased(t *testing.T) {
+	s := New()
+	s.SetWeight("weighted", 2)
+	for i := 0; i < 1000; i++ {
+		s.Enqueue(i, i, 1)
+		s.Enqueue("weighted", i, 1)
+		s.Dequeue()
+	}
+	for s.Len() > 0 {
+		s.Dequeue()
+	}
+	// only the flow with a weight is kept once everything is served
+	if len(s.flows) != 1 || s.flows["weighted"] == nil {
+		t.Fatalf("expected 1 flow left, got %d", len(s.flows))
+	}
+	s.SetWeight("weighted", 1)
+	if len(s.flows) != 0 {
+		t.Fatalf("expected no flow left, got %d", len(s.flows))
+	}
+}
+
+func TestSetWeightPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	New().SetWeight("a", 0)
+}